@@ -0,0 +1,371 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azstart_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// This example shows how to construct & use an ARM Client to invoke service methods
+func Example_usingARMClients() {
+	// Construct a credential type from the azidentity package
+	// or the module defining the client you wish to use.
+	// This example uses a fake credential; use azidentity.NewDefaultAzureCredential(nil) against Azure
+	credential := &azfake.TokenCredential{}
+
+	// Construct an ARM client factory passing subscription ID, credential, & optional options
+	// which could be used to create any client in one ARM module.
+	// This example sends the requests to a fake server instead of Azure
+	clientFactory, err := armresources.NewClientFactory(subscriptionID, credential, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// This example creates a ResourceGroupsClient, but you can create any ARM client
+	client := clientFactory.NewResourceGroupsClient()
+
+	// You can now call client methods to invoke service operations
+	// This example calls CreateOrUpdate, but you can call any client method
+	response, err := client.CreateOrUpdate(context.TODO(), "sample-resource-group",
+		armresources.ResourceGroup{
+			Location: to.Ptr("westus"), // to.Ptr converts this string to a *string
+		}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Use the service's response as your application desires
+	fmt.Printf("Resource group ID: %s\n", *response.ResourceGroup.ID)
+	// Output:
+	// Resource group ID: /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/sample-resource-group
+}
+
+// This example shows how to construct & use a data-plane Client to invoke service methods
+func Example_usingDataPlaneClients() {
+	// Construct a credential type from the azidentity package
+	// or the module defining the client you wish to use.
+	// This example uses a fake credential; use azidentity.NewDefaultAzureCredential(nil) against Azure
+	credential := &azfake.TokenCredential{}
+
+	// Construct a DP client passing endpointURL, credential, & optional options.
+	// This example sends the requests to a fake vault instead of Azure
+	client, err := azsecrets.NewClient("https://sample-vault.vault.azure.net/", credential, &azsecrets.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fakeVault{}},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// You can now call client methods to invoke service operations
+	response, err := client.SetSecret(context.TODO(), "sample-secret",
+		azsecrets.SetSecretParameters{Value: to.Ptr("sample-value")}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Use the service's response as your application desires
+	fmt.Printf("Name: %s, Value: %s\n", response.ID.Name(), *response.Value)
+	// Output:
+	// Name: sample-secret, Value: sample-value
+}
+
+// This example shows how to page over a collection's items
+func Example_pagingOverACollection() {
+	// This example uses a fake credential and server instead of Azure
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Call a client method that creates a XxxPager; this does NOT invoke a service operation
+	for pager := client.NewListPager(nil); pager.More(); {
+		// While pages are getable, request a page of items from the service
+		page, err := pager.NextPage(context.TODO())
+		if err != nil {
+			panic(err)
+		}
+
+		// Process the page's items.
+		// NOTE: The service decides how many items to return on a page.
+		// If a page has 0 items, go get the next page.
+		// Other clients may be adding/deleting items from the collection while
+		// this code is paging; some items may be skipped or returned multiple times.
+		for _, item := range page.Value {
+			fmt.Println(*item.Name) // Here's where your code processes the item as you desire
+		}
+		// Looping around will request the next page of items from the service
+	}
+	// Output:
+	// rg-one
+	// rg-two
+	// rg-three
+}
+
+// This example shows how to invoke a long-running operation and poll for its completion
+func Example_longRunningOperation() {
+	// This example uses a fake credential and server instead of Azure
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Initiating a long-Running Operation causes the method to return a Poller[T]
+	poller, err := client.BeginDelete(context.TODO(), "sample-resource-group", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// PollUntilDone causes your goroutine to periodically ask the service the status of the LRO
+	// It ultimately returns when the operation succeeds, fails, or was canceled.
+	// If the operation succeeds, err == nil and lroResult has result (if any); else err != nil
+	lroResult, err := poller.PollUntilDone(context.TODO(), &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	if err != nil {
+		panic(err)
+	}
+	_ = lroResult // Examine successful result (if any)
+	fmt.Println("Deleted:", poller.Done())
+	// Output:
+	// Deleted: true
+}
+
+// This example shows how to tell why a service method failed from its *azcore.ResponseError
+func Example_handlingErrors() {
+	// This example uses a fake credential and server instead of Azure
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// A service method returns an error when the service answers with a failure status code
+	_, err = client.Get(context.TODO(), "missing-resource-group", nil)
+
+	// errors.As tells whether the service answered; other errors, such as a
+	// failure to connect, are not *azcore.ResponseError
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		// StatusCode is the HTTP status code, and ErrorCode the error code from
+		// the response body or the x-ms-error-code header, which is what your
+		// code should test to decide what to do
+		fmt.Println("Status code:", respErr.StatusCode)
+		fmt.Println("Error code:", respErr.ErrorCode)
+		// RawResponse is the whole response, with the request that got it
+		fmt.Println("Request:", respErr.RawResponse.Request.Method, respErr.RawResponse.Request.URL.Path)
+	}
+	// Output:
+	// Status code: 404
+	// Error code: ResourceGroupNotFound
+	// Request: GET /subscriptions/00000000-0000-0000-0000-000000000000/resourcegroups/missing-resource-group
+}
+
+// This example shows how to resume waiting for a long-running operation, for example in another process
+func Example_resumingALongRunningOperation() {
+	// This example uses a fake credential and server instead of Azure
+	options := &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	}
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, options)
+	if err != nil {
+		panic(err)
+	}
+
+	poller, err := client.BeginDelete(context.TODO(), "sample-resource-group", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// ResumeToken returns a string saving the state of the poller; save it
+	// wherever your application keeps state, such as a file or a queue
+	token, err := poller.ResumeToken()
+	if err != nil {
+		panic(err)
+	}
+
+	// Later, perhaps in another process, pass the token to the same Begin method
+	// to get a poller for the operation in progress; the method does NOT begin
+	// the operation again
+	resumed, err := client.BeginDelete(context.TODO(), "sample-resource-group", &armresources.ResourceGroupsClientBeginDeleteOptions{
+		ResumeToken: token,
+	})
+	if err != nil {
+		panic(err)
+	}
+	if _, err := resumed.PollUntilDone(context.TODO(), &runtime.PollUntilDoneOptions{Frequency: time.Second}); err != nil {
+		panic(err)
+	}
+	fmt.Println("Deleted:", resumed.Done())
+	// Output:
+	// Deleted: true
+}
+
+// This example shows how to run your own code on every request a client sends
+func Example_addingAPolicy() {
+	// This example uses a fake credential and server instead of Azure
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: fake.NewServerFactoryTransport(newFakeServer()),
+			// PerCallPolicies run once per service method call; PerRetryPolicies
+			// would run again on every retry of a request
+			PerCallPolicies: []policy.Policy{printPolicy{}},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := client.Get(context.TODO(), "sample-resource-group", nil); err != nil {
+		panic(err)
+	}
+	// Output:
+	// GET /subscriptions/00000000-0000-0000-0000-000000000000/resourcegroups/sample-resource-group: 200
+}
+
+// printPolicy is a policy.Policy printing each request with the status code of its response
+type printPolicy struct{}
+
+func (printPolicy) Do(req *policy.Request) (*http.Response, error) {
+	// Code here runs before the request is sent, and can change it
+	// Next sends the request through the rest of the pipeline and returns its response
+	resp, err := req.Next()
+	if err != nil {
+		return resp, err
+	}
+	// Code here runs after the response is received
+	fmt.Printf("%s %s: %d\n", req.Raw().Method, req.Raw().URL.Path, resp.StatusCode)
+	return resp, nil
+}
+
+// This example shows how to read the headers of the raw response to a service method call
+func Example_capturingTheRawResponse() {
+	// This example uses a fake credential and server instead of Azure
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerFactoryTransport(newFakeServer())},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// WithCaptureResponse returns a context that makes the method store the raw HTTP response
+	var rawResponse *http.Response
+	ctx := runtime.WithCaptureResponse(context.TODO(), &rawResponse)
+	response, err := client.Get(ctx, "sample-resource-group", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// The typed response has the body; the raw response has the status code and headers too
+	fmt.Println("Resource group:", *response.Name)
+	fmt.Println("Status code:", rawResponse.StatusCode)
+	fmt.Println("Content-Type:", rawResponse.Header.Get("Content-Type"))
+	// Output:
+	// Resource group: sample-resource-group
+	// Status code: 200
+	// Content-Type: application/json
+}
+
+// newFakeServer returns a fake resources server answering the requests of the
+// examples: it creates any resource group, gets only sample-resource-group,
+// lists three over two pages and deletes any after one poll.
+func newFakeServer() *fake.ServerFactory {
+	return &fake.ServerFactory{
+		ResourceGroupsServer: fake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusCreated, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:       to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name),
+						Name:     to.Ptr(name),
+						Location: parameters.Location,
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientGetOptions) (resp azfake.Responder[armresources.ResourceGroupsClientGetResponse], errResp azfake.ErrorResponder) {
+				if name != "sample-resource-group" {
+					errResp.SetResponseError(http.StatusNotFound, "ResourceGroupNotFound")
+					return
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientGetResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armresources.ResourceGroupsClientListOptions) (resp azfake.PagerResponder[armresources.ResourceGroupsClientListResponse]) {
+				for _, names := range [][]string{{"rg-one", "rg-two"}, {"rg-three"}} {
+					var page armresources.ResourceGroupsClientListResponse
+					for _, name := range names {
+						page.Value = append(page.Value, &armresources.ResourceGroup{Name: to.Ptr(name)})
+					}
+					resp.AddPage(http.StatusOK, page, nil)
+				}
+				return
+			},
+			BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+}
+
+// fakeVault is a transport answering SetSecret like a key vault. Like Key
+// Vault, it first challenges the requests sent without a token, telling the
+// client which tenant and scope to get one for.
+type fakeVault struct{}
+
+func (fakeVault) Do(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{Request: req, Header: http.Header{}}
+	if req.Header.Get("Authorization") == "" {
+		resp.StatusCode = http.StatusUnauthorized
+		resp.Header.Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/`+subscriptionID+`", resource="https://vault.azure.net"`)
+		resp.Body = http.NoBody
+		return resp, nil
+	}
+
+	// the request path is /secrets/{name}, and the response echoes the value
+	name := strings.TrimPrefix(req.URL.Path, "/secrets/")
+	var parameters azsecrets.SetSecretParameters
+	if err := json.NewDecoder(req.Body).Decode(&parameters); err != nil {
+		return nil, err
+	}
+	id := azsecrets.ID("https://" + req.URL.Host + "/secrets/" + name + "/1")
+	body, err := json.Marshal(azsecrets.SecretBundle{ID: &id, Value: parameters.Value})
+	if err != nil {
+		return nil, err
+	}
+	resp.StatusCode = http.StatusOK
+	resp.Header.Set("Content-Type", "application/json")
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,213 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// CloudEnv names the environment variable choosing the cloud the samples run
+// in: "public", "china", "usgov" or the path of a JSON file describing a custom
+// cloud. The -cloud flag takes precedence over it.
+const CloudEnv = "SAMPLE_CLOUD"
+
+// PairedLocationSetting names the setting of the second location used by
+// samples that replicate across regions.
+const PairedLocationSetting = "pairedLocation"
+
+// Cloud is an Azure cloud the samples can run in.
+type Cloud struct {
+	Name          string
+	Configuration cloud.Configuration
+	// Location and PairedLocation replace the samples' default location and
+	// paired location, which are in the public cloud. Empty keeps the defaults.
+	Location       string
+	PairedLocation string
+	// StorageEndpointSuffix ends the host names of storage accounts, such as
+	// "core.windows.net".
+	StorageEndpointSuffix string
+	// CloudAppEndpointSuffix ends the DNS names of public IP addresses and
+	// clusters after their location, such as "cloudapp.azure.com".
+	CloudAppEndpointSuffix string
+}
+
+// The clouds LoadCloud knows by name.
+var (
+	PublicCloud = Cloud{
+		Name:                   "public",
+		Configuration:          cloud.AzurePublic,
+		StorageEndpointSuffix:  "core.windows.net",
+		CloudAppEndpointSuffix: "cloudapp.azure.com",
+	}
+	ChinaCloud = Cloud{
+		Name:                   "china",
+		Configuration:          cloud.AzureChina,
+		Location:               "chinanorth3",
+		PairedLocation:         "chinaeast3",
+		StorageEndpointSuffix:  "core.chinacloudapi.cn",
+		CloudAppEndpointSuffix: "chinacloudapp.cn",
+	}
+	USGovernmentCloud = Cloud{
+		Name:                   "usgov",
+		Configuration:          cloud.AzureGovernment,
+		Location:               "usgovvirginia",
+		PairedLocation:         "usgovtexas",
+		StorageEndpointSuffix:  "core.usgovcloudapi.net",
+		CloudAppEndpointSuffix: "cloudapp.usgovcloudapi.net",
+	}
+)
+
+// cloudMetadata is a cloud as described by Resource Manager's metadata
+// endpoint, /metadata/endpoints?api-version=2022-09-01, with the samples'
+// locations and the suffix of cloud app DNS names, which the endpoint does not
+// describe, added.
+type cloudMetadata struct {
+	Name            string `json:"name"`
+	ResourceManager string `json:"resourceManager"`
+	Authentication  struct {
+		LoginEndpoint string   `json:"loginEndpoint"`
+		Audiences     []string `json:"audiences"`
+	} `json:"authentication"`
+	Suffixes struct {
+		Storage  string `json:"storage"`
+		CloudApp string `json:"cloudApp"`
+	} `json:"suffixes"`
+	Location       string `json:"location"`
+	PairedLocation string `json:"pairedLocation"`
+}
+
+// LoadCloud returns the cloud called name, "public" if name is empty, or the
+// custom cloud described by the JSON file at the path name. The file holds the
+// metadata of one cloud in the format of Resource Manager's metadata endpoint,
+// such as:
+//
+//	{
+//	  "name": "AzureStackHub",
+//	  "resourceManager": "https://management.local.azurestack.external/",
+//	  "authentication": {
+//	    "loginEndpoint": "https://login.microsoftonline.com/",
+//	    "audiences": ["https://management.adfs.azurestack.local/"]
+//	  },
+//	  "suffixes": {"storage": "local.azurestack.external", "cloudApp": "local.cloudapp.azurestack.external"},
+//	  "location": "local"
+//	}
+//
+// The samples that need a suffix the file leaves out fail with an error.
+func LoadCloud(name string) (Cloud, error) {
+	switch strings.ToLower(name) {
+	case "", "public", "azurecloud":
+		return PublicCloud, nil
+	case "china", "azurechinacloud":
+		return ChinaCloud, nil
+	case "usgov", "azureusgovernment":
+		return USGovernmentCloud, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Cloud{}, fmt.Errorf("unknown cloud %q; use public, china, usgov or the path of a JSON file: %w", name, err)
+	}
+	var metadata cloudMetadata
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		// the metadata endpoint returns every cloud it knows
+		var clouds []cloudMetadata
+		if err := json.Unmarshal(data, &clouds); err != nil {
+			return Cloud{}, fmt.Errorf("cannot parse cloud file %s: %w", name, err)
+		}
+		if len(clouds) != 1 {
+			return Cloud{}, fmt.Errorf("cloud file %s describes %d clouds; keep the one to use", name, len(clouds))
+		}
+		metadata = clouds[0]
+	} else if err := json.Unmarshal(data, &metadata); err != nil {
+		return Cloud{}, fmt.Errorf("cannot parse cloud file %s: %w", name, err)
+	}
+	if metadata.ResourceManager == "" || metadata.Authentication.LoginEndpoint == "" || len(metadata.Authentication.Audiences) == 0 {
+		return Cloud{}, fmt.Errorf("cloud file %s needs resourceManager, authentication.loginEndpoint and authentication.audiences", name)
+	}
+
+	c := Cloud{
+		Name: metadata.Name,
+		Configuration: cloud.Configuration{
+			ActiveDirectoryAuthorityHost: metadata.Authentication.LoginEndpoint,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Endpoint: metadata.ResourceManager,
+					Audience: metadata.Authentication.Audiences[0],
+				},
+			},
+		},
+		Location:               metadata.Location,
+		PairedLocation:         metadata.PairedLocation,
+		StorageEndpointSuffix:  metadata.Suffixes.Storage,
+		CloudAppEndpointSuffix: metadata.Suffixes.CloudApp,
+	}
+	if c.Name == "" {
+		c.Name = name
+	}
+	return c, nil
+}
+
+// BlobEndpoint returns the blob endpoint of the storage account in the
+// sample's cloud, such as "https://account.blob.core.windows.net/". It fails
+// in a custom cloud without a storage suffix.
+func (s *Sample) BlobEndpoint(account string) (string, error) {
+	return s.storageEndpoint(account, "blob")
+}
+
+// QueueEndpoint returns the queue endpoint of the storage account in the
+// sample's cloud, such as "https://account.queue.core.windows.net/". It fails
+// in a custom cloud without a storage suffix.
+func (s *Sample) QueueEndpoint(account string) (string, error) {
+	return s.storageEndpoint(account, "queue")
+}
+
+// TableEndpoint returns the table endpoint of the storage account in the
+// sample's cloud, such as "https://account.table.core.windows.net/". It fails
+// in a custom cloud without a storage suffix.
+func (s *Sample) TableEndpoint(account string) (string, error) {
+	return s.storageEndpoint(account, "table")
+}
+
+func (s *Sample) storageEndpoint(account, service string) (string, error) {
+	c := s.cloud()
+	if c.StorageEndpointSuffix == "" {
+		return "", configError(fmt.Errorf("cloud %s has no storage suffix; set suffixes.storage in its file", c.Name))
+	}
+	return "https://" + account + "." + service + "." + c.StorageEndpointSuffix + "/", nil
+}
+
+// DomainName returns the DNS name of the domain name label in the sample's
+// location and cloud, such as "label.eastus.cloudapp.azure.com". It fails in
+// a custom cloud without a cloud app suffix.
+func (s *Sample) DomainName(label string) (string, error) {
+	c := s.cloud()
+	if c.CloudAppEndpointSuffix == "" {
+		return "", configError(fmt.Errorf("cloud %s has no cloud app suffix; set suffixes.cloudApp in its file", c.Name))
+	}
+	return label + "." + s.Location + "." + c.CloudAppEndpointSuffix, nil
+}
+
+// cloud returns the sample's cloud, the public cloud for a Sample made
+// without one as for its ClientOptions.
+func (s *Sample) cloud() Cloud {
+	if s.Cloud.Name == "" {
+		return PublicCloud
+	}
+	return s.Cloud
+}
+
+// applyCloud sets the location settings that were not overridden to the
+// locations of c.
+func (s Settings) applyCloud(c Cloud, overridden map[string]bool) {
+	for name, location := range map[string]string{LocationSetting: c.Location, PairedLocationSetting: c.PairedLocation} {
+		if p, ok := s[name]; ok && location != "" && !overridden[name] {
+			*p = location
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+const customCloud = `{
+  "name": "AzureStackHub",
+  "resourceManager": "https://management.local.azurestack.external/",
+  "authentication": {
+    "loginEndpoint": "https://login.microsoftonline.com/",
+    "audiences": ["https://management.adfs.azurestack.local/"]
+  },
+  "suffixes": {"storage": "local.azurestack.external", "cloudApp": "local.cloudapp.azurestack.external"},
+  "location": "local"
+}`
+
+func TestLoadCloud(t *testing.T) {
+	for name, want := range map[string]Cloud{"": PublicCloud, "China": ChinaCloud, "usgov": USGovernmentCloud} {
+		c, err := LoadCloud(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Name != want.Name || c.Location != want.Location {
+			t.Errorf("LoadCloud(%q) = %+v, want %+v", name, c, want)
+		}
+	}
+
+	dir := t.TempDir()
+	for file, content := range map[string]string{"object.json": customCloud, "array.json": "[" + customCloud + "]"} {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := LoadCloud(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rm := c.Configuration.Services[cloud.ResourceManager]
+		if c.Name != "AzureStackHub" || c.Location != "local" || c.StorageEndpointSuffix != "local.azurestack.external" || c.CloudAppEndpointSuffix != "local.cloudapp.azurestack.external" ||
+			c.Configuration.ActiveDirectoryAuthorityHost != "https://login.microsoftonline.com/" ||
+			rm.Endpoint != "https://management.local.azurestack.external/" || rm.Audience != "https://management.adfs.azurestack.local/" {
+			t.Errorf("%s: unexpected cloud %+v", file, c)
+		}
+	}
+
+	if _, err := LoadCloud("mars"); err == nil || !strings.Contains(err.Error(), "unknown cloud") {
+		t.Errorf("got error %v for an unknown cloud", err)
+	}
+	incomplete := filepath.Join(dir, "incomplete.json")
+	if err := os.WriteFile(incomplete, []byte(`{"resourceManager": "https://management.example/"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCloud(incomplete); err == nil {
+		t.Error("expected an error for a cloud without authentication")
+	}
+}
+
+func TestSettingsCloud(t *testing.T) {
+	location, pairedLocation := "westus", "eastus"
+	settings := Settings{LocationSetting: &location, PairedLocationSetting: &pairedLocation}
+	if err := settings.Load([]string{"-cloud", "usgov"}); err != nil {
+		t.Fatal(err)
+	}
+	if location != "usgovvirginia" || pairedLocation != "usgovtexas" {
+		t.Errorf("got locations %q and %q, want the US Government defaults", location, pairedLocation)
+	}
+
+	// an overridden location is kept
+	t.Setenv(CloudEnv, "china")
+	location, pairedLocation = "westus", "eastus"
+	if err := settings.Load([]string{"-location", "chinaeast2"}); err != nil {
+		t.Fatal(err)
+	}
+	if location != "chinaeast2" || pairedLocation != "chinaeast3" {
+		t.Errorf("got locations %q and %q, want chinaeast2 and chinaeast3", location, pairedLocation)
+	}
+}
+
+func TestDryRunInCloud(t *testing.T) {
+	var out bytes.Buffer
+	sample := NewDryRun("sample-resource-group", "chinanorth3", &out)
+	sample.Cloud = ChinaCloud
+	sample.ClientOptions.Cloud = ChinaCloud.Configuration
+	err := sample.Run(context.Background(), func(ctx context.Context, s *Sample) error {
+		_, err := s.CreateResourceGroup(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "PUT https://management.chinacloudapi.cn/subscriptions/"; !strings.Contains(out.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out.String())
+	}
+	if got, err := sample.BlobEndpoint("account"); err != nil || got != "https://account.blob.core.chinacloudapi.cn/" {
+		t.Errorf("BlobEndpoint() = %q, %v", got, err)
+	}
+	if got, err := sample.TableEndpoint("account"); err != nil || got != "https://account.table.core.chinacloudapi.cn/" {
+		t.Errorf("TableEndpoint() = %q, %v", got, err)
+	}
+	if got, err := sample.DomainName("cluster"); err != nil || got != "cluster.chinanorth3.chinacloudapp.cn" {
+		t.Errorf("DomainName() = %q, %v", got, err)
+	}
+
+	// a custom cloud has only the suffixes its file sets
+	sample.Cloud = Cloud{Name: "AzureStackHub", StorageEndpointSuffix: "local.azurestack.external"}
+	if _, err := sample.QueueEndpoint("account"); err != nil {
+		t.Error(err)
+	}
+	if _, err := sample.DomainName("cluster"); err == nil || Classify(err) != FailureConfig {
+		t.Errorf("got error %v for a custom cloud without a cloud app suffix", err)
+	}
+	// without a cloud, the sample is in the public cloud
+	sample.Cloud = Cloud{}
+	if got, err := sample.DomainName("cluster"); err != nil || got != "cluster.chinanorth3.cloudapp.azure.com" {
+		t.Errorf("DomainName() = %q, %v", got, err)
+	}
+}
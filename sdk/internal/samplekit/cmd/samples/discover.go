@@ -0,0 +1,174 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+)
+
+// samplesDir is where the samples are, relative to the repository root.
+const samplesDir = "sdk/resourcemanager"
+
+// Sample is a sample found under sdk/resourcemanager.
+type Sample struct {
+	// Path is the sample's directory relative to sdk/resourcemanager, for example "compute/create_vm".
+	Path string
+	// Dir is the sample's directory.
+	Dir string
+	// Service is the first element of Path, for example "compute".
+	Service string
+	// Env holds the environment variables the sample needs, sorted.
+	Env []string
+}
+
+// Discover returns the samples of the repository at root, sorted by path.
+func Discover(root string) ([]Sample, error) {
+	dirs, err := filepath.Glob(filepath.Join(root, samplesDir, "*", "*", "main.go"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no samples found in %s", filepath.Join(root, samplesDir))
+	}
+
+	var samples []Sample
+	for _, mainFile := range dirs {
+		dir := filepath.Dir(mainFile)
+		rel, err := filepath.Rel(filepath.Join(root, samplesDir), dir)
+		if err != nil {
+			return nil, err
+		}
+		env, err := requiredEnv(mainFile)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		samples = append(samples, Sample{
+			Path:    rel,
+			Dir:     dir,
+			Service: strings.SplitN(rel, "/", 2)[0],
+			Env:     env,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Path < samples[j].Path })
+	return samples, nil
+}
+
+// requiredEnv returns the environment variables the sample in mainFile reads
+// with samplekit.Getenv or os.Getenv, and the subscription every sample needs.
+func requiredEnv(mainFile string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), mainFile, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]bool{samplekit.SubscriptionIDEnv: true}
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Getenv" {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || (pkg.Name != "samplekit" && pkg.Name != "os") {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if name, err := strconv.Unquote(lit.Value); err == nil && name != samplekit.KeepResourceEnv {
+			env[name] = true
+		}
+		return true
+	})
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Filter returns the samples of the services, or of every service if services
+// is empty, whose path matches one of the patterns, or every path if patterns
+// is empty. A pattern is a path.Match pattern such as "compute/*", or the
+// path of a sample.
+func Filter(samples []Sample, services, patterns []string) ([]Sample, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var out []Sample
+	for _, s := range samples {
+		if len(services) > 0 && !contains(services, s.Service) {
+			continue
+		}
+		if len(patterns) > 0 && !matchAny(patterns, s.Path) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// MissingEnv returns the environment variables the sample needs that are not set.
+func (s Sample) MissingEnv() []string {
+	var missing []string
+	for _, name := range s.Env {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		// the patterns were checked by Filter
+		if ok, _ := path.Match(strings.TrimSuffix(pattern, "/"), p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findRoot returns the repository root containing dir.
+func findRoot(dir string) (string, error) {
+	for d := dir; ; {
+		if info, err := os.Stat(filepath.Join(d, samplesDir)); err == nil && info.IsDir() {
+			return d, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("%s is not in the samples repository; use -root", dir)
+		}
+		d = parent
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeSample writes a sample main.go under root reading the environment variables env.
+func writeSample(t *testing.T, root, path string, env ...string) {
+	t.Helper()
+	dir := filepath.Join(root, samplesDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package main\n\nimport (\n\t\"os\"\n\n\t\"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit\"\n)\n\nfunc run() {\n"
+	for i, name := range env {
+		if i%2 == 0 {
+			src += "\tsamplekit.Getenv(\"" + name + "\")\n"
+		} else {
+			src += "\tos.Getenv(\"" + name + "\")\n"
+		}
+	}
+	src += "\tos.Getenv(\"KEEP_RESOURCE\")\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeSample(t, root, "network/virtualnetwork")
+	writeSample(t, root, "keyvault/vault", "AZURE_TENANT_ID", "AZURE_OBJECT_ID")
+	writeSample(t, root, "compute/create_vm")
+	// not a sample: no main.go
+	if err := os.MkdirAll(filepath.Join(root, samplesDir, "compute", "testdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, s := range samples {
+		paths = append(paths, s.Path)
+	}
+	if want := []string{"compute/create_vm", "keyvault/vault", "network/virtualnetwork"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+	if want := []string{"AZURE_OBJECT_ID", "AZURE_SUBSCRIPTION_ID", "AZURE_TENANT_ID"}; !reflect.DeepEqual(samples[1].Env, want) {
+		t.Errorf("keyvault/vault env = %v, want %v", samples[1].Env, want)
+	}
+	if samples[1].Service != "keyvault" {
+		t.Errorf("service = %q, want keyvault", samples[1].Service)
+	}
+
+	sub := filepath.Join(root, samplesDir, "compute", "create_vm")
+	if got, err := findRoot(sub); err != nil || got != root {
+		t.Errorf("findRoot() = %q, %v, want %q", got, err, root)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	samples := []Sample{
+		{Path: "compute/create_vm", Service: "compute"},
+		{Path: "compute/disk", Service: "compute"},
+		{Path: "network/virtualnetwork", Service: "network"},
+		{Path: "sql/server", Service: "sql"},
+	}
+	tests := []struct {
+		services, patterns []string
+		want               []string
+	}{
+		{nil, nil, []string{"compute/create_vm", "compute/disk", "network/virtualnetwork", "sql/server"}},
+		{[]string{"compute", "sql"}, nil, []string{"compute/create_vm", "compute/disk", "sql/server"}},
+		{nil, []string{"compute/*"}, []string{"compute/create_vm", "compute/disk"}},
+		{[]string{"compute"}, []string{"*/d*", "sql/server"}, []string{"compute/disk"}},
+		{nil, []string{"network/virtualnetwork/"}, []string{"network/virtualnetwork"}},
+	}
+	for _, tt := range tests {
+		got, err := Filter(samples, tt.services, tt.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, s := range got {
+			paths = append(paths, s.Path)
+		}
+		if !reflect.DeepEqual(paths, tt.want) {
+			t.Errorf("Filter(%v, %v) = %v, want %v", tt.services, tt.patterns, paths, tt.want)
+		}
+	}
+
+	if _, err := Filter(samples, nil, []string{"["}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestOffline(t *testing.T) {
+	t.Setenv("SAMPLE_DRY_RUN", "")
+	t.Setenv("SAMPLE_REPLAY", "")
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-location", "eastus"}, false},
+		{[]string{"-dry-run"}, true},
+		{[]string{"--replay=cassette.json"}, true},
+		{[]string{"-record", "dry-run"}, false},
+	}
+	for _, tt := range tests {
+		if got := offline(tt.args); got != tt.want {
+			t.Errorf("offline(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestPublicCloud(t *testing.T) {
+	t.Setenv("SAMPLE_CLOUD", "")
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"-cloud", "public"}, true},
+		{[]string{"-cloud", "china"}, false},
+		{[]string{"--cloud=usgov", "-dry-run"}, false},
+		{[]string{"-location", "cloud"}, true},
+	}
+	for _, tt := range tests {
+		if got := publicCloud(tt.args); got != tt.want {
+			t.Errorf("publicCloud(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+	t.Setenv("SAMPLE_CLOUD", "custom-cloud.json")
+	if publicCloud(nil) {
+		t.Error("SAMPLE_CLOUD chose the public cloud")
+	}
+}
+
+func TestResourceGroupName(t *testing.T) {
+	a := resourceGroupName(Sample{Path: "compute/create_vm"}, "x7k2p9")
+	b := resourceGroupName(Sample{Path: "network/virtualnetwork"}, "x7k2p9")
+	if a != "sample-compute-create_vm-x7k2p9" {
+		t.Errorf("got %s", a)
+	}
+	if a == b {
+		t.Errorf("two samples share resource group %s", a)
+	}
+	if !hasFlag([]string{"--resource-group-name=rg"}, "resource-group-name") || hasFlag([]string{"-location", "resource-group-name"}, "resource-group-name") {
+		t.Error("hasFlag does not tell a flag from a value")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Command samples lists and runs the resource management samples under
+// sdk/resourcemanager.
+//
+//	go run ./cmd/samples list [-service compute,network] [pattern ...]
+//	go run ./cmd/samples run [-service compute] [-parallel 2] [-dry-run] [pattern ...] [-- sample flags]
+//
+// A pattern is a sample path such as compute/create_vm, or a path.Match
+// pattern such as "compute/*".
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const usage = `usage:
+  samples list [flags] [pattern ...]
+      list the samples and the environment variables they need
+  samples run [flags] [pattern ...] [-- sample flags]
+      run the samples and print a summary; the flags after -- are passed to every sample
+
+Run "samples list -h" or "samples run -h" for the flags.
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "list":
+		err = list(os.Args[2:], os.Stdout)
+	case "run":
+		err = run(os.Args[2:], os.Stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// selection holds the flags choosing the samples, common to every command.
+type selection struct {
+	root     string
+	services string
+}
+
+func (s *selection) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.root, "root", "", "root of the samples repository (default: the repository containing the working directory)")
+	fs.StringVar(&s.services, "service", "", "comma-separated services to select, for example compute,network")
+}
+
+// samples returns the selected samples matching patterns.
+func (s *selection) samples(patterns []string) ([]Sample, error) {
+	root := s.root
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if root, err = findRoot(wd); err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	if s.services != "" {
+		services = strings.Split(s.services, ",")
+	}
+	selected, err := Filter(all, services, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no sample matches")
+	}
+	return selected, nil
+}
+
+func list(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	var sel selection
+	sel.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	samples, err := sel.samples(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SAMPLE\tREADY\tENV")
+	for _, s := range samples {
+		ready := "yes"
+		if len(s.MissingEnv()) > 0 {
+			ready = "no"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Path, ready, strings.Join(s.Env, " "))
+	}
+	return w.Flush()
+}
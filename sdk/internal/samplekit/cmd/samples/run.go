@@ -0,0 +1,271 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+)
+
+// Result values of a sample run.
+const (
+	resultPass = "pass"
+	resultFail = "fail"
+	resultSkip = "skip"
+)
+
+// Result is the outcome of running one sample.
+type Result struct {
+	Sample   Sample
+	Result   string
+	Duration time.Duration
+	// Detail says why the sample failed or was skipped.
+	Detail string
+}
+
+// runOptions holds the flags of the run command.
+type runOptions struct {
+	parallel   int
+	dryRun     bool
+	logDir     string
+	reportDir  string
+	sampleArgs []string
+	// groupSuffix, if set, gives each sample a resource group of its own,
+	// named after the sample and ending with it.
+	groupSuffix string
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var sel selection
+	sel.register(fs)
+	var opts runOptions
+	fs.IntVar(&opts.parallel, "parallel", 1, "number of samples to run at the same time")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "run the samples in dry-run mode, printing their requests instead of sending them")
+	fs.StringVar(&opts.logDir, "logs", "", "directory for the output of each sample (default: a new temporary directory)")
+	fs.StringVar(&opts.reportDir, "reports", "", "directory for the JSON run report of each sample")
+
+	// the flags after -- are for the samples
+	for i, arg := range args {
+		if arg == "--" {
+			args, opts.sampleArgs = args[:i], args[i+1:]
+			break
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if opts.parallel < 1 {
+		return errors.New("-parallel must be at least 1")
+	}
+	if opts.dryRun {
+		opts.sampleArgs = append([]string{"-dry-run"}, opts.sampleArgs...)
+	}
+	// against Azure, samples running at the same time must not share a resource group
+	if opts.parallel > 1 && !offline(opts.sampleArgs) {
+		if hasFlag(opts.sampleArgs, samplekit.FlagName(samplekit.ResourceGroupNameSetting)) {
+			return errors.New("-parallel cannot be used with one resource group for all the samples; they get one each")
+		}
+		opts.groupSuffix = samplekit.RandomSuffix(time.Now().UnixNano())
+	}
+	samples, err := sel.samples(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if opts.logDir == "" {
+		if opts.logDir, err = os.MkdirTemp("", "samples-"); err != nil {
+			return err
+		}
+	}
+	for _, dir := range []string{opts.logDir, opts.reportDir} {
+		if dir != "" {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(out, "running %d samples; their output is in %s\n", len(samples), opts.logDir)
+
+	results := make([]Result, len(samples))
+	sem := make(chan struct{}, opts.parallel)
+	var wg sync.WaitGroup
+	for i, s := range samples {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s Sample) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runSample(s, opts)
+			fmt.Fprintf(out, "%s %s\n", results[i].Result, s.Path)
+		}(i, s)
+	}
+	wg.Wait()
+
+	failed := printSummary(out, results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d samples failed", failed, len(results))
+	}
+	return nil
+}
+
+// runSample runs the sample with go run, writing its output to the log directory.
+func runSample(s Sample, opts runOptions) Result {
+	if !offline(opts.sampleArgs) {
+		if missing := s.MissingEnv(); len(missing) > 0 {
+			return Result{Sample: s, Result: resultSkip, Detail: strings.Join(missing, ", ") + " not set"}
+		}
+	}
+
+	name := strings.ReplaceAll(s.Path, "/", "_")
+	logFile := filepath.Join(opts.logDir, name+".log")
+	f, err := os.Create(logFile)
+	if err != nil {
+		return Result{Sample: s, Result: resultFail, Detail: err.Error()}
+	}
+	defer f.Close()
+
+	// built first, as go run exits with status 1 whatever the status of the sample
+	binDir, err := os.MkdirTemp("", "sample")
+	if err != nil {
+		return Result{Sample: s, Result: resultFail, Detail: err.Error()}
+	}
+	defer os.RemoveAll(binDir)
+	bin := filepath.Join(binDir, "sample")
+	build := exec.Command("go", "build", "-o", bin, ".")
+	build.Dir = s.Dir
+	build.Stdout = f
+	build.Stderr = f
+	if err := build.Run(); err != nil {
+		return Result{Sample: s, Result: resultFail, Detail: fmt.Sprintf("build failed, see %s", logFile)}
+	}
+
+	cmd := exec.Command(bin, opts.sampleArgs...)
+	cmd.Dir = s.Dir
+	cmd.Stdout = f
+	cmd.Stderr = f
+	cmd.Env = os.Environ()
+	if opts.groupSuffix != "" {
+		cmd.Env = append(cmd.Env, samplekit.EnvName(samplekit.ResourceGroupNameSetting)+"="+resourceGroupName(s, opts.groupSuffix))
+	}
+	// against the public cloud, a sample with a golden template checks what it
+	// created; other clouds may export other API versions
+	if !offline(opts.sampleArgs) && publicCloud(opts.sampleArgs) {
+		if _, err := os.Stat(filepath.Join(s.Dir, samplekit.GoldenTemplateFile)); err == nil {
+			cmd.Env = append(cmd.Env, samplekit.GoldenTemplateEnv+"="+samplekit.GoldenTemplateFile)
+		}
+	}
+	if opts.reportDir != "" {
+		report, err := filepath.Abs(filepath.Join(opts.reportDir, name+".json"))
+		if err != nil {
+			return Result{Sample: s, Result: resultFail, Detail: err.Error()}
+		}
+		cmd.Env = append(cmd.Env, samplekit.ReportEnv+"="+report)
+	}
+
+	start := time.Now()
+	err = cmd.Run()
+	result := Result{Sample: s, Result: resultPass, Duration: time.Since(start).Round(time.Second)}
+	if err != nil {
+		result.Result = resultFail
+		result.Detail = fmt.Sprintf("%v, see %s", err, logFile)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.ExitCode() == samplekit.CrashExitCode {
+				result.Detail = fmt.Sprintf("crashed (%v), see %s", err, logFile)
+			} else if failure := samplekit.ExitFailure(exitErr.ExitCode()); failure != samplekit.FailureUnknown {
+				result.Detail = fmt.Sprintf("%s (%v), see %s", failure, err, logFile)
+			}
+		}
+	}
+	return result
+}
+
+// offline reports whether the sample flags make the samples run without Azure,
+// so they need none of their environment variables.
+func offline(sampleArgs []string) bool {
+	return hasFlag(sampleArgs, "dry-run", "replay") || os.Getenv(samplekit.DryRunEnv) != "" || os.Getenv(samplekit.ReplayEnv) != ""
+}
+
+// publicCloud reports whether the samples run in the public cloud, as they do
+// unless -cloud or SAMPLE_CLOUD choose another.
+func publicCloud(sampleArgs []string) bool {
+	name := os.Getenv(samplekit.CloudEnv)
+	if value, ok := flagValue(sampleArgs, "cloud"); ok {
+		name = value
+	}
+	return name == "" || strings.EqualFold(name, "public")
+}
+
+// flagValue returns the value the sample flags give flag name, if any, set
+// as -name=value or -name value.
+func flagValue(sampleArgs []string, name string) (string, bool) {
+	for i, arg := range sampleArgs {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		n, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if n != name {
+			continue
+		}
+		if hasValue {
+			return value, true
+		}
+		if i+1 < len(sampleArgs) {
+			return sampleArgs[i+1], true
+		}
+	}
+	return "", false
+}
+
+// hasFlag reports whether the sample flags set one of the flags names.
+func hasFlag(sampleArgs []string, names ...string) bool {
+	for _, arg := range sampleArgs {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		for _, n := range names {
+			if name == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resourceGroupName returns the resource group of sample s in a run whose
+// samples get one each.
+func resourceGroupName(s Sample, suffix string) string {
+	return "sample-" + strings.ReplaceAll(s.Path, "/", "-") + "-" + suffix
+}
+
+// printSummary prints a table of the results and returns the number of failed samples.
+func printSummary(out io.Writer, results []Result) int {
+	counts := map[string]int{}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSAMPLE\tRESULT\tDURATION\tDETAIL")
+	for _, r := range results {
+		counts[r.Result]++
+		duration := "-"
+		if r.Result != resultSkip {
+			duration = r.Duration.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Sample.Path, r.Result, duration, r.Detail)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d passed, %d failed, %d skipped\n", counts[resultPass], counts[resultFail], counts[resultSkip])
+	return counts[resultFail]
+}
@@ -0,0 +1,206 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Command sweep lists and deletes the resource groups sample runs left behind,
+// found by the tags samplekit puts on them. By default it deletes the groups
+// whose expiry time has passed, after asking for confirmation.
+//
+//	go run ./cmd/sweep [-dry-run] [-yes] [-all] [-run-id id]
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+func main() {
+	log.SetFlags(0)
+	err := sweep(context.Background(), os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// options holds the flags of the command.
+type options struct {
+	subscriptionID string
+	cloud          string
+	credential     string
+	runID          string
+	all            bool
+	dryRun         bool
+	yes            bool
+}
+
+func sweep(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	var opts options
+	fs.StringVar(&opts.subscriptionID, "subscription", os.Getenv(samplekit.SubscriptionIDEnv), "subscription to sweep")
+	fs.StringVar(&opts.cloud, "cloud", os.Getenv(samplekit.CloudEnv), "cloud to sweep: public, china, usgov or the path of a JSON file describing a custom cloud (default public)")
+	fs.StringVar(&opts.credential, "credential", os.Getenv(samplekit.CredentialEnv), "credential to sign in with, as for the samples (default default)")
+	fs.StringVar(&opts.runID, "run-id", "", "only sweep the resource group of this run, expired or not")
+	fs.BoolVar(&opts.all, "all", false, "also sweep the resource groups that have not expired yet")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "list the resource groups to sweep without deleting them")
+	fs.BoolVar(&opts.yes, "yes", false, "delete without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if opts.subscriptionID == "" {
+		return fmt.Errorf("set -subscription or %s", samplekit.SubscriptionIDEnv)
+	}
+
+	c, err := samplekit.LoadCloud(opts.cloud)
+	if err != nil {
+		return err
+	}
+	cred, err := samplekit.NewCredential(opts.credential, c)
+	if err != nil {
+		return err
+	}
+	client, err := armresources.NewResourceGroupsClient(opts.subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{Cloud: c.Configuration},
+	})
+	if err != nil {
+		return err
+	}
+	return sweepGroups(ctx, client, opts, in, out)
+}
+
+// sweepGroups lists the resource groups to sweep, and deletes them unless
+// opts say otherwise or the user does not confirm.
+func sweepGroups(ctx context.Context, client *armresources.ResourceGroupsClient, opts options, in io.Reader, out io.Writer) error {
+	groups, err := findGroups(ctx, client, opts.runID, opts.all, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "no resource group to sweep.")
+		return nil
+	}
+	if err := printGroups(out, groups); err != nil {
+		return err
+	}
+	if opts.dryRun {
+		fmt.Fprintf(out, "dry run: %d resource groups would be deleted.\n", len(groups))
+		return nil
+	}
+	if !opts.yes && !confirm(in, out, fmt.Sprintf("delete %d resource groups? [y/N] ", len(groups))) {
+		fmt.Fprintln(out, "nothing deleted.")
+		return nil
+	}
+	return deleteGroups(ctx, client, groups)
+}
+
+// findGroups returns the resource groups tagged by sample runs that expired
+// before now, every one if all is set, or the one of the run runID if set.
+// Groups already being deleted are left out.
+func findGroups(ctx context.Context, client *armresources.ResourceGroupsClient, runID string, all bool, now time.Time) ([]*armresources.ResourceGroup, error) {
+	filter := fmt.Sprintf("tagName eq '%s'", samplekit.RunIDTag)
+	if runID != "" {
+		filter += fmt.Sprintf(" and tagValue eq '%s'", runID)
+	}
+	var groups []*armresources.ResourceGroup
+	pager := client.NewListPager(&armresources.ResourceGroupsClientListOptions{Filter: &filter})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range page.Value {
+			if group.Properties != nil && group.Properties.ProvisioningState != nil && *group.Properties.ProvisioningState == "Deleting" {
+				continue
+			}
+			if all || runID != "" || samplekit.Expired(group.Tags, now) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return *groups[i].Name < *groups[j].Name })
+	return groups, nil
+}
+
+func printGroups(out io.Writer, groups []*armresources.ResourceGroup) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE GROUP\tLOCATION\tSAMPLE\tRUN\tCREATED BY\tEXPIRES")
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", *group.Name, value(group.Location),
+			tag(group, samplekit.SamplePathTag), tag(group, samplekit.RunIDTag),
+			tag(group, samplekit.CreatedByTag), tag(group, samplekit.ExpiresTag))
+	}
+	return w.Flush()
+}
+
+// confirm asks question on out and reports whether the answer read from in is yes.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprint(out, question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// deleteGroups begins deleting every group, then waits for the deletions.
+func deleteGroups(ctx context.Context, client *armresources.ResourceGroupsClient, groups []*armresources.ResourceGroup) error {
+	type deletion struct {
+		name string
+		wait func() error
+	}
+	failed := 0
+	var deletions []deletion
+	for _, group := range groups {
+		name := *group.Name
+		poller, err := client.BeginDelete(ctx, name, nil)
+		if err != nil {
+			log.Printf("cannot delete resource group %s: %v", name, err)
+			failed++
+			continue
+		}
+		deletions = append(deletions, deletion{name: name, wait: func() error {
+			_, err := samplekit.Wait(ctx, "delete resource group "+name, poller, nil)
+			return err
+		}})
+	}
+	for _, d := range deletions {
+		if err := d.wait(); err != nil {
+			log.Printf("cannot delete resource group %s: %v", d.name, err)
+			failed++
+			continue
+		}
+		log.Printf("deleted resource group %s.", d.name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resource groups were not deleted", failed, len(groups))
+	}
+	return nil
+}
+
+func tag(group *armresources.ResourceGroup, name string) string {
+	return value(group.Tags[name])
+}
+
+func value(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func group(name string, expires time.Time) *armresources.ResourceGroup {
+	return &armresources.ResourceGroup{
+		Name:     to.Ptr(name),
+		Location: to.Ptr("westus"),
+		Tags:     samplekit.NewRunTags("run-"+name, "compute/create_vm", "alice@example.com", expires),
+	}
+}
+
+// newFakeClient returns a client listing groups and recording the filter it
+// was asked for and the groups it deleted.
+func newFakeClient(t *testing.T, groups []*armresources.ResourceGroup, filter *string, deleted *[]string) *armresources.ResourceGroupsClient {
+	var mu sync.Mutex
+	srv := &resourcesfake.ResourceGroupsServer{
+		NewListPager: func(options *armresources.ResourceGroupsClientListOptions) (resp azfake.PagerResponder[armresources.ResourceGroupsClientListResponse]) {
+			*filter = *options.Filter
+			resp.AddPage(http.StatusOK, armresources.ResourceGroupsClientListResponse{
+				ResourceGroupListResult: armresources.ResourceGroupListResult{Value: groups},
+			}, nil)
+			return
+		},
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			mu.Lock()
+			*deleted = append(*deleted, name)
+			mu.Unlock()
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	}
+	client, err := armresources.NewResourceGroupsClient("00000000-0000-0000-0000-000000000000", &azfake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{Transport: resourcesfake.NewResourceGroupsServerTransport(srv)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestSweepGroups(t *testing.T) {
+	now := time.Now()
+	groups := []*armresources.ResourceGroup{
+		group("expired-b", now.Add(-time.Hour)),
+		group("expired-a", now.Add(-2*time.Hour)),
+		group("fresh", now.Add(time.Hour)),
+	}
+	tests := []struct {
+		name        string
+		opts        options
+		answer      string
+		wantFilter  string
+		wantDeleted []string
+		wantOut     string
+	}{
+		{"dry run", options{dryRun: true}, "", "tagName eq 'sample-run-id'", nil, "dry run: 2 resource groups would be deleted."},
+		{"declined", options{}, "n\n", "tagName eq 'sample-run-id'", nil, "delete 2 resource groups? [y/N] nothing deleted."},
+		{"confirmed", options{}, "yes\n", "tagName eq 'sample-run-id'", []string{"expired-a", "expired-b"}, "delete 2 resource groups? [y/N] "},
+		{"all without prompt", options{all: true, yes: true}, "", "tagName eq 'sample-run-id'", []string{"expired-a", "expired-b", "fresh"}, "fresh"},
+		{"run", options{runID: "run-fresh", yes: true}, "", "tagName eq 'sample-run-id' and tagValue eq 'run-fresh'", []string{"fresh"}, "run-fresh"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var filter string
+			var deleted []string
+			listed := groups
+			if test.opts.runID != "" {
+				// the service applies the filter
+				listed = groups[2:]
+			}
+			client := newFakeClient(t, listed, &filter, &deleted)
+			var out bytes.Buffer
+			if err := sweepGroups(context.Background(), client, test.opts, strings.NewReader(test.answer), &out); err != nil {
+				t.Fatal(err)
+			}
+			if filter != test.wantFilter {
+				t.Errorf("got filter %q, want %q", filter, test.wantFilter)
+			}
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, test.wantDeleted) {
+				t.Errorf("deleted %v, want %v", deleted, test.wantDeleted)
+			}
+			if !strings.Contains(out.String(), test.wantOut) {
+				t.Errorf("output does not contain %q:\n%s", test.wantOut, out.String())
+			}
+		})
+	}
+}
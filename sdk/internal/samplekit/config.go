@@ -0,0 +1,333 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ResourceGroupNameSetting and LocationSetting name the settings Main uses
+	// to create the sample's resource group.
+	ResourceGroupNameSetting = "resourceGroupName"
+	LocationSetting          = "location"
+
+	// ConfigFileEnv names the environment variable holding the path of a YAML
+	// config file. The -config flag takes precedence over it.
+	ConfigFileEnv = "SAMPLE_CONFIG"
+
+	// EnvPrefix starts the environment variable of every setting, for example
+	// SAMPLE_RESOURCE_GROUP_NAME for resourceGroupName.
+	EnvPrefix = "SAMPLE_"
+)
+
+// Settings maps setting names to the variables holding the names, location and
+// SKUs a sample uses. The values the variables hold when Load is called are the
+// defaults.
+//
+// Load overrides each value from, highest precedence first:
+//   - the command line flag, for example -resource-group-name
+//   - the environment variable, for example SAMPLE_RESOURCE_GROUP_NAME
+//   - the top level key of the YAML config file, for example resourceGroupName
+//
+// Settings marked with Unique that are not overridden get a random suffix.
+type Settings map[string]*string
+
+// options holds the command line options that are not settings.
+type options struct {
+	dryRun bool
+	record string
+	replay string
+	report string
+	// cleanupTimeout bounds the cleanup; zero for the default.
+	cleanupTimeout time.Duration
+	// operationTimeout bounds each long-running operation; zero for no limit.
+	operationTimeout time.Duration
+	// parallel bounds the steps of a Graph running at the same time; zero for the default.
+	parallel int
+	// retry configures the retries of failed requests.
+	retry policy.RetryOptions
+	// cloud is the cloud to run in.
+	cloud Cloud
+	// expiresAfter is how long after the run its resource group expires.
+	expiresAfter time.Duration
+	// reuse looks up the resources before creating them.
+	reuse bool
+	// softDeleted is what reuse mode does with soft-deleted resources.
+	softDeleted string
+	// registerProviders registers the providers the sample needs.
+	registerProviders bool
+	// credential is the kind of credential to sign in with.
+	credential string
+	// trace is where to export the spans of the run, if anywhere.
+	trace string
+	// state is the path of the state file.
+	state string
+	// resumeState is the state to resume, loaded when resume is set.
+	resumeState *State
+	// cassette is the cassette to replay, loaded when replay is set.
+	cassette *Cassette
+	// nameSeed is the seed the unique names were made with, if any.
+	nameSeed string
+	// goldenTemplate is the path of the golden template to compare the
+	// exported template with, if any.
+	goldenTemplate string
+	// updateGolden writes the golden template instead of comparing with it.
+	updateGolden bool
+	// requestLog is the path of the request log to write, if any.
+	requestLog string
+}
+
+// Load overrides the settings from the command line arguments args, the
+// environment and the config file.
+func (s Settings) Load(args []string) error {
+	_, err := s.load(args)
+	return err
+}
+
+func (s Settings) load(args []string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("sample", flag.ContinueOnError)
+	fs.BoolVar(&opts.dryRun, "dry-run", len(os.Getenv(DryRunEnv)) != 0, "print the requests instead of sending them")
+	fs.StringVar(&opts.record, "record", os.Getenv(RecordEnv), "path of a cassette to record the requests and responses to")
+	fs.StringVar(&opts.replay, "replay", os.Getenv(ReplayEnv), "path of a cassette to replay instead of sending the requests")
+	fs.DurationVar(&opts.cleanupTimeout, "cleanup-timeout", 0, "how long the cleanup may take (default 30m)")
+	fs.DurationVar(&opts.expiresAfter, "expires-after", DefaultExpiresAfter, "how long after the run the sweep command may delete its resource group")
+	fs.DurationVar(&opts.operationTimeout, "operation-timeout", 0, "how long each long-running operation may take (default no limit)")
+	fs.IntVar(&opts.parallel, "parallel", 0, "how many independent steps may run at the same time (default 4)")
+	fs.Var(int32Value{&opts.retry.MaxRetries}, "max-retries", "how many times to retry a failed request, or -1 for none (default 3)")
+	fs.DurationVar(&opts.retry.RetryDelay, "retry-delay", 0, "delay before the first retry, growing exponentially (default 800ms)")
+	fs.DurationVar(&opts.retry.MaxRetryDelay, "max-retry-delay", 0, "longest delay between retries (default 1m)")
+	fs.DurationVar(&opts.retry.TryTimeout, "try-timeout", 0, "how long each attempt of a request may take (default 1m)")
+	stateFile := os.Getenv(StateEnv)
+	if stateFile == "" {
+		stateFile = DefaultStateFile
+	}
+	fs.StringVar(&opts.state, "state", stateFile, "path of the file saving the long-running operations in progress")
+	fs.BoolVar(&opts.reuse, "reuse", len(os.Getenv(ReuseEnv)) != 0, "use the resources that already exist instead of creating them again, usually with KEEP_RESOURCE set")
+	fs.StringVar(&opts.softDeleted, "soft-deleted", os.Getenv(SoftDeletedEnv), "what -reuse does with a soft-deleted key vault or API Management service: recover or purge (default recover)")
+	fs.BoolVar(&opts.registerProviders, "register-providers", len(os.Getenv(RegisterProvidersEnv)) != 0, "register the resource providers the sample needs that are not registered in the subscription")
+	resume := fs.Bool("resume", len(os.Getenv(ResumeEnv)) != 0, "continue waiting for the operations saved in the state file instead of beginning them again")
+	cloudName := fs.String("cloud", os.Getenv(CloudEnv), "cloud to run in: public, china, usgov or the path of a JSON file describing a custom cloud (default public)")
+	fs.StringVar(&opts.credential, "credential", os.Getenv(CredentialEnv), "credential to sign in with: default, cli, env, secret, cert, workload, managed, or a comma separated chain such as cli,managed (default default)")
+	fs.StringVar(&opts.trace, "trace", os.Getenv(TraceEnv), "export OpenTelemetry spans of the run to stdout or otlp")
+	fs.StringVar(&opts.goldenTemplate, "golden-template", os.Getenv(GoldenTemplateEnv), "path of a golden template to compare the template of the resource group with once the sample has created its resources")
+	fs.BoolVar(&opts.updateGolden, "update-golden", len(os.Getenv(UpdateGoldenEnv)) != 0, "write the golden template instead of comparing with it")
+	fs.StringVar(&opts.requestLog, "request-log", os.Getenv(RequestLogEnv), "path of a log of every request with its status, duration and correlation IDs to write")
+	fs.StringVar(&opts.report, "report", os.Getenv(ReportEnv), "path of a JSON report of the run to write, or - for stdout")
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "path of a YAML file with setting overrides")
+	nameSeed := fs.String("name-seed", os.Getenv(NameSeedEnv), "seed of the random suffix of unique names, to replay a previous run")
+	flags := make(map[string]*string, len(s))
+	for _, name := range s.names() {
+		flags[name] = fs.String(FlagName(name), *s[name], fmt.Sprintf("overrides %s (env %s)", name, EnvName(name)))
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	var err error
+	if opts.cloud, err = LoadCloud(*cloudName); err != nil {
+		return opts, err
+	}
+	if err := checkSoftDeleted(opts.softDeleted); err != nil {
+		return opts, err
+	}
+	modes := 0
+	for _, set := range []bool{opts.dryRun, opts.record != "", opts.replay != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return opts, errors.New("only one of -dry-run, -record and -replay can be used")
+	}
+	if opts.updateGolden && opts.goldenTemplate == "" {
+		return opts, errors.New("-update-golden needs -golden-template")
+	}
+	if opts.goldenTemplate != "" && opts.dryRun {
+		return opts, errors.New("-golden-template cannot be used with -dry-run")
+	}
+	if opts.replay != "" {
+		if opts.cassette, err = LoadCassette(opts.replay); err != nil {
+			return opts, err
+		}
+		// the replay needs the recorded names
+		if *nameSeed == "" {
+			*nameSeed = opts.cassette.NameSeed
+		}
+	}
+	if *resume {
+		if opts.dryRun || opts.replay != "" {
+			return opts, errors.New("-resume cannot be used with -dry-run or -replay")
+		}
+		if opts.resumeState, err = LoadState(opts.state); err != nil {
+			return opts, fmt.Errorf("cannot resume: %w", err)
+		}
+		// the resumed operations need the names they were begun with
+		if *nameSeed == "" {
+			*nameSeed = opts.resumeState.NameSeed
+		}
+	}
+
+	overridden := map[string]bool{}
+	if *configFile != "" {
+		if err := s.loadFile(*configFile, overridden); err != nil {
+			return opts, err
+		}
+	}
+
+	for _, name := range s.names() {
+		if value, ok := os.LookupEnv(EnvName(name)); ok && value != "" {
+			*s[name] = value
+			overridden[name] = true
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for name, value := range flags {
+			if FlagName(name) == f.Name {
+				*s[name] = *value
+				overridden[name] = true
+			}
+		}
+	})
+
+	// the default locations are in the public cloud
+	s.applyCloud(opts.cloud, overridden)
+
+	if opts.reuse && *nameSeed == "" {
+		// a rerun must find the resources of the previous run by their names
+		*nameSeed = reuseSeed(os.Getenv(SubscriptionIDEnv), s.Get(ResourceGroupNameSetting))
+	}
+	opts.nameSeed, err = s.makeUnique(*nameSeed, overridden)
+	return opts, err
+}
+
+// makeUnique appends the run's random suffix to the unique names that were not
+// overridden. It returns the seed of the suffix, or "" if there are no such names.
+func (s Settings) makeUnique(nameSeed string, overridden map[string]bool) (string, error) {
+	var unique []string
+	for _, name := range s.names() {
+		if _, ok := uniqueRule(s[name]); ok && !overridden[name] {
+			unique = append(unique, name)
+		}
+	}
+	if len(unique) == 0 {
+		return "", nil
+	}
+
+	seed := time.Now().UnixNano()
+	if nameSeed != "" {
+		var err error
+		if seed, err = strconv.ParseInt(nameSeed, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid name seed %q: %w", nameSeed, err)
+		}
+	}
+	log.Printf("unique names use seed %d; set %s=%d to get the same names again.", seed, NameSeedEnv, seed)
+
+	suffix := RandomSuffix(seed)
+	for _, name := range unique {
+		rule, _ := uniqueRule(s[name])
+		*s[name] = rule.Unique(*s[name], suffix)
+	}
+	return strconv.FormatInt(seed, 10), nil
+}
+
+// Get returns the value of the setting name, or "" if the sample has no such setting.
+func (s Settings) Get(name string) string {
+	if p, ok := s[name]; ok && p != nil {
+		return *p
+	}
+	return ""
+}
+
+func (s Settings) loadFile(path string, overridden map[string]bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// the file can be shared by several samples, so keys a sample does not use are ignored
+	var values map[string]string
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("cannot parse config file %s: %w", path, err)
+	}
+	for name, value := range values {
+		if p, ok := s[name]; ok {
+			*p = value
+			overridden[name] = true
+		}
+	}
+	return nil
+}
+
+func (s Settings) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FlagName returns the command line flag of the setting name, for example
+// "storage-account-sku-name" for "storageAccountSKUName".
+func FlagName(name string) string {
+	return strings.Join(words(name), "-")
+}
+
+// EnvName returns the environment variable of the setting name, for example
+// "SAMPLE_STORAGE_ACCOUNT_SKU_NAME" for "storageAccountSKUName".
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(words(name), "_"))
+}
+
+// words splits a camel case name into lower case words, keeping acronyms such as SKU or ID together.
+func words(name string) []string {
+	var out []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := unicode.IsUpper(runes[i])
+		prevUpper := unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if upper && (!prevUpper || nextLower) {
+			out = append(out, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(out, strings.ToLower(string(runes[start:])))
+}
+
+// int32Value is a flag.Value setting an int32, such as RetryOptions.MaxRetries.
+type int32Value struct {
+	p *int32
+}
+
+func (v int32Value) String() string {
+	if v.p == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*v.p), 10)
+}
+
+func (v int32Value) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v.p = int32(n)
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name, flag, env string
+	}{
+		{"location", "location", "SAMPLE_LOCATION"},
+		{"resourceGroupName", "resource-group-name", "SAMPLE_RESOURCE_GROUP_NAME"},
+		{"storageAccountSKUName", "storage-account-sku-name", "SAMPLE_STORAGE_ACCOUNT_SKU_NAME"},
+		{"apiID", "api-id", "SAMPLE_API_ID"},
+	}
+	for _, tt := range tests {
+		if got := FlagName(tt.name); got != tt.flag {
+			t.Errorf("FlagName(%q) = %q, want %q", tt.name, got, tt.flag)
+		}
+		if got := EnvName(tt.name); got != tt.env {
+			t.Errorf("EnvName(%q) = %q, want %q", tt.name, got, tt.env)
+		}
+	}
+}
+
+func TestSettingsPrecedence(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte("location: eastus\nvmName: file-vm\nskuName: file-sku\notherSampleName: ignored\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigFileEnv, configFile)
+	t.Setenv("SAMPLE_VM_NAME", "env-vm")
+	t.Setenv("SAMPLE_SKU_NAME", "env-sku")
+
+	var (
+		location          = "westus"
+		resourceGroupName = "sample-resource-group"
+		vmName            = "sample-vm"
+		skuName           = "Standard_LRS"
+	)
+	settings := Settings{
+		LocationSetting:          &location,
+		ResourceGroupNameSetting: &resourceGroupName,
+		"vmName":                 &vmName,
+		"skuName":                &skuName,
+	}
+	if err := settings.Load([]string{"-sku-name", "flag-sku"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		LocationSetting:          "eastus",                // config file over default
+		ResourceGroupNameSetting: "sample-resource-group", // default
+		"vmName":                 "env-vm",                // env over config file
+		"skuName":                "flag-sku",              // flag over env
+	}
+	for name, value := range want {
+		if got := settings.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestSettingsConfigFlag(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("location: eastus2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigFileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	location := "westus"
+	settings := Settings{LocationSetting: &location}
+	if err := settings.Load([]string{"-config", configFile}); err != nil {
+		t.Fatal(err)
+	}
+	if location != "eastus2" {
+		t.Fatalf("location = %q, want eastus2", location)
+	}
+
+	if err := settings.Load([]string{"-unknown-flag", "x"}); err == nil {
+		t.Fatal("expected an error for an unknown flag")
+	}
+}
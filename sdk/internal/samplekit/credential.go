@@ -0,0 +1,194 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// CredentialEnv names the environment variable choosing how samples sign in.
+// The -credential flag takes precedence over it.
+const CredentialEnv = "SAMPLE_CREDENTIAL"
+
+// The kinds of credential NewCredential can make. A comma separated list of
+// kinds makes a chain trying them in order, such as "cli,managed".
+const (
+	// DefaultCredential tries the environment, workload identity, managed
+	// identity and the Azure CLI in turn, as DefaultAzureCredential does.
+	DefaultCredential = "default"
+	// CLICredential uses the account signed in with az login. The Azure CLI
+	// signs in to the cloud chosen with az cloud set, which the credential
+	// cannot check, so it is only used in the public cloud.
+	CLICredential = "cli"
+	// EnvironmentCredential uses the service principal or user described by
+	// the AZURE_* variables EnvironmentCredential reads.
+	EnvironmentCredential = "env"
+	// SecretCredential uses the service principal AZURE_TENANT_ID and
+	// AZURE_CLIENT_ID with the secret AZURE_CLIENT_SECRET.
+	SecretCredential = "secret"
+	// CertificateCredential uses the service principal AZURE_TENANT_ID and
+	// AZURE_CLIENT_ID with the certificate at AZURE_CLIENT_CERTIFICATE_PATH,
+	// decrypted with AZURE_CLIENT_CERTIFICATE_PASSWORD if set.
+	CertificateCredential = "cert"
+	// WorkloadIdentityCredential uses the Kubernetes workload identity
+	// described by AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_FEDERATED_TOKEN_FILE.
+	WorkloadIdentityCredential = "workload"
+	// ManagedIdentityCredential uses the managed identity of the host, the
+	// user-assigned one with the client ID AZURE_CLIENT_ID if set.
+	ManagedIdentityCredential = "managed"
+)
+
+// NewCredential returns the credential of the kinds, a kind or a comma
+// separated chain of kinds, signing in to cloud c.
+func NewCredential(kinds string, c Cloud) (azcore.TokenCredential, error) {
+	if kinds == "" {
+		kinds = DefaultCredential
+	}
+	var chain []azcore.TokenCredential
+	for _, kind := range strings.Split(kinds, ",") {
+		cred, err := newCredential(strings.TrimSpace(kind), c)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create %s credential: %w", kind, err)
+		}
+		chain = append(chain, cred)
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return azidentity.NewChainedTokenCredential(chain, nil)
+}
+
+func newCredential(kind string, c Cloud) (azcore.TokenCredential, error) {
+	clientOptions := azcore.ClientOptions{Cloud: c.Configuration}
+	switch kind {
+	case DefaultCredential:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: clientOptions})
+	case CLICredential:
+		if c.Name != PublicCloud.Name {
+			return nil, fmt.Errorf("the Azure CLI signs in to the cloud of az cloud set, not necessarily %s; sign in with another -credential", c.Name)
+		}
+		return azidentity.NewAzureCLICredential(nil)
+	case EnvironmentCredential:
+		return azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{ClientOptions: clientOptions})
+	case SecretCredential:
+		tenantID, clientID, err := servicePrincipal()
+		if err != nil {
+			return nil, err
+		}
+		secret, err := Getenv("AZURE_CLIENT_SECRET")
+		if err != nil {
+			return nil, err
+		}
+		return azidentity.NewClientSecretCredential(tenantID, clientID, secret, &azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions})
+	case CertificateCredential:
+		tenantID, clientID, err := servicePrincipal()
+		if err != nil {
+			return nil, err
+		}
+		path, err := Getenv("AZURE_CLIENT_CERTIFICATE_PATH")
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var password []byte
+		if p := os.Getenv("AZURE_CLIENT_CERTIFICATE_PASSWORD"); p != "" {
+			password = []byte(p)
+		}
+		certs, key, err := azidentity.ParseCertificates(data, password)
+		if err != nil {
+			return nil, err
+		}
+		return azidentity.NewClientCertificateCredential(tenantID, clientID, certs, key, &azidentity.ClientCertificateCredentialOptions{ClientOptions: clientOptions})
+	case WorkloadIdentityCredential:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{ClientOptions: clientOptions})
+	case ManagedIdentityCredential:
+		options := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+		if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+			options.ID = azidentity.ClientID(clientID)
+		}
+		return azidentity.NewManagedIdentityCredential(options)
+	}
+	return nil, fmt.Errorf("unknown credential %q; use %s", kind, strings.Join([]string{
+		DefaultCredential, CLICredential, EnvironmentCredential, SecretCredential,
+		CertificateCredential, WorkloadIdentityCredential, ManagedIdentityCredential,
+	}, ", "))
+}
+
+func servicePrincipal() (tenantID, clientID string, err error) {
+	if tenantID, err = Getenv("AZURE_TENANT_ID"); err != nil {
+		return "", "", err
+	}
+	clientID, err = Getenv("AZURE_CLIENT_ID")
+	return tenantID, clientID, err
+}
+
+// tokenClaims are the claims of an access token identifying who signed in.
+type tokenClaims struct {
+	ObjectID string `json:"oid"`
+	TenantID string `json:"tid"`
+	// UPN names a user, AppID a service principal or managed identity.
+	UPN   string `json:"upn"`
+	AppID string `json:"appid"`
+}
+
+// SignIn gets a Resource Manager token with the sample's credential and sets
+// TenantID and ObjectID to the tenant and object ID of the principal it was
+// issued to.
+func (s *Sample) SignIn(ctx context.Context) error {
+	scope := "https://management.core.windows.net//.default"
+	if rm, ok := s.Cloud.Configuration.Services[cloud.ResourceManager]; ok && rm.Audience != "" {
+		scope = strings.TrimSuffix(rm.Audience, "/") + "/.default"
+	}
+	token, err := s.Credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return fmt.Errorf("cannot sign in: %w", err)
+	}
+	claims, err := parseClaims(token.Token)
+	if err != nil {
+		return fmt.Errorf("cannot read the token: %w", err)
+	}
+
+	s.TenantID, s.ObjectID = claims.TenantID, claims.ObjectID
+	s.principal = claims.UPN
+	if s.principal == "" {
+		s.principal = "application " + claims.AppID
+	}
+	log.Printf("signed in as %s, object ID %s in tenant %s.", s.principal, s.ObjectID, s.TenantID)
+	return nil
+}
+
+// parseClaims returns the claims of a JWT access token, without verifying it.
+func parseClaims(token string) (tokenClaims, error) {
+	var claims tokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, fmt.Errorf("got %d parts instead of 3", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims, err
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, err
+	}
+	if claims.ObjectID == "" || claims.TenantID == "" {
+		return claims, errors.New("the token has no oid or tid claim")
+	}
+	return claims, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const tenantID = "33333333-3333-3333-3333-333333333333"
+
+func TestNewCredential(t *testing.T) {
+	t.Setenv("AZURE_TENANT_ID", tenantID)
+	t.Setenv("AZURE_CLIENT_ID", "11111111-1111-1111-1111-111111111111")
+	t.Setenv("AZURE_CLIENT_SECRET", "secret")
+
+	cred, err := NewCredential("secret", PublicCloud)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cred.(*azidentity.ClientSecretCredential); !ok {
+		t.Errorf("got %T for secret", cred)
+	}
+	cred, err = NewCredential("env, managed", USGovernmentCloud)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cred.(*azidentity.ChainedTokenCredential); !ok {
+		t.Errorf("got %T for a chain", cred)
+	}
+
+	if _, err := NewCredential("cli", USGovernmentCloud); err == nil || !strings.Contains(err.Error(), "az cloud set") {
+		t.Errorf("got error %v for the Azure CLI in another cloud", err)
+	}
+	if _, err := NewCredential("cli,browser", PublicCloud); err == nil || !strings.Contains(err.Error(), `unknown credential "browser"`) {
+		t.Errorf("got error %v for an unknown credential", err)
+	}
+	t.Setenv("AZURE_CLIENT_SECRET", "")
+	if _, err := NewCredential("secret", PublicCloud); err == nil || !strings.Contains(err.Error(), "AZURE_CLIENT_SECRET is not set") {
+		t.Errorf("got error %v without a secret", err)
+	}
+}
+
+// tokenCredential hands out token, recording the scopes it was asked for.
+type tokenCredential struct {
+	token  string
+	scopes []string
+}
+
+func (c *tokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.scopes = options.Scopes
+	return azcore.AccessToken{Token: c.token, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestSignIn(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	const objectID = "22222222-2222-2222-2222-222222222222"
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"oid":"` + objectID + `","tid":"` + tenantID + `","upn":"alice@example.com"}`))
+	cred := &tokenCredential{token: "header." + claims + ".signature"}
+	sample := &Sample{Cloud: ChinaCloud, Credential: cred}
+	if err := sample.SignIn(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sample.ObjectID != objectID || sample.TenantID != tenantID {
+		t.Errorf("got object ID %q in tenant %q", sample.ObjectID, sample.TenantID)
+	}
+	if len(cred.scopes) != 1 || cred.scopes[0] != "https://management.core.chinacloudapi.cn/.default" {
+		t.Errorf("got scopes %v", cred.scopes)
+	}
+	if want := "signed in as alice@example.com, object ID " + objectID + " in tenant " + tenantID + "."; !strings.Contains(out.String(), want) {
+		t.Errorf("the log does not contain %q:\n%s", want, out.String())
+	}
+
+	cred.token = "not a token"
+	if err := sample.SignIn(context.Background()); err == nil {
+		t.Error("expected an error for a token that is not a JWT")
+	}
+}
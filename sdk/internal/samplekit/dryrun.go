@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DryRunEnv names the environment variable that, when set to anything other
+// than empty, runs the sample in dry-run mode. The -dry-run flag does the same.
+const DryRunEnv = "SAMPLE_DRY_RUN"
+
+// DryRunTransport prints every request instead of sending it, and answers with
+// a synthetic success response so the sample carries on:
+//   - PUT and PATCH return the request body with the resource's id, name and
+//     a Succeeded provisioning state added
+//   - GET returns what the last PUT or PATCH of the resource returned, or an
+//     empty list for a collection
+//   - HEAD returns 404, as nothing has been created
+//   - POST to a checkNameAvailability action reports the name as available
+//   - other DELETE and POST requests return 200 with an empty object
+//
+// Values only Azure can know, such as keys or endpoints, are missing from the
+// responses.
+type DryRunTransport struct {
+	mu  sync.Mutex
+	out io.Writer
+	// resources holds the resources put in this run by lower case path.
+	resources map[string]map[string]any
+}
+
+// NewDryRunTransport returns a DryRunTransport printing to out.
+func NewDryRunTransport(out io.Writer) *DryRunTransport {
+	return &DryRunTransport{out: out, resources: map[string]map[string]any{}}
+}
+
+// Do implements policy.Transporter.
+func (t *DryRunTransport) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.print(req, body)
+	key := strings.ToLower(req.URL.Path)
+
+	status := http.StatusOK
+	var resource map[string]any
+	switch req.Method {
+	case http.MethodPut, http.MethodPatch:
+		resource = map[string]any{}
+		if len(body) > 0 {
+			// a body that is not an object, such as a policy document, is echoed as an empty resource
+			_ = json.Unmarshal(body, &resource)
+		}
+		resource = syntheticResource(req, resource)
+		t.resources[key] = resource
+	case http.MethodGet:
+		if isCollection(req.URL.Path) {
+			resource = map[string]any{"value": []any{}}
+		} else if resource = t.resources[key]; resource == nil {
+			resource = syntheticResource(req, map[string]any{})
+		}
+	case http.MethodHead:
+		status = http.StatusNotFound
+	case http.MethodPost:
+		resource = map[string]any{}
+		if strings.HasSuffix(strings.ToLower(req.URL.Path), "/checknameavailability") {
+			resource["nameAvailable"] = true
+		}
+	case http.MethodDelete:
+		delete(t.resources, key)
+		resource = map[string]any{}
+	default:
+		resource = map[string]any{}
+	}
+
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Request:    req,
+		Body:       http.NoBody,
+	}
+	if resource != nil {
+		data, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		resp.ContentLength = int64(len(data))
+	}
+	return resp, nil
+}
+
+func (t *DryRunTransport) print(req *http.Request, body []byte) {
+	fmt.Fprintf(t.out, "%s %s\n", req.Method, req.URL)
+	if len(body) == 0 {
+		return
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.out.Write(body)
+	} else {
+		indented.WriteTo(t.out)
+	}
+	fmt.Fprintln(t.out)
+}
+
+// syntheticResource fills in what Azure adds to a resource it created.
+func syntheticResource(req *http.Request, resource map[string]any) map[string]any {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	resource["id"] = req.URL.Path
+	resource["name"] = segments[len(segments)-1]
+	if len(segments) >= 2 {
+		resource["type"] = resourceType(segments)
+	}
+	properties, ok := resource["properties"].(map[string]any)
+	if !ok {
+		properties = map[string]any{}
+		resource["properties"] = properties
+	}
+	properties["provisioningState"] = "Succeeded"
+	return resource
+}
+
+// resourceType returns the ARM type, such as Microsoft.Storage/storageAccounts, of the resource path segments.
+func resourceType(segments []string) string {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") && i+1 < len(segments) {
+			types := []string{segments[i+1]}
+			for j := i + 2; j < len(segments); j += 2 {
+				types = append(types, segments[j])
+			}
+			return strings.Join(types, "/")
+		}
+	}
+	return segments[len(segments)-2]
+}
+
+// isCollection reports whether path addresses a list of resources rather than one resource.
+// Resource paths alternate between a type and a name, so a collection ends with a type.
+func isCollection(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			// providers/{namespace}/{type}/{name}/...
+			return (len(segments)-i)%2 == 1
+		}
+	}
+	// subscriptions/{id}/resourcegroups/{name}/...
+	return len(segments)%2 == 1
+}
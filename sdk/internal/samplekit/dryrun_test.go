@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Setenv(SubscriptionIDEnv, "")
+	t.Setenv(KeepResourceEnv, "")
+	var out bytes.Buffer
+	sample := NewDryRun("sample-resource-group", "westus", &out)
+	if sample.SubscriptionID != placeholderID {
+		t.Fatalf("SubscriptionID = %q, want %q", sample.SubscriptionID, placeholderID)
+	}
+
+	err := sample.Run(context.Background(), func(ctx context.Context, s *Sample) error {
+		resourceGroup, err := s.CreateResourceGroup(ctx)
+		if err != nil {
+			return err
+		}
+		if *resourceGroup.Name != "sample-resource-group" || *resourceGroup.Properties.ProvisioningState != "Succeeded" {
+			t.Errorf("unexpected resource group %+v", resourceGroup)
+		}
+
+		client, err := armresources.NewClient(s.SubscriptionID, s.Credential, s.ClientOptions)
+		if err != nil {
+			return err
+		}
+		pager := client.NewListByResourceGroupPager(s.ResourceGroupName, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			if len(page.Value) != 0 {
+				t.Errorf("got %d resources, want none", len(page.Value))
+			}
+		}
+
+		groups, err := s.ResourceGroupsClient()
+		if err != nil {
+			return err
+		}
+		exists, err := groups.CheckExistence(ctx, "other-resource-group", nil)
+		if err != nil {
+			return err
+		}
+		if exists.Success {
+			t.Error("other-resource-group should not exist")
+		}
+		_, err = groups.Update(ctx, s.ResourceGroupName, armresources.ResourceGroupPatchable{
+			Tags: map[string]*string{"owner": to.Ptr("alice")},
+		}, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	printed := out.String()
+	for _, want := range []string{
+		"PUT https://management.azure.com/subscriptions/" + placeholderID + "/resourcegroups/sample-resource-group?api-version=",
+		"\n{\n  \"location\": \"westus\"\n}\n",
+		"GET https://management.azure.com/subscriptions/" + placeholderID + "/resourceGroups/sample-resource-group/resources?api-version=",
+		"HEAD https://management.azure.com/subscriptions/" + placeholderID + "/resourcegroups/other-resource-group?api-version=",
+		"PATCH https://management.azure.com/subscriptions/" + placeholderID + "/resourcegroups/sample-resource-group?api-version=",
+		"\"owner\": \"alice\"",
+		"DELETE https://management.azure.com/subscriptions/" + placeholderID + "/resourcegroups/sample-resource-group?api-version=",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("output does not contain %q:\n%s", want, printed)
+		}
+	}
+}
+
+func TestDryRunResponses(t *testing.T) {
+	tests := []struct {
+		path       string
+		collection bool
+		typ        string
+	}{
+		{"/subscriptions/s/resourcegroups/rg", false, "resourcegroups"},
+		{"/subscriptions/s/resourceGroups/rg/resources", true, ""},
+		{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts", true, ""},
+		{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa", false, "Microsoft.Storage/storageAccounts"},
+		{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa/blobServices/default/containers/c", false, "Microsoft.Storage/storageAccounts/blobServices/containers"},
+	}
+	for _, tt := range tests {
+		if got := isCollection(tt.path); got != tt.collection {
+			t.Errorf("isCollection(%q) = %v, want %v", tt.path, got, tt.collection)
+		}
+		if tt.collection {
+			continue
+		}
+		if got := resourceType(strings.Split(strings.Trim(tt.path, "/"), "/")); got != tt.typ {
+			t.Errorf("resourceType(%q) = %q, want %q", tt.path, got, tt.typ)
+		}
+	}
+}
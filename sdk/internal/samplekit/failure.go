@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Failure is the category of the error a sample failed with. Main exits with
+// the exit code of the category, so a pipeline can tell a subscription or
+// capacity problem from a regression of the sample's code.
+type Failure int
+
+const (
+	// FailureUnknown is any other error, most likely a bug of the sample.
+	FailureUnknown Failure = iota
+	// FailureQuota is a subscription out of quota for the resource.
+	FailureQuota
+	// FailureSKUNotAvailable is a SKU or size not offered, or out of
+	// capacity, in the region.
+	FailureSKUNotAvailable
+	// FailureNameConflict is a name already taken, by a resource of this or
+	// another subscription, or by a soft-deleted resource.
+	FailureNameConflict
+	// FailureAuthorization is a principal that could not sign in, or is not
+	// allowed to do the operation.
+	FailureAuthorization
+	// FailureProviderNotRegistered is a resource provider not registered in
+	// the subscription.
+	FailureProviderNotRegistered
+	// FailureTransient is a failure of Azure that may not happen again, such
+	// as throttling, a server error or a timeout.
+	FailureTransient
+	// FailureConfig is an invalid flag, environment variable or config file,
+	// found before the sample ran.
+	FailureConfig
+)
+
+// CrashExitCode is the exit code of a Go program that panicked or hit a fatal
+// error.
+const CrashExitCode = 2
+
+// failures holds the name, exit code and advice of each category. No category
+// exits with CrashExitCode, so a crash is never mistaken for a category.
+var failures = map[Failure]struct {
+	name     string
+	exitCode int
+	advice   string
+}{
+	FailureUnknown:               {"unknown", 1, ""},
+	FailureQuota:                 {"quota exceeded", 3, "request a quota increase for the subscription, or run in another region with -location"},
+	FailureSKUNotAvailable:       {"SKU not available", 4, "choose another SKU or size with the sample's settings, or run in another region with -location"},
+	FailureNameConflict:          {"name conflict", 5, "set another name, purge the soft-deleted resource, or run with -reuse to use the existing one"},
+	FailureAuthorization:         {"authorization failure", 6, "check the role assignments of the signed-in principal on the subscription, or sign in with another -credential"},
+	FailureProviderNotRegistered: {"provider not registered", 7, "register the resource provider with az provider register --namespace <namespace>"},
+	FailureTransient:             {"transient", 8, "run the sample again, or allow more with -max-retries and -operation-timeout"},
+	FailureConfig:                {"invalid configuration", 9, "fix the flags, environment variables or config file; -h lists the flags"},
+}
+
+// String returns the name of the category.
+func (f Failure) String() string {
+	return failures[f].name
+}
+
+// ExitCode returns the exit code of a sample failing with an error of the category.
+func (f Failure) ExitCode() int {
+	return failures[f].exitCode
+}
+
+// Advice tells what to do about an error of the category, or "" for FailureUnknown.
+func (f Failure) Advice() string {
+	return failures[f].advice
+}
+
+// ExitFailure returns the category a sample that exited with code failed
+// with, FailureUnknown if none, as for a crash.
+func ExitFailure(code int) Failure {
+	for f, failure := range failures {
+		if failure.exitCode == code {
+			return f
+		}
+	}
+	return FailureUnknown
+}
+
+// errorCodes maps the ErrorCode of the Azure error responses to their category.
+var errorCodes = map[string]Failure{
+	"QuotaExceeded":                       FailureQuota,
+	"ResourceQuotaExceeded":               FailureQuota,
+	"SubscriptionQuotaExceeded":           FailureQuota,
+	"RegionalQuotaExceeded":               FailureQuota,
+	"SkuNotAvailable":                     FailureSKUNotAvailable,
+	"AllocationFailed":                    FailureSKUNotAvailable,
+	"ZonalAllocationFailed":               FailureSKUNotAvailable,
+	"OverconstrainedAllocationRequest":    FailureSKUNotAvailable,
+	"LocationNotAvailableForResourceType": FailureSKUNotAvailable,
+	"RegionDoesNotAllowProvisioning":      FailureSKUNotAvailable,
+	"ProvisioningDisabled":                FailureSKUNotAvailable,
+	"NameNotAvailable":                    FailureNameConflict,
+	"StorageAccountAlreadyTaken":          FailureNameConflict,
+	"StorageAccountAlreadyExists":         FailureNameConflict,
+	"VaultAlreadyExists":                  FailureNameConflict,
+	"ServiceAlreadyExists":                FailureNameConflict,
+	"ServerAlreadyExists":                 FailureNameConflict,
+	"DatabaseAccountAlreadyExists":        FailureNameConflict,
+	"AuthorizationFailed":                 FailureAuthorization,
+	"LinkedAuthorizationFailed":           FailureAuthorization,
+	"AuthenticationFailed":                FailureAuthorization,
+	"InvalidAuthenticationToken":          FailureAuthorization,
+	"InvalidAuthenticationTokenTenant":    FailureAuthorization,
+	"Forbidden":                           FailureAuthorization,
+	"MissingSubscriptionRegistration":     FailureProviderNotRegistered,
+	"SubscriptionNotRegistered":           FailureProviderNotRegistered,
+	"MissingRegistrationForLocation":      FailureProviderNotRegistered,
+	"TooManyRequests":                     FailureTransient,
+	"RetryableError":                      FailureTransient,
+	"InternalServerError":                 FailureTransient,
+	"ServiceUnavailable":                  FailureTransient,
+	"GatewayTimeout":                      FailureTransient,
+	"AnotherOperationInProgress":          FailureTransient,
+	"OperationPreempted":                  FailureTransient,
+}
+
+// Classify returns the category of err. The ErrorCode of an Azure error
+// response decides, or else its status code. A failed pre-flight check has
+// the category of what it found missing.
+//
+// A conflict is not a name conflict unless its ErrorCode says so, as Azure
+// answers 409 to many requests conflicting with the state of a resource; nor
+// is OperationNotAllowed a quota error unless its message is about quota.
+func Classify(err error) Failure {
+	var respErr *azcore.ResponseError
+	var authErr *azidentity.AuthenticationFailedError
+	var classified interface{ Failure() Failure }
+	switch {
+	case err == nil:
+		return FailureUnknown
+	case errors.As(err, &classified):
+		return classified.Failure()
+	case errors.As(err, &respErr):
+		if f, ok := errorCodes[respErr.ErrorCode]; ok {
+			return f
+		}
+		if respErr.ErrorCode == "OperationNotAllowed" && strings.Contains(strings.ToLower(respErr.Error()), "quota") {
+			return FailureQuota
+		}
+		return classifyCode(respErr.ErrorCode, respErr.StatusCode)
+	case errors.As(err, &authErr):
+		return FailureAuthorization
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTransient
+	}
+	return FailureUnknown
+}
+
+// configError returns err, found in the flags, environment or config file,
+// as an error of FailureConfig.
+func configError(err error) error {
+	return failureError{FailureConfig, err.Error()}
+}
+
+// classifyCode returns the category of an error response whose ErrorCode is
+// not in errorCodes.
+func classifyCode(code string, statusCode int) Failure {
+	switch {
+	case strings.Contains(strings.ToLower(code), "quota"):
+		return FailureQuota
+	case strings.HasSuffix(code, "AlreadyExists"), strings.HasSuffix(code, "AlreadyTaken"), strings.HasSuffix(code, "NameNotAvailable"):
+		return FailureNameConflict
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return FailureAuthorization
+	case statusCode == http.StatusTooManyRequests, statusCode >= http.StatusInternalServerError:
+		return FailureTransient
+	}
+	return FailureUnknown
+}
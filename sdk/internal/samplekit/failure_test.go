@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+func TestClassify(t *testing.T) {
+	responseError := func(statusCode int, code string) error {
+		return fmt.Errorf("cannot create vault: %w", &azcore.ResponseError{StatusCode: statusCode, ErrorCode: code})
+	}
+	// an error response read from Azure, with its message
+	azureError := func(statusCode int, code, message string) error {
+		body := fmt.Sprintf(`{"error":{"code":%q,"message":%q}}`, code, message)
+		return runtime.NewResponseError(&http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(body))})
+	}
+	tests := []struct {
+		err  error
+		want Failure
+	}{
+		{azureError(http.StatusConflict, "OperationNotAllowed", "Operation could not be completed as it results in exceeding approved standardDSv2Family Cores quota."), FailureQuota},
+		{azureError(http.StatusConflict, "OperationNotAllowed", "The server is not in a state to allow this operation."), FailureUnknown},
+		{responseError(http.StatusBadRequest, "CoresQuotaExceededForRegion"), FailureQuota},
+		{responseError(http.StatusConflict, "SkuNotAvailable"), FailureSKUNotAvailable},
+		{responseError(http.StatusConflict, "VaultAlreadyExists"), FailureNameConflict},
+		{responseError(http.StatusBadRequest, "ApiManagementServiceAlreadyExists"), FailureNameConflict},
+		{responseError(http.StatusConflict, "Conflict"), FailureUnknown},
+		{responseError(http.StatusConflict, ""), FailureUnknown},
+		{responseError(http.StatusForbidden, "AuthorizationFailed"), FailureAuthorization},
+		{responseError(http.StatusUnauthorized, ""), FailureAuthorization},
+		{responseError(http.StatusConflict, "MissingSubscriptionRegistration"), FailureProviderNotRegistered},
+		{responseError(http.StatusConflict, "AnotherOperationInProgress"), FailureTransient},
+		{responseError(http.StatusServiceUnavailable, ""), FailureTransient},
+		{fmt.Errorf("wait: %w", context.DeadlineExceeded), FailureTransient},
+		{responseError(http.StatusBadRequest, "InvalidParameter"), FailureUnknown},
+		{errors.New("nil pointer dereference"), FailureUnknown},
+		{configError(errors.New("invalid value for flag -parallel")), FailureConfig},
+		// a failed step of a graph, with the steps it canceled
+		{joinErrors([]error{context.Canceled, responseError(http.StatusConflict, "QuotaExceeded")}), FailureQuota},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFailureExitCodes(t *testing.T) {
+	seen := map[int]Failure{}
+	for f := FailureUnknown; f <= FailureConfig; f++ {
+		code := f.ExitCode()
+		// a Go program exits with 2 when it panics or hits a fatal error
+		if code == 0 || code == CrashExitCode {
+			t.Errorf("%s exits with %d", f, code)
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("%s and %s both exit with %d", f, other, code)
+		}
+		seen[code] = f
+		if got := ExitFailure(code); got != f {
+			t.Errorf("ExitFailure(%d) = %s, want %s", code, got, f)
+		}
+		if f != FailureUnknown && f.Advice() == "" {
+			t.Errorf("%s has no advice", f)
+		}
+	}
+	if got := ExitFailure(CrashExitCode); got != FailureUnknown {
+		t.Errorf("ExitFailure(%d) = %s, want %s", CrashExitCode, got, FailureUnknown)
+	}
+}
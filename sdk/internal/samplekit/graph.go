@@ -0,0 +1,236 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// DefaultParallel is how many steps of a Graph, or deletions of a Teardown,
+// run at the same time unless set otherwise.
+const DefaultParallel = 4
+
+// parallel is the number of steps a Graph runs at the same time when its
+// Parallel is zero, set by Main from -parallel; zero for DefaultParallel.
+var parallel int
+
+// GraphStep is a step of a Graph.
+type GraphStep struct {
+	Name string
+	// DependsOn names the steps that must succeed before this one starts.
+	DependsOn []string
+	// Create does the step's work, such as creating a resource.
+	Create func(ctx context.Context) error
+	// Delete, if set, deletes what Create created. It is recorded in the
+	// sample's Teardown once Create succeeds, to run after the deletions of
+	// the steps depending on this one and before those of its dependencies.
+	Delete func(ctx context.Context) error
+}
+
+// Graph runs the steps of a sample that do not depend on each other at the
+// same time, each step starting once the steps it depends on succeeded.
+type Graph struct {
+	// Parallel bounds how many steps run at the same time; zero for the
+	// -parallel flag, DefaultParallel by default.
+	Parallel int
+
+	steps []GraphStep
+}
+
+// Add adds step to the graph. Its dependencies can be added after it.
+func (g *Graph) Add(step GraphStep) {
+	g.steps = append(g.steps, step)
+}
+
+// Run runs the steps of the graph for the sample s, each as a Step. The first
+// step to fail cancels the context of the steps running and no other step
+// starts; Run returns its error, with those of other steps that failed for
+// other reasons than the cancellation.
+func (g *Graph) Run(ctx context.Context, s *Sample) error {
+	deps, err := g.dependencies()
+	if err != nil {
+		return err
+	}
+	limit := g.Parallel
+	if limit == 0 {
+		limit = parallel
+	}
+	if limit <= 0 {
+		limit = DefaultParallel
+	}
+
+	// every step is deleted before the resources recorded before the graph ran
+	before := s.Teardown.indexes()
+	// teardown holds the index in Teardown of the deletion of each step, -1 for none
+	teardown := make([]int, len(g.steps))
+	for i := range teardown {
+		teardown[i] = -1
+	}
+	errs := runGraph(ctx, deps, limit, true, func(ctx context.Context, i int) error {
+		step := g.steps[i]
+		_, err := Step(ctx, s, step.Name, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, step.Create(ctx)
+		})
+		if err != nil || step.Delete == nil {
+			return err
+		}
+		// the dependencies ran before, so their teardown indexes are set
+		after := append([]int(nil), before...)
+		for _, dep := range deletedDependencies(deps, teardown, i) {
+			after = append(after, teardown[dep])
+		}
+		teardown[i] = s.Teardown.add(step.Name, step.Delete, after)
+		return nil
+	})
+
+	var failed, canceled []error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled) && ctx.Err() == nil:
+			canceled = append(canceled, err)
+		default:
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return joinErrors(canceled)
+	}
+	return joinErrors(failed)
+}
+
+// dependencies returns the indexes of the steps each step depends on, or an
+// error if a step depends on an unknown step or the steps depend on each
+// other in a cycle.
+func (g *Graph) dependencies() ([][]int, error) {
+	index := make(map[string]int, len(g.steps))
+	for i, step := range g.steps {
+		if _, ok := index[step.Name]; ok {
+			return nil, fmt.Errorf("graph: two steps are called %s", step.Name)
+		}
+		index[step.Name] = i
+	}
+	deps := make([][]int, len(g.steps))
+	for i, step := range g.steps {
+		for _, name := range step.DependsOn {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("graph: %s depends on unknown step %s", step.Name, name)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	// the steps left after removing those whose dependencies can all run first are in a cycle
+	pending := make([]int, len(deps))
+	dependents := make([][]int, len(deps))
+	var ready []int
+	for i := range deps {
+		pending[i] = len(deps[i])
+		for _, j := range deps[i] {
+			dependents[j] = append(dependents[j], i)
+		}
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		for _, j := range dependents[i] {
+			if pending[j]--; pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	for i, n := range pending {
+		if n > 0 {
+			return nil, fmt.Errorf("graph: %s is in a dependency cycle", g.steps[i].Name)
+		}
+	}
+	return deps, nil
+}
+
+// deletedDependencies returns the closest dependencies of step i that
+// recorded a deletion, looking through those that did not.
+func deletedDependencies(deps [][]int, teardown []int, i int) []int {
+	var found []int
+	seen := map[int]bool{}
+	var visit func(i int)
+	visit = func(i int) {
+		for _, dep := range deps[i] {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			if teardown[dep] >= 0 {
+				found = append(found, dep)
+			} else {
+				visit(dep)
+			}
+		}
+	}
+	visit(i)
+	return found
+}
+
+// runGraph calls fn for every node i of a graph, once the nodes in waits[i]
+// returned, with at most limit calls running at the same time. With
+// stopOnError, the first error cancels the context of the running calls and
+// no other call starts. A call that panics returns the panic as its error. It
+// returns the error of every call, nil for the nodes not called.
+func runGraph(ctx context.Context, waits [][]int, limit int, stopOnError bool, fn func(ctx context.Context, i int) error) []error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(waits))
+	pending := make([]int, len(waits))
+	waiting := make([][]int, len(waits))
+	var ready []int
+	for i, w := range waits {
+		pending[i] = len(w)
+		for _, j := range w {
+			waiting[j] = append(waiting[j], i)
+		}
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	done := make(chan int)
+	running, stopped := 0, false
+	for {
+		for !stopped && len(ready) > 0 && running < limit {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func(i int) {
+				defer func() {
+					if r := recover(); r != nil {
+						errs[i] = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+					}
+					done <- i
+				}()
+				errs[i] = fn(ctx, i)
+			}(i)
+		}
+		if running == 0 {
+			return errs
+		}
+		i := <-done
+		running--
+		if errs[i] != nil && stopOnError && !stopped {
+			stopped = true
+			cancel()
+		}
+		for _, j := range waiting[i] {
+			if pending[j]--; pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the steps of a graph as they start and the resources as
+// they are deleted.
+type recorder struct {
+	mu      sync.Mutex
+	started []string
+	deleted []string
+}
+
+func (r *recorder) step(name string, deps ...string) GraphStep {
+	return GraphStep{
+		Name:      name,
+		DependsOn: deps,
+		Create: func(ctx context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.started = append(r.started, name)
+			return nil
+		},
+		Delete: func(ctx context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.deleted = append(r.deleted, name)
+			return nil
+		},
+	}
+}
+
+func index(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestGraphRunsInDependencyOrder(t *testing.T) {
+	var r recorder
+	var g Graph
+	g.Add(r.step("nic", "subnet", "publicIP", "nsg"))
+	g.Add(r.step("vm", "nic"))
+	g.Add(r.step("vnet"))
+	g.Add(r.step("subnet", "vnet"))
+	g.Add(r.step("publicIP"))
+	g.Add(r.step("nsg"))
+
+	sample := &Sample{}
+	sample.Teardown.Add("resource group", r.step("resource group").Delete)
+	if err := g.Run(context.Background(), sample); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.started) != 6 {
+		t.Fatalf("started %v, want 6 steps", r.started)
+	}
+	for _, edge := range [][2]string{{"vnet", "subnet"}, {"subnet", "nic"}, {"publicIP", "nic"}, {"nsg", "nic"}, {"nic", "vm"}} {
+		if index(r.started, edge[0]) > index(r.started, edge[1]) {
+			t.Errorf("%s started before %s: %v", edge[1], edge[0], r.started)
+		}
+	}
+
+	if err := sample.Teardown.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.deleted) != 7 {
+		t.Fatalf("deleted %v, want 7 resources", r.deleted)
+	}
+	for _, edge := range [][2]string{{"subnet", "vnet"}, {"nic", "subnet"}, {"nic", "publicIP"}, {"nic", "nsg"}, {"vm", "nic"}, {"vnet", "resource group"}} {
+		if index(r.deleted, edge[0]) > index(r.deleted, edge[1]) {
+			t.Errorf("%s deleted before %s: %v", edge[1], edge[0], r.deleted)
+		}
+	}
+}
+
+func TestGraphBoundsParallelSteps(t *testing.T) {
+	var mu sync.Mutex
+	running, most := 0, 0
+	g := Graph{Parallel: 2}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		g.Add(GraphStep{Name: name, Create: func(ctx context.Context) error {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		}})
+	}
+	if err := g.Run(context.Background(), &Sample{}); err != nil {
+		t.Fatal(err)
+	}
+	if most != 2 {
+		t.Errorf("%d steps ran at the same time, want 2", most)
+	}
+}
+
+func TestGraphErrorCancelsSteps(t *testing.T) {
+	var r recorder
+	var g Graph
+	g.Add(GraphStep{Name: "server", Create: func(ctx context.Context) error {
+		return errors.New("quota exceeded")
+	}})
+	partner := r.step("partner server")
+	partner.Create = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	g.Add(partner)
+	g.Add(r.step("failover group", "server", "partner server"))
+
+	sample := &Sample{}
+	err := g.Run(context.Background(), sample)
+	if err == nil || err.Error() != "quota exceeded" {
+		t.Fatalf("got error %v, want quota exceeded", err)
+	}
+	if len(r.started) != 0 {
+		t.Errorf("started %v after the error", r.started)
+	}
+	if sample.Teardown.Len() != 0 {
+		t.Errorf("%d deletions recorded for failed steps", sample.Teardown.Len())
+	}
+}
+
+func TestGraphPanicStopsSteps(t *testing.T) {
+	var r recorder
+	var g Graph
+	g.Add(r.step("vnet"))
+	g.Add(GraphStep{Name: "subnet", DependsOn: []string{"vnet"}, Create: func(ctx context.Context) error {
+		panic("nil subnet properties")
+	}})
+	g.Add(r.step("nic", "subnet"))
+
+	sample := &Sample{}
+	sample.Teardown.Add("resource group", r.step("resource group").Delete)
+	err := g.Run(context.Background(), sample)
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: nil subnet properties") {
+		t.Fatalf("got error %v, want the panic", err)
+	}
+	if !reflect.DeepEqual(r.started, []string{"vnet"}) {
+		t.Errorf("started %v, want [vnet]", r.started)
+	}
+	if err := sample.Teardown.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vnet", "resource group"}; !reflect.DeepEqual(r.deleted, want) {
+		t.Errorf("deleted %v, want %v", r.deleted, want)
+	}
+}
+
+func TestGraphInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []GraphStep
+		wantErr string
+	}{
+		{
+			name:    "unknown",
+			steps:   []GraphStep{{Name: "nic", DependsOn: []string{"subnet"}}},
+			wantErr: "nic depends on unknown step subnet",
+		},
+		{
+			name:    "cycle",
+			steps:   []GraphStep{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}},
+			wantErr: "a is in a dependency cycle",
+		},
+		{
+			name:    "duplicate",
+			steps:   []GraphStep{{Name: "a"}, {Name: "a"}},
+			wantErr: "two steps are called a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Graph
+			for _, step := range tt.steps {
+				g.Add(step)
+			}
+			err := g.Run(context.Background(), &Sample{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTeardownDeletesIndependentResourcesTogether(t *testing.T) {
+	var td Teardown
+	// the two deletions only return once both started
+	var started sync.WaitGroup
+	started.Add(2)
+	wait := func(ctx context.Context) error {
+		started.Done()
+		started.Wait()
+		return nil
+	}
+	vnet := td.add("vnet", wait, nil)
+	td.add("public IP", wait, nil)
+	var deleted []string
+	td.add("subnet", func(ctx context.Context) error {
+		deleted = append(deleted, "subnet")
+		return nil
+	}, []int{vnet})
+
+	done := make(chan error)
+	go func() { done <- td.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the independent deletions did not run at the same time")
+	}
+	if !reflect.DeepEqual(deleted, []string{"subnet"}) {
+		t.Errorf("deleted %v", deleted)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NameSeedEnv names the environment variable holding the seed of the random
+// suffix added to unique names. The -name-seed flag takes precedence over it.
+// Set it to the seed a previous run logged to get the same names again.
+const NameSeedEnv = "SAMPLE_NAME_SEED"
+
+// suffixLength is the length of the random suffix added to unique names.
+const suffixLength = 6
+
+const (
+	lowerAlphanumerics = "abcdefghijklmnopqrstuvwxyz0123456789"
+	alphanumerics      = lowerAlphanumerics + "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// NameRule describes the names Azure accepts for a resource type.
+type NameRule struct {
+	MinLength int
+	MaxLength int
+	// Chars holds every character allowed in the name.
+	Chars string
+	// StartWithLetter is set when the name must start with a letter.
+	StartWithLetter bool
+}
+
+// Rules for resource types whose names must be globally unique, because they
+// become part of a DNS name.
+var (
+	StorageAccount       = NameRule{MinLength: 3, MaxLength: 24, Chars: lowerAlphanumerics}
+	KeyVault             = NameRule{MinLength: 3, MaxLength: 24, Chars: alphanumerics + "-", StartWithLetter: true}
+	SQLServer            = NameRule{MinLength: 1, MaxLength: 63, Chars: lowerAlphanumerics + "-"}
+	MySQLServer          = NameRule{MinLength: 3, MaxLength: 63, Chars: lowerAlphanumerics + "-"}
+	PostgreSQLServer     = NameRule{MinLength: 3, MaxLength: 63, Chars: lowerAlphanumerics + "-"}
+	CosmosDBAccount      = NameRule{MinLength: 3, MaxLength: 44, Chars: lowerAlphanumerics + "-"}
+	ContainerRegistry    = NameRule{MinLength: 5, MaxLength: 50, Chars: alphanumerics}
+	APIManagement        = NameRule{MinLength: 1, MaxLength: 50, Chars: alphanumerics + "-", StartWithLetter: true}
+	AppService           = NameRule{MinLength: 2, MaxLength: 60, Chars: alphanumerics + "-"}
+	SpringApps           = NameRule{MinLength: 4, MaxLength: 32, Chars: lowerAlphanumerics + "-", StartWithLetter: true}
+	EventHubNamespace    = NameRule{MinLength: 6, MaxLength: 50, Chars: alphanumerics + "-", StartWithLetter: true}
+	ServiceBusNamespace  = NameRule{MinLength: 6, MaxLength: 50, Chars: alphanumerics + "-", StartWithLetter: true}
+	RedisCache           = NameRule{MinLength: 1, MaxLength: 63, Chars: alphanumerics + "-"}
+	DataFactory          = NameRule{MinLength: 3, MaxLength: 63, Chars: alphanumerics + "-"}
+	DataLakeStoreAccount = NameRule{MinLength: 3, MaxLength: 24, Chars: lowerAlphanumerics}
+	IoTHub               = NameRule{MinLength: 3, MaxLength: 50, Chars: alphanumerics + "-"}
+)
+
+// Validate returns an error describing why name does not follow the rule.
+func (r NameRule) Validate(name string) error {
+	if len(name) < r.MinLength || len(name) > r.MaxLength {
+		return fmt.Errorf("name %q must be %d to %d characters long", name, r.MinLength, r.MaxLength)
+	}
+	for _, c := range name {
+		if !strings.ContainsRune(r.Chars, c) {
+			return fmt.Errorf("name %q must not contain %q", name, c)
+		}
+	}
+	if r.StartWithLetter && !unicode.IsLetter(rune(name[0])) {
+		return fmt.Errorf("name %q must start with a letter", name)
+	}
+	if strings.HasSuffix(name, "-") {
+		return fmt.Errorf("name %q must not end with a hyphen", name)
+	}
+	return nil
+}
+
+// Unique returns base with suffix appended, dropping the characters of base the
+// rule does not allow and shortening base so the result fits the rule.
+func (r NameRule) Unique(base, suffix string) string {
+	if !strings.ContainsAny(r.Chars, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		base = strings.ToLower(base)
+	}
+	var b strings.Builder
+	for _, c := range base {
+		if strings.ContainsRune(r.Chars, c) {
+			b.WriteRune(c)
+		}
+	}
+	base = strings.TrimLeft(b.String(), "-")
+	if r.StartWithLetter && (base == "" || !unicode.IsLetter(rune(base[0]))) {
+		base = "s" + base
+	}
+
+	sep := ""
+	if strings.ContainsRune(r.Chars, '-') {
+		sep = "-"
+	}
+	if max := r.MaxLength - len(sep) - len(suffix); len(base) > max {
+		base = base[:max]
+	}
+	return strings.TrimRight(base, "-") + sep + suffix
+}
+
+// RandomSuffix returns the suffix of unique names for the seed.
+func RandomSuffix(seed int64) string {
+	rnd := rand.New(rand.NewSource(seed))
+	suffix := make([]byte, suffixLength)
+	for i := range suffix {
+		suffix[i] = lowerAlphanumerics[rnd.Intn(len(lowerAlphanumerics))]
+	}
+	return string(suffix)
+}
+
+var (
+	uniqueMu    sync.Mutex
+	uniqueNames = map[*string]NameRule{}
+)
+
+// Unique marks the setting p as a globally unique name. Unless the setting is
+// overridden, Settings.Load appends the run's random suffix to it following rule.
+// It returns p, so it can be used in a Settings literal:
+//
+//	samplekit.Settings{
+//		"storageAccountName": samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+//	}
+func Unique(p *string, rule NameRule) *string {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+	uniqueNames[p] = rule
+	return p
+}
+
+func uniqueRule(p *string) (NameRule, bool) {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+	rule, ok := uniqueNames[p]
+	return rule, ok
+}
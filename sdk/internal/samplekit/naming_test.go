@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNameRuleUnique(t *testing.T) {
+	tests := []struct {
+		rule NameRule
+		base string
+		want string
+	}{
+		{StorageAccount, "sample2storage2account", "sample2storage2accabc123"},
+		{StorageAccount, "Sample-Storage", "samplestorageabc123"},
+		{KeyVault, "sample2vaultalan", "sample2vaultalan-abc123"},
+		{KeyVault, "2vault", "s2vault-abc123"},
+		{SQLServer, "-sample-sql-server", "sample-sql-server-abc123"},
+		{ContainerRegistry, "sample-registry", "sampleregistryabc123"},
+		{CosmosDBAccount, strings.Repeat("sample-", 10), "sample-sample-sample-sample-sample-sa-abc123"},
+	}
+	for _, tt := range tests {
+		got := tt.rule.Unique(tt.base, "abc123")
+		if got != tt.want {
+			t.Errorf("Unique(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+		if err := tt.rule.Validate(got); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNameRuleValidate(t *testing.T) {
+	for _, name := range []string{"ab", "UPPERCASE", "has-hyphen", strings.Repeat("a", 25)} {
+		if err := StorageAccount.Validate(name); err == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestRandomSuffix(t *testing.T) {
+	if RandomSuffix(42) != RandomSuffix(42) {
+		t.Fatal("the same seed must give the same suffix")
+	}
+	if RandomSuffix(42) == RandomSuffix(43) {
+		t.Fatal("different seeds should give different suffixes")
+	}
+	if err := StorageAccount.Validate(RandomSuffix(1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSettingsUnique(t *testing.T) {
+	t.Setenv(NameSeedEnv, "42")
+	t.Setenv("SAMPLE_VAULT_NAME", "my-vault")
+
+	storageAccountName := "sample2storage"
+	vaultName := "sample-vault"
+	resourceGroupName := "sample-resource-group"
+	settings := Settings{
+		"storageAccountName": Unique(&storageAccountName, StorageAccount),
+		"vaultName":          Unique(&vaultName, KeyVault),
+		"resourceGroupName":  &resourceGroupName,
+	}
+	if err := settings.Load(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "sample2storage" + RandomSuffix(42); storageAccountName != want {
+		t.Errorf("storageAccountName = %q, want %q", storageAccountName, want)
+	}
+	if vaultName != "my-vault" {
+		t.Errorf("an overridden unique name must be kept as is, got %q", vaultName)
+	}
+	if resourceGroupName != "sample-resource-group" {
+		t.Errorf("resourceGroupName = %q, want it unchanged", resourceGroupName)
+	}
+
+	// replaying the seed gives the same names
+	replayed := "sample2storage"
+	if err := (Settings{"storageAccountName": Unique(&replayed, StorageAccount)}).Load([]string{"-name-seed", "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if replayed != storageAccountName {
+		t.Errorf("replayed name %q, want %q", replayed, storageAccountName)
+	}
+}
+
+func TestSettingsResume(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	state := NewState(stateFile)
+	state.NameSeed = "42"
+	if err := state.SetResumeToken("operation", "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a resumed run uses the names of the saved run
+	storageAccountName := "sample2storage"
+	settings := Settings{"storageAccountName": Unique(&storageAccountName, StorageAccount)}
+	if err := settings.Load([]string{"-resume", "-state", stateFile}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "sample2storage" + RandomSuffix(42); storageAccountName != want {
+		t.Errorf("storageAccountName = %q, want %q", storageAccountName, want)
+	}
+
+	if err := settings.Load([]string{"-resume", "-state", filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected an error for a missing state file")
+	}
+	if err := settings.Load([]string{"-resume", "-state", stateFile, "-dry-run"}); err == nil {
+		t.Error("expected an error for resuming a dry run")
+	}
+}
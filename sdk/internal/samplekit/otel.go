@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"github.com/Azure/azure-sdk-for-go/sdk/tracing/azotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TraceEnv names the environment variable choosing where to export the
+// OpenTelemetry spans of a run: "stdout" or "otlp". The -trace flag takes
+// precedence over it.
+const TraceEnv = "SAMPLE_TRACE"
+
+// tracerName names the tracer of the spans samplekit starts itself.
+const tracerName = "github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+
+// OTLPPackage is the package registering the "otlp" trace exporter. It is
+// kept apart so the OTLP exporter and its dependencies are only built into
+// the samples that import it.
+const OTLPPackage = "github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit/otlp"
+
+// TraceExporter makes an exporter of the spans of a run; out is where an
+// exporter writing the spans as text writes them.
+type TraceExporter func(ctx context.Context, out io.Writer) (sdktrace.SpanExporter, error)
+
+// traceExporters holds the exporters NewTraceExporter knows by name.
+var traceExporters = map[string]TraceExporter{
+	"stdout": func(ctx context.Context, out io.Writer) (sdktrace.SpanExporter, error) {
+		return stdouttrace.New(stdouttrace.WithWriter(out), stdouttrace.WithPrettyPrint())
+	},
+}
+
+// RegisterTraceExporter makes the exporter called name available to
+// NewTraceExporter and the -trace flag. It is meant to be called from the
+// init function of a package, as OTLPPackage registers "otlp".
+func RegisterTraceExporter(name string, exporter TraceExporter) {
+	traceExporters[name] = exporter
+}
+
+// NewTraceExporter returns the exporter called name: "stdout" writes the spans
+// as JSON to out, and "otlp", once OTLPPackage is imported, sends them with
+// OTLP over HTTP.
+func NewTraceExporter(ctx context.Context, name string, out io.Writer) (sdktrace.SpanExporter, error) {
+	exporter, ok := traceExporters[name]
+	switch {
+	case ok:
+		return exporter(ctx, out)
+	case name == "otlp":
+		return nil, fmt.Errorf("the otlp trace exporter is not built into this sample; import _ %q in it", OTLPPackage)
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q; use stdout or otlp", name)
+}
+
+// StartTracing makes the sample record OpenTelemetry spans and export them
+// with exporter: a span for the run, one for each Step and one for the
+// cleanup, with the spans of the SDK operations and their HTTP requests as
+// their children. Call the returned function at the end of the run to export
+// the spans left.
+func (s *Sample) StartTracing(exporter sdktrace.SpanExporter) (shutdown func(context.Context) error) {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", sampleName()))),
+	)
+	s.ClientOptions.TracingProvider = azotel.NewTracingProvider(tp, nil)
+	s.tracer = s.ClientOptions.TracingProvider.NewTracer(tracerName, "")
+	return tp.Shutdown
+}
+
+// Step runs fn, the step of the sample called name such as
+// "createVirtualNetwork", and returns its result. When tracing, the step is a
+// span, and the SDK operations fn runs are its children. The requests fn makes
+// are stamped with name, see StampRequests.
+func Step[T any](ctx context.Context, s *Sample, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := s.tracer.Start(withStepName(ctx, name), name, nil)
+	defer span.End()
+	v, err := fn(ctx)
+	if err != nil {
+		// the description is formatted like the SDK's
+		span.SetStatus(tracing.SpanStatusError, fmt.Sprintf("%T\n%s", err, err))
+	}
+	return v, err
+}
+
+// sampleName returns the module path of the running sample.
+func sampleName() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		return info.Main.Path
+	}
+	return "sample"
+}
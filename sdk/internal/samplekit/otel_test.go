@@ -0,0 +1,99 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartTracing(t *testing.T) {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{ID: to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name)},
+			}, nil)
+			return
+		},
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	})
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown := sample.StartTracing(keepSpans{exporter})
+	// the report still gets the SDK operations only
+	reporter := sample.StartReport()
+
+	err := sample.Run(context.Background(), func(ctx context.Context, s *Sample) error {
+		_, err := Step(ctx, s, "createResourceGroup", s.CreateResourceGroup)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]string{}
+	parents := map[string]string{}
+	for _, span := range exporter.GetSpans() {
+		names[span.SpanContext.SpanID().String()] = span.Name
+	}
+	for _, span := range exporter.GetSpans() {
+		parents[span.Name] = names[span.Parent.SpanID().String()]
+	}
+	want := map[string]string{
+		sampleName():                          "",
+		"createResourceGroup":                 sampleName(),
+		"ResourceGroupsClient.CreateOrUpdate": "createResourceGroup",
+		"HTTP PUT":                            "ResourceGroupsClient.CreateOrUpdate",
+		"cleanup":                             sampleName(),
+		"ResourceGroupsClient.BeginDelete":    "cleanup",
+		"HTTP DELETE":                         "ResourceGroupsClient.BeginDelete",
+	}
+	for name, parent := range want {
+		if got, ok := parents[name]; !ok {
+			t.Errorf("no span %q in %v", name, parents)
+		} else if got != parent {
+			t.Errorf("span %q has parent %q, want %q", name, got, parent)
+		}
+	}
+
+	if report := reporter.Report(nil); len(report.Steps) != 2 {
+		t.Errorf("got %d steps in the report, want the 2 SDK operations", len(report.Steps))
+	}
+}
+
+func TestNewTraceExporter(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewTraceExporter(ctx, "stdout", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	// the OTLP exporter is only there once its package is imported
+	if _, err := NewTraceExporter(ctx, "otlp", io.Discard); err == nil || !strings.Contains(err.Error(), OTLPPackage) {
+		t.Errorf("got error %v for otlp", err)
+	}
+	if _, err := NewTraceExporter(ctx, "jaeger", io.Discard); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
+
+// keepSpans keeps the spans of an InMemoryExporter, which forgets them on
+// Shutdown.
+type keepSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepSpans) Shutdown(context.Context) error { return nil }
@@ -0,0 +1,37 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package otlp registers the "otlp" trace exporter of samplekit, which sends
+// the spans of a run with OTLP over HTTP. Import it for its side effect in a
+// sample whose spans should go to a collector:
+//
+//	import _ "github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit/otlp"
+//
+// It is a module of its own so the OTLP exporter and its gRPC and protobuf
+// dependencies stay out of the samples that do not import it.
+package otlp
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func init() {
+	samplekit.RegisterTraceExporter("otlp", NewExporter)
+}
+
+// NewExporter returns an OTLP over HTTP exporter. It reads the standard
+// OTEL_EXPORTER_OTLP_* environment variables, and sends to a local collector
+// at localhost:4318 without TLS when no endpoint is set.
+func NewExporter(ctx context.Context, _ io.Writer) (sdktrace.SpanExporter, error) {
+	var opts []otlptracehttp.Option
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
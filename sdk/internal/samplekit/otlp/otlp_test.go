@@ -0,0 +1,22 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package otlp
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+)
+
+func TestRegister(t *testing.T) {
+	exporter, err := samplekit.NewTraceExporter(context.Background(), "otlp", io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
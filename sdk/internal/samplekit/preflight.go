@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// RegisterProvidersEnv names the environment variable that, when set to
+// anything other than empty, makes Preflight register the providers a sample
+// needs. The -register-providers flag takes precedence over it.
+const RegisterProvidersEnv = "SAMPLE_REGISTER_PROVIDERS"
+
+// Needs declares what a sample needs from its subscription and location, for
+// Preflight to check before the sample creates anything.
+type Needs struct {
+	// Providers are the namespaces of the resource providers the sample
+	// uses, such as Microsoft.Compute.
+	Providers []string
+	// VMSizes holds the size of every virtual machine the sample creates,
+	// such as Standard_F2s, once per machine. Only virtual machine sizes are
+	// checked; the SKUs of other resources, such as databases or caches, are
+	// not.
+	VMSizes []string
+}
+
+// registrationPollInterval is how often Preflight checks whether a provider
+// it registers is registered yet.
+var registrationPollInterval = 10 * time.Second
+
+// Preflight checks that the sample's subscription and location have what the
+// sample needs, so it fails before creating anything rather than deep into
+// the run. It logs the providers that are not registered, or registers them
+// if RegisterProviders is set, and returns an error classified as
+// FailureSKUNotAvailable or FailureQuota if a VM size is not offered to the
+// subscription in the location, or its vCPUs are out of quota. Offline, it
+// checks nothing.
+func (s *Sample) Preflight(ctx context.Context, needs Needs) error {
+	if offline {
+		return nil
+	}
+	for _, namespace := range needs.Providers {
+		if err := s.checkProvider(ctx, namespace); err != nil {
+			return err
+		}
+	}
+	if len(needs.VMSizes) > 0 {
+		if err := s.checkVMSizes(ctx, needs.VMSizes); err != nil {
+			return err
+		}
+	}
+	log.Printf("preflight checks passed in %s.", s.Location)
+	return nil
+}
+
+// checkProvider checks that the provider namespace is registered in the
+// subscription. If it is not, it registers it and waits for the registration
+// to finish when RegisterProviders is set, and logs it otherwise: registering
+// a provider changes the subscription, and Azure registers many providers on
+// their first use anyway.
+func (s *Sample) checkProvider(ctx context.Context, namespace string) error {
+	client, err := armresources.NewProvidersClient(s.SubscriptionID, s.Credential, s.ClientOptions)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Get(ctx, namespace, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get provider %s: %w", namespace, err)
+	}
+	if registrationState(resp.Provider) == "Registered" {
+		return nil
+	}
+	if !s.RegisterProviders {
+		log.Printf("provider %s is not registered in the subscription; register it with az provider register --namespace %s, or run with -register-providers.", namespace, namespace)
+		return nil
+	}
+
+	log.Printf("registering provider %s...", namespace)
+	if _, err := client.Register(ctx, namespace, nil); err != nil {
+		return fmt.Errorf("cannot register provider %s: %w", namespace, err)
+	}
+	for {
+		resp, err := client.Get(ctx, namespace, nil)
+		if err != nil {
+			return fmt.Errorf("cannot get provider %s: %w", namespace, err)
+		}
+		if registrationState(resp.Provider) == "Registered" {
+			log.Printf("registered provider %s.", namespace)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("provider %s is not registered yet: %w", namespace, ctx.Err())
+		case <-time.After(registrationPollInterval):
+		}
+	}
+}
+
+func registrationState(p armresources.Provider) string {
+	if p.RegistrationState == nil {
+		return ""
+	}
+	return *p.RegistrationState
+}
+
+// checkVMSizes checks that every size is offered to the subscription in the
+// sample's location, and that the quota of the location has room for the
+// vCPUs of all the machines, in total and in each VM family.
+func (s *Sample) checkVMSizes(ctx context.Context, sizes []string) error {
+	skus, err := s.vmSKUs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the vCPUs needed by usage name: cores in total, and per family
+	need := map[string]int64{}
+	for _, size := range sizes {
+		sku, ok := skus[strings.ToLower(size)]
+		if !ok {
+			return failureError{FailureSKUNotAvailable, fmt.Sprintf("VM size %s is not offered in %s", size, s.Location)}
+		}
+		if reason := restriction(sku, s.Location); reason != "" {
+			return failureError{FailureSKUNotAvailable, fmt.Sprintf("VM size %s is restricted in %s: %s", size, s.Location, reason)}
+		}
+		vCPUs := capability(sku, "vCPUs")
+		need["cores"] += vCPUs
+		if sku.Family != nil {
+			need[*sku.Family] += vCPUs
+		}
+	}
+
+	client, err := armcompute.NewUsageClient(s.SubscriptionID, s.Credential, s.ClientOptions)
+	if err != nil {
+		return err
+	}
+	pager := client.NewListPager(s.Location, nil)
+	var short []string
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot list the compute usages in %s: %w", s.Location, err)
+		}
+		for _, usage := range page.Value {
+			if usage.Name == nil || usage.Name.Value == nil || usage.Limit == nil || usage.CurrentValue == nil {
+				continue
+			}
+			n, ok := need[*usage.Name.Value]
+			if !ok {
+				continue
+			}
+			if free := *usage.Limit - int64(*usage.CurrentValue); free < n {
+				short = append(short, fmt.Sprintf("%s needs %d, %d of %d free", *usage.Name.Value, n, free, *usage.Limit))
+			}
+		}
+	}
+	if len(short) > 0 {
+		sort.Strings(short)
+		return failureError{FailureQuota, fmt.Sprintf("not enough vCPU quota in %s: %s", s.Location, strings.Join(short, "; "))}
+	}
+	return nil
+}
+
+// vmSKUs returns the virtual machine SKUs of the sample's location by lower
+// case name.
+func (s *Sample) vmSKUs(ctx context.Context) (map[string]*armcompute.ResourceSKU, error) {
+	client, err := armcompute.NewResourceSKUsClient(s.SubscriptionID, s.Credential, s.ClientOptions)
+	if err != nil {
+		return nil, err
+	}
+	filter := fmt.Sprintf("location eq '%s'", s.Location)
+	pager := client.NewListPager(&armcompute.ResourceSKUsClientListOptions{Filter: &filter})
+	skus := map[string]*armcompute.ResourceSKU{}
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the resource SKUs in %s: %w", s.Location, err)
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType != nil && *sku.ResourceType == "virtualMachines" && sku.Name != nil {
+				skus[strings.ToLower(*sku.Name)] = sku
+			}
+		}
+	}
+	return skus, nil
+}
+
+// restriction returns the reason sku cannot be used anywhere in location, or
+// "" if it can be. Restrictions to some zones leave the others usable.
+func restriction(sku *armcompute.ResourceSKU, location string) string {
+	for _, r := range sku.Restrictions {
+		if r.Type == nil || *r.Type != armcompute.ResourceSKURestrictionsTypeLocation {
+			continue
+		}
+		for _, v := range r.Values {
+			if v != nil && strings.EqualFold(*v, location) {
+				if r.ReasonCode != nil {
+					return string(*r.ReasonCode)
+				}
+				return "restricted"
+			}
+		}
+	}
+	return ""
+}
+
+// capability returns the value of the capability name of sku, 0 if it has none.
+func capability(sku *armcompute.ResourceSKU, name string) int64 {
+	for _, c := range sku.Capabilities {
+		if c.Name != nil && *c.Name == name && c.Value != nil {
+			n, _ := strconv.ParseInt(*c.Value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// failureError is an error whose category does not come from an Azure error
+// response, such as a failed pre-flight check.
+type failureError struct {
+	failure Failure
+	msg     string
+}
+
+func (e failureError) Error() string { return e.msg }
+
+// Failure returns the category of the error, for Classify.
+func (e failureError) Failure() Failure { return e.failure }
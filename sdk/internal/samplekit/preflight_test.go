@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	computefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+// routeTransport sends the requests for SKUs and usages to compute and the
+// others, for providers, to resources.
+type routeTransport struct {
+	compute, resources policy.Transporter
+}
+
+func (t routeTransport) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/skus") || strings.HasSuffix(req.URL.Path, "/usages") {
+		return t.compute.Do(req)
+	}
+	return t.resources.Do(req)
+}
+
+// newPreflightSample returns a Sample in westus whose provider Microsoft.Compute
+// is in state, offering the VM sizes Standard_F2s, 2 vCPUs of the family
+// standardFSFamily, and Standard_M8ms, restricted, with usedCores of its 14
+// cores used. registered is set when the provider is registered.
+func newPreflightSample(state string, usedCores int32, registered *bool) *Sample {
+	resources := resourcesfake.ProvidersServer{
+		Get: func(ctx context.Context, namespace string, options *armresources.ProvidersClientGetOptions) (resp azfake.Responder[armresources.ProvidersClientGetResponse], errResp azfake.ErrorResponder) {
+			if *registered {
+				state = "Registered"
+			}
+			resp.SetResponse(http.StatusOK, armresources.ProvidersClientGetResponse{
+				Provider: armresources.Provider{Namespace: to.Ptr(namespace), RegistrationState: to.Ptr(state)},
+			}, nil)
+			return
+		},
+		Register: func(ctx context.Context, namespace string, options *armresources.ProvidersClientRegisterOptions) (resp azfake.Responder[armresources.ProvidersClientRegisterResponse], errResp azfake.ErrorResponder) {
+			*registered = true
+			resp.SetResponse(http.StatusOK, armresources.ProvidersClientRegisterResponse{
+				Provider: armresources.Provider{Namespace: to.Ptr(namespace), RegistrationState: to.Ptr("Registering")},
+			}, nil)
+			return
+		},
+	}
+	compute := computefake.ServerFactory{
+		ResourceSKUsServer: computefake.ResourceSKUsServer{
+			NewListPager: func(options *armcompute.ResourceSKUsClientListOptions) (resp azfake.PagerResponder[armcompute.ResourceSKUsClientListResponse]) {
+				resp.AddPage(http.StatusOK, armcompute.ResourceSKUsClientListResponse{
+					ResourceSKUsResult: armcompute.ResourceSKUsResult{Value: []*armcompute.ResourceSKU{
+						{
+							ResourceType: to.Ptr("virtualMachines"),
+							Name:         to.Ptr("Standard_F2s"),
+							Family:       to.Ptr("standardFSFamily"),
+							Capabilities: []*armcompute.ResourceSKUCapabilities{{Name: to.Ptr("vCPUs"), Value: to.Ptr("2")}},
+							Restrictions: []*armcompute.ResourceSKURestrictions{{
+								Type:   to.Ptr(armcompute.ResourceSKURestrictionsTypeZone),
+								Values: []*string{to.Ptr("westus")},
+							}},
+						},
+						{
+							ResourceType: to.Ptr("virtualMachines"),
+							Name:         to.Ptr("Standard_M8ms"),
+							Restrictions: []*armcompute.ResourceSKURestrictions{{
+								Type:       to.Ptr(armcompute.ResourceSKURestrictionsTypeLocation),
+								Values:     []*string{to.Ptr("westus")},
+								ReasonCode: to.Ptr(armcompute.ResourceSKURestrictionsReasonCodeNotAvailableForSubscription),
+							}},
+						},
+					}},
+				}, nil)
+				return
+			},
+		},
+		UsageServer: computefake.UsageServer{
+			NewListPager: func(location string, options *armcompute.UsageClientListOptions) (resp azfake.PagerResponder[armcompute.UsageClientListResponse]) {
+				resp.AddPage(http.StatusOK, armcompute.UsageClientListResponse{
+					ListUsagesResult: armcompute.ListUsagesResult{Value: []*armcompute.Usage{
+						{Name: &armcompute.UsageName{Value: to.Ptr("cores")}, CurrentValue: to.Ptr(usedCores), Limit: to.Ptr[int64](14)},
+						{Name: &armcompute.UsageName{Value: to.Ptr("standardFSFamily")}, CurrentValue: to.Ptr[int32](0), Limit: to.Ptr[int64](10)},
+					}},
+				}, nil)
+				return
+			},
+		},
+	}
+	return &Sample{
+		SubscriptionID: subscriptionID,
+		Location:       "westus",
+		Credential:     &azfake.TokenCredential{},
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: routeTransport{
+					compute:   computefake.NewServerFactoryTransport(&compute),
+					resources: resourcesfake.NewProvidersServerTransport(&resources),
+				},
+			},
+		},
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	defer func(interval time.Duration) { registrationPollInterval = interval }(registrationPollInterval)
+	registrationPollInterval = 0
+	tests := []struct {
+		name           string
+		state          string
+		usedCores      int32
+		sizes          []string
+		register       bool
+		wantRegistered bool
+		wantErr        string
+		wantFailure    Failure
+	}{
+		{name: "ready", state: "Registered", sizes: []string{"Standard_F2s", "standard_f2s"}},
+		{name: "reports", state: "NotRegistered", sizes: []string{"Standard_F2s"}},
+		{name: "registers", state: "NotRegistered", sizes: []string{"Standard_F2s"}, register: true, wantRegistered: true},
+		{name: "not offered", state: "Registered", sizes: []string{"Standard_F2"}, wantErr: "VM size Standard_F2 is not offered in westus", wantFailure: FailureSKUNotAvailable},
+		{name: "restricted", state: "Registered", sizes: []string{"Standard_M8ms"}, wantErr: "restricted in westus: NotAvailableForSubscription", wantFailure: FailureSKUNotAvailable},
+		{name: "out of quota", state: "Registered", usedCores: 12, sizes: []string{"Standard_F2s", "Standard_F2s"}, wantErr: "cores needs 4, 2 of 14 free", wantFailure: FailureQuota},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registered := false
+			sample := newPreflightSample(tt.state, tt.usedCores, &registered)
+			sample.RegisterProviders = tt.register
+			err := sample.Preflight(context.Background(), Needs{Providers: []string{"Microsoft.Compute"}, VMSizes: tt.sizes})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				if got := Classify(err); got != tt.wantFailure {
+					t.Errorf("Classify() = %s, want %s", got, tt.wantFailure)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if registered != tt.wantRegistered {
+				t.Errorf("registered = %t, want %t", registered, tt.wantRegistered)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// DefaultPollFrequency is how long Wait waits between polls when the service
+// does not say, as PollUntilDone does.
+const DefaultPollFrequency = 30 * time.Second
+
+// operationTimeout bounds each operation Wait waits for when its options do
+// not; Main sets it from -operation-timeout. Zero means no limit.
+var operationTimeout time.Duration
+
+// WaitOptions contains the optional parameters for Wait.
+type WaitOptions struct {
+	// Timeout bounds how long the operation may take. If zero, the
+	// -operation-timeout flag applies, if set.
+	Timeout time.Duration
+	// Frequency is the time between polls when the service does not give one;
+	// DefaultPollFrequency if zero.
+	Frequency time.Duration
+}
+
+// Wait polls the long-running operation called name until it is done and
+// returns its result, like the poller's PollUntilDone. After each poll it logs
+// the time elapsed, the provisioning state the service returned and when it
+// polls next, so a long wait is not silent.
+//
+// If the operation takes longer than its timeout, Wait returns an error naming
+// it that wraps context.DeadlineExceeded.
+func Wait[T any](ctx context.Context, name string, poller *runtime.Poller[T], options *WaitOptions) (T, error) {
+	var zero T
+	opts := WaitOptions{Timeout: operationTimeout, Frequency: DefaultPollFrequency}
+	if options != nil && options.Timeout > 0 {
+		opts.Timeout = options.Timeout
+	}
+	if options != nil && options.Frequency > 0 {
+		opts.Frequency = options.Frequency
+	}
+
+	parent := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	// timedOut names the operation in the error if its own timeout expired
+	timedOut := func(err error) error {
+		if parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s did not finish within %s: %w", name, opts.Timeout, context.DeadlineExceeded)
+		}
+		return err
+	}
+
+	start := time.Now()
+	for !poller.Done() {
+		if err := ctx.Err(); err != nil {
+			return zero, timedOut(err)
+		}
+		resp, err := poller.Poll(ctx)
+		if err != nil {
+			return zero, timedOut(err)
+		}
+		if poller.Done() {
+			log.Printf("%s: done after %s.", name, time.Since(start).Round(time.Second))
+			break
+		}
+		next := retryAfter(resp, opts.Frequency)
+		log.Printf("%s: %s after %s; polling again in %s.", name, provisioningState(resp), time.Since(start).Round(time.Second), next)
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, timedOut(ctx.Err())
+		case <-timer.C:
+		}
+	}
+	resp, err := poller.Result(ctx)
+	if err != nil {
+		return zero, timedOut(err)
+	}
+	return resp, nil
+}
+
+// retryAfter returns the time the service asks to wait before the next poll, or
+// frequency if it does not say.
+func retryAfter(resp *http.Response, frequency time.Duration) time.Duration {
+	if resp == nil {
+		return frequency
+	}
+	for _, header := range []string{"Retry-After-Ms", "X-Ms-Retry-After-Ms"} {
+		if ms, err := strconv.Atoi(resp.Header.Get(header)); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return frequency
+}
+
+// provisioningState returns the provisioning state of the resource in a poll
+// response, or the status of the operation, or "in progress" if it has neither.
+func provisioningState(resp *http.Response) string {
+	var v struct {
+		Status     string `json:"status"`
+		Properties struct {
+			ProvisioningState string `json:"provisioningState"`
+		} `json:"properties"`
+	}
+	if body, err := runtime.Payload(resp); err == nil {
+		// not every body is a JSON object
+		_ = json.Unmarshal(body, &v)
+	}
+	switch {
+	case v.Properties.ProvisioningState != "":
+		return v.Properties.ProvisioningState
+	case v.Status != "":
+		return v.Status
+	}
+	return "in progress"
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+// newPollingSample returns a Sample whose resource group deletion is polled
+// twice, the service asking to wait retryAfterMS between polls and reporting
+// the deletion in progress.
+func newPollingSample(retryAfterMS string) *Sample {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	})
+	fake := sample.ClientOptions.Transport
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := fake.Do(req)
+		if err == nil {
+			resp.Header.Set("Retry-After-Ms", retryAfterMS)
+			if resp.StatusCode == http.StatusAccepted {
+				resp.Body = io.NopCloser(strings.NewReader(`{"status":"InProgress"}`))
+			}
+		}
+		return resp, err
+	})
+	return sample
+}
+
+func TestWaitLogsProgress(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	sample := newPollingSample("1")
+	if err := sample.DeleteResourceGroup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"delete resource group sample-resource-group: InProgress after 0s; polling again in 1ms.",
+		"delete resource group sample-resource-group: done after 0s.",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("the log does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	sample := newPollingSample("3600000")
+	client, err := sample.ResourceGroupsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	poller, err := client.BeginDelete(context.Background(), sample.ResourceGroupName, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Wait(context.Background(), "delete resource group", poller, &WaitOptions{Timeout: 10 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if want := "delete resource group did not finish within 10ms"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got error %q, want it to start with %q", err, want)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header, value string
+		want          time.Duration
+	}{
+		{"Retry-After-Ms", "250", 250 * time.Millisecond},
+		{"X-Ms-Retry-After-Ms", "100", 100 * time.Millisecond},
+		{"Retry-After", "15", 15 * time.Second},
+		{"Retry-After", "soon", DefaultPollFrequency},
+		{"", "", DefaultPollFrequency},
+	}
+	for _, test := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if test.header != "" {
+			resp.Header.Set(test.header, test.value)
+		}
+		if got := retryAfter(resp, DefaultPollFrequency); got != test.want {
+			t.Errorf("%s: %q gives %s, want %s", test.header, test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	// RecordEnv names the environment variable holding the path of a cassette
+	// to record the sample's requests and Azure's responses to. The -record
+	// flag takes precedence over it.
+	RecordEnv = "SAMPLE_RECORD"
+
+	// ReplayEnv names the environment variable holding the path of a cassette
+	// to replay instead of sending requests to Azure. The -replay flag takes
+	// precedence over it.
+	ReplayEnv = "SAMPLE_REPLAY"
+)
+
+// sanitizedValue replaces the secrets removed from a cassette.
+const sanitizedValue = "sanitized"
+
+// Cassette holds the requests of one sample run and the responses Azure gave to them.
+type Cassette struct {
+	// NameSeed is the seed of the unique names of the recorded run, so a
+	// replay uses the same names.
+	NameSeed string `json:"nameSeed,omitempty"`
+	// RunTags are the tags of the recorded run's resource group, so a replay
+	// sends the same ones.
+	RunTags      map[string]*string `json:"runTags,omitempty"`
+	Interactions []Interaction      `json:"interactions"`
+}
+
+// Interaction is one request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request a ReplayTransport matches on.
+type RecordedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response as a ReplayTransport plays it back.
+type RecordedResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+}
+
+// LoadCassette reads the cassette saved at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cannot parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Sanitizer removes secrets from what is recorded. The IDs it is given, such as
+// the subscription or the tenant, are replaced by a placeholder wherever they
+// appear, and the string values of JSON properties named like secrets, such as
+// adminPassword, primaryKey or clientSecret, are replaced by "sanitized".
+//
+// Requests are sanitized the same way before they are matched during a replay,
+// so a replay matches a recording made with other IDs or secrets.
+type Sanitizer struct {
+	ids []string
+}
+
+// NewSanitizer returns a Sanitizer removing ids. Empty ids are ignored.
+func NewSanitizer(ids ...string) *Sanitizer {
+	s := &Sanitizer{}
+	for _, id := range ids {
+		if id != "" && id != placeholderID {
+			s.ids = append(s.ids, id)
+		}
+	}
+	return s
+}
+
+// String returns v with the IDs replaced.
+func (s *Sanitizer) String(v string) string {
+	for _, id := range s.ids {
+		v = strings.ReplaceAll(v, id, placeholderID)
+		v = strings.ReplaceAll(v, strings.ToLower(id), placeholderID)
+	}
+	return v
+}
+
+// Body returns body with the IDs and secrets replaced.
+func (s *Sanitizer) Body(body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	var v any
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err == nil {
+		if data, err := json.Marshal(sanitizeSecrets(v)); err == nil {
+			body = data
+		}
+	}
+	return []byte(s.String(string(body)))
+}
+
+func sanitizeSecrets(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		_, isKey := v["keyName"]
+		for name, value := range v {
+			if _, ok := value.(string); ok && (isSecretName(name) || isKey && name == "value") {
+				v[name] = sanitizedValue
+			} else {
+				v[name] = sanitizeSecrets(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = sanitizeSecrets(v[i])
+		}
+	}
+	return v
+}
+
+// isSecretName reports whether a JSON property of that name holds a secret.
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range []string{"password", "secret", "key", "token", "connectionstring"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordingTransport sends requests with another transport and records each
+// request and its response, sanitized, to a cassette.
+type RecordingTransport struct {
+	mu        sync.Mutex
+	next      policy.Transporter
+	sanitizer *Sanitizer
+	cassette  Cassette
+}
+
+// NewRecordingTransport returns a RecordingTransport sending requests with next.
+func NewRecordingTransport(next policy.Transporter, sanitizer *Sanitizer) *RecordingTransport {
+	return &RecordingTransport{next: next, sanitizer: sanitizer}
+}
+
+// Do implements policy.Transporter.
+func (t *RecordingTransport) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[string]string{}
+	for name := range resp.Header {
+		header[name] = t.sanitizer.String(resp.Header.Get(name))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request:  t.sanitizer.request(req, reqBody),
+		Response: RecordedResponse{StatusCode: resp.StatusCode, Header: header, Body: rawBody(t.sanitizer.Body(respBody))},
+	})
+	return resp, nil
+}
+
+// Cassette returns what has been recorded so far.
+func (t *RecordingTransport) Cassette() *Cassette {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.cassette
+	c.Interactions = append([]Interaction(nil), t.cassette.Interactions...)
+	return &c
+}
+
+// ReplayTransport answers requests with the responses of a cassette instead of
+// sending them. Each recorded interaction is played back once, in the recorded
+// order among those that match, so the polls of a long-running operation get
+// their recorded responses one after the other. Replayed responses ask for no
+// delay before the next poll.
+type ReplayTransport struct {
+	// Match reports whether req matches the recorded request. It is
+	// MatchRequest unless set to something else before the first request.
+	Match func(recorded, req RecordedRequest) bool
+
+	mu        sync.Mutex
+	cassette  *Cassette
+	sanitizer *Sanitizer
+	played    []bool
+}
+
+// NewReplayTransport returns a ReplayTransport playing back c. Requests are
+// sanitized with sanitizer before they are matched.
+func NewReplayTransport(c *Cassette, sanitizer *Sanitizer) *ReplayTransport {
+	return &ReplayTransport{
+		Match:     MatchRequest,
+		cassette:  c,
+		sanitizer: sanitizer,
+		played:    make([]bool, len(c.Interactions)),
+	}
+}
+
+// Do implements policy.Transporter.
+func (t *ReplayTransport) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	recorded := t.sanitizer.request(req, body)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	next := -1
+	for i, interaction := range t.cassette.Interactions {
+		if t.played[i] {
+			continue
+		}
+		if next < 0 {
+			next = i
+		}
+		if !t.Match(interaction.Request, recorded) {
+			continue
+		}
+		t.played[i] = true
+		return interaction.Response.httpResponse(req), nil
+	}
+
+	if next < 0 {
+		return nil, replayError(fmt.Sprintf("replay: %s %s was not recorded: all %d recorded interactions have been played back",
+			req.Method, req.URL, len(t.cassette.Interactions)))
+	}
+	want := t.cassette.Interactions[next].Request
+	return nil, replayError(fmt.Sprintf("replay: no recorded interaction matches %s %s; the next one not played back is %s %s",
+		req.Method, req.URL, want.Method, want.URL))
+}
+
+// replayError reports a request the cassette has no response for.
+type replayError string
+
+func (e replayError) Error() string { return string(e) }
+
+// NonRetriable tells the retry policy that sending the request again does not help.
+func (replayError) NonRetriable() {}
+
+// Unplayed returns how many recorded interactions have not been played back.
+func (t *ReplayTransport) Unplayed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, played := range t.played {
+		if !played {
+			n++
+		}
+	}
+	return n
+}
+
+// MatchRequest reports whether req matches the recorded request: the methods
+// are the same, the URLs are the same apart from the case of the path and the
+// order of the query parameters, and the bodies hold the same JSON.
+func MatchRequest(recorded, req RecordedRequest) bool {
+	return strings.EqualFold(recorded.Method, req.Method) && sameURL(recorded.URL, req.URL) && sameBody(recorded.Body, req.Body)
+}
+
+func sameURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return strings.EqualFold(ua.Host, ub.Host) &&
+		strings.EqualFold(ua.Path, ub.Path) &&
+		ua.Query().Encode() == ub.Query().Encode()
+}
+
+func sameBody(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func (s *Sanitizer) request(req *http.Request, body []byte) RecordedRequest {
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    s.String(req.URL.String()),
+		Body:   rawBody(s.Body(body)),
+	}
+}
+
+func (r RecordedResponse) httpResponse(req *http.Request) *http.Response {
+	resp := &http.Response{
+		StatusCode: r.StatusCode,
+		Status:     http.StatusText(r.StatusCode),
+		Header:     http.Header{},
+		Request:    req,
+		Body:       http.NoBody,
+	}
+	for name, value := range r.Header {
+		resp.Header.Set(name, value)
+	}
+	// the recorded delays were needed by Azure, not by the replay
+	resp.Header.Del("Retry-After")
+	resp.Header.Set("Retry-After-Ms", "1")
+	if body := bodyBytes(r.Body); len(body) > 0 {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+	}
+	return resp
+}
+
+// readBody reads the body and puts back a reader of what was read, so the body can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// rawBody returns body as it is saved in a cassette: as is if it is JSON, as a
+// JSON string otherwise.
+func rawBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return body
+	}
+	data, _ := json.Marshal(string(body))
+	return data
+}
+
+// bodyBytes reverses rawBody.
+func bodyBytes(raw json.RawMessage) []byte {
+	var s string
+	if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return []byte(s)
+	}
+	return raw
+}
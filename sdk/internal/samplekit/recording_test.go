@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+const recordedSubscriptionID = "11111111-2222-3333-4444-555555555555"
+
+// runResourceGroup creates the sample's resource group with a secret tag, then deletes it.
+func runResourceGroup(ctx context.Context, sample *Sample) error {
+	sample.Tags = map[string]*string{"apiToken": to.Ptr("s3cr3t")}
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		return err
+	}
+	if *resourceGroup.Name != sample.ResourceGroupName {
+		return fmt.Errorf("unexpected resource group %s", *resourceGroup.Name)
+	}
+	return sample.DeleteResourceGroup(ctx)
+}
+
+// transportFunc turns a function into a policy.Transporter.
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestRecordReplay(t *testing.T) {
+	srv := resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{
+					ID:       to.Ptr("/subscriptions/" + recordedSubscriptionID + "/resourceGroups/" + name),
+					Name:     to.Ptr(name),
+					Location: parameters.Location,
+					Tags:     parameters.Tags,
+				},
+			}, nil)
+			return
+		},
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	}
+	fake := resourcesfake.NewResourceGroupsServerTransport(&srv)
+	sample := &Sample{
+		SubscriptionID:    recordedSubscriptionID,
+		ResourceGroupName: "sample-resource-group",
+		Location:          "westus",
+		Credential:        &azfake.TokenCredential{},
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+					resp, err := fake.Do(req)
+					if err == nil {
+						// poll the fake server without waiting
+						resp.Header.Set("Retry-After-Ms", "1")
+					}
+					return resp, err
+				}),
+			},
+		},
+	}
+	recorder := sample.Record()
+	ctx := context.Background()
+	if err := runResourceGroup(ctx, sample); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{recordedSubscriptionID, "s3cr3t"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cassette.Interactions); n < 3 {
+		t.Fatalf("recorded %d interactions, want the create, the delete and its polls", n)
+	}
+
+	t.Setenv(SubscriptionIDEnv, "")
+	replay := NewReplay("sample-resource-group", "westus", cassette)
+	if err := runResourceGroup(ctx, replay); err != nil {
+		t.Fatal(err)
+	}
+	player := replay.ClientOptions.Transport.(*ReplayTransport)
+	if n := player.Unplayed(); n != 0 {
+		t.Errorf("%d interactions were not played back", n)
+	}
+
+	// every interaction has been played back
+	err = replay.DeleteResourceGroup(ctx)
+	if err == nil || !strings.Contains(err.Error(), "all 3 recorded interactions have been played back") {
+		t.Errorf("unexpected error when the cassette runs out: %v", err)
+	}
+
+	// the request does not match the recording
+	other := NewReplay("other-resource-group", "westus", cassette)
+	if _, err := other.CreateResourceGroup(ctx); err == nil || !strings.Contains(err.Error(), "no recorded interaction matches PUT") {
+		t.Errorf("unexpected error for an unrecorded request: %v", err)
+	}
+}
+
+func TestSanitizer(t *testing.T) {
+	s := NewSanitizer(recordedSubscriptionID, "", placeholderID)
+	body := s.Body([]byte(`{
+		"id": "/subscriptions/` + strings.ToUpper(recordedSubscriptionID) + `/resourceGroups/rg",
+		"properties": {
+			"administratorLoginPassword": "p@ss",
+			"primaryKey": "k1",
+			"keyVaultName": "vault",
+			"count": 12345678901234567890
+		},
+		"keys": [{"keyName": "key1", "value": "k2"}]
+	}`))
+	for _, secret := range []string{recordedSubscriptionID, strings.ToUpper(recordedSubscriptionID), "p@ss", "k1", "k2"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("sanitized body contains %q: %s", secret, body)
+		}
+	}
+	for _, kept := range []string{"vault", "key1", "12345678901234567890", placeholderID} {
+		if !strings.Contains(string(body), kept) {
+			t.Errorf("sanitized body lost %q: %s", kept, body)
+		}
+	}
+}
+
+func TestMatchRequest(t *testing.T) {
+	recorded := RecordedRequest{
+		Method: http.MethodPut,
+		URL:    "https://management.azure.com/subscriptions/s/resourcegroups/rg?b=2&a=1",
+		Body:   []byte(`{"location":"westus","tags":{"a":"1"}}`),
+	}
+	tests := []struct {
+		req  RecordedRequest
+		want bool
+	}{
+		{RecordedRequest{http.MethodPut, "https://management.azure.com/subscriptions/s/resourceGroups/rg?a=1&b=2", []byte(`{"tags": {"a": "1"}, "location": "westus"}`)}, true},
+		{RecordedRequest{http.MethodPatch, recorded.URL, recorded.Body}, false},
+		{RecordedRequest{http.MethodPut, "https://management.azure.com/subscriptions/s/resourcegroups/rg2?a=1&b=2", recorded.Body}, false},
+		{RecordedRequest{http.MethodPut, recorded.URL, []byte(`{"location":"eastus","tags":{"a":"1"}}`)}, false},
+		{RecordedRequest{http.MethodPut, recorded.URL, nil}, false},
+	}
+	for _, tt := range tests {
+		if got := MatchRequest(recorded, tt.req); got != tt.want {
+			t.Errorf("MatchRequest(%s %s %s) = %v, want %v", tt.req.Method, tt.req.URL, tt.req.Body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+)
+
+// ReportEnv names the environment variable holding the path of the JSON run
+// report to write, or "-" to write it to stdout. The -report flag takes
+// precedence over it.
+const ReportEnv = "SAMPLE_REPORT"
+
+// Report describes a sample run in a form pipelines can parse.
+type Report struct {
+	// Sample is the module path of the sample.
+	Sample string `json:"sample"`
+	// SDK holds the version of each Azure SDK for Go module the sample was built with.
+	SDK               map[string]string `json:"sdk,omitempty"`
+	ResourceGroupName string            `json:"resourceGroupName"`
+	Location          string            `json:"location"`
+	Start             time.Time         `json:"start"`
+	DurationMS        int64             `json:"durationMs"`
+	// Error is why the run failed, including its cleanup, and Failure the
+	// category of the error.
+	Error   string        `json:"error,omitempty"`
+	Failure string        `json:"failure,omitempty"`
+	Steps   []*StepReport `json:"steps"`
+}
+
+// StepReport describes one operation of a sample, such as
+// "AccountsClient.BeginCreate". The polls of a long-running operation are
+// part of the step that began it.
+type StepReport struct {
+	Name              string     `json:"name"`
+	Start             time.Time  `json:"start"`
+	DurationMS        int64      `json:"durationMs"`
+	ResourceID        string     `json:"resourceId,omitempty"`
+	ProvisioningState string     `json:"provisioningState,omitempty"`
+	Error             *StepError `json:"error,omitempty"`
+
+	// lastError is the error response the step got last, if any.
+	lastError *StepError
+}
+
+// StepError holds the details of a failed step.
+type StepError struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Save writes the report as indented JSON to path, or to stdout if path is "-".
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Reporter builds the Report of a sample run from the operations its clients
+// run. It gets them through the tracing provider and a per-call policy of the
+// client options, so samples do not have to report their steps themselves.
+type Reporter struct {
+	mu     sync.Mutex
+	report Report
+	// polled maps the URLs a long-running operation is polled at, from
+	// pollKey, to the step that began it.
+	polled map[string]*StepReport
+}
+
+// StartReport makes the clients created with the sample's ClientOptions
+// report their operations, and returns the Reporter collecting them. The
+// sample's tracing provider, if any, still gets every span.
+func (s *Sample) StartReport() *Reporter {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	r := &Reporter{
+		report: Report{
+			ResourceGroupName: s.ResourceGroupName,
+			Location:          s.Location,
+			Start:             time.Now(),
+			Steps:             []*StepReport{},
+		},
+		polled: map[string]*StepReport{},
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		r.report.Sample = info.Main.Path
+		r.report.SDK = map[string]string{}
+		for _, dep := range info.Deps {
+			if strings.HasPrefix(dep.Path, "github.com/Azure/azure-sdk-for-go/") {
+				r.report.SDK[dep.Path] = dep.Version
+			}
+		}
+	}
+
+	next := s.ClientOptions.TracingProvider
+	s.ClientOptions.TracingProvider = tracing.NewProvider(func(module, version string) tracing.Tracer {
+		return r.tracer(next.NewTracer(module, version))
+	}, nil)
+	s.ClientOptions.PerCallPolicies = append(s.ClientOptions.PerCallPolicies, reportPolicy{r})
+	return r
+}
+
+// Report returns the report of the run so far; err is the error of the run, if any.
+func (r *Reporter) Report(err error) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.report
+	report.DurationMS = time.Since(report.Start).Milliseconds()
+	if err != nil {
+		report.Error = err.Error()
+		report.Failure = Classify(err).String()
+	}
+	report.Steps = make([]*StepReport, len(r.report.Steps))
+	for i, step := range r.report.Steps {
+		copied := *step
+		report.Steps[i] = &copied
+	}
+	return &report
+}
+
+type stepKey struct{}
+
+// spanStep is the step of a span. The step of a poller span is only known
+// once it sends a request, from the URL it polls.
+type spanStep struct {
+	// name is the name of the step, used if the span needs a step of its own.
+	name string
+	step *StepReport
+}
+
+// tracer returns a tracer starting the spans of next and a step for each SDK operation.
+func (r *Reporter) tracer(next tracing.Tracer) tracing.Tracer {
+	return tracing.NewTracer(func(ctx context.Context, name string, options *tracing.SpanOptions) (context.Context, tracing.Span) {
+		ctx, span := next.Start(ctx, name, options)
+		if options != nil && options.Kind == tracing.SpanKindClient {
+			// the HTTP requests are reported by reportPolicy as part of their operation
+			return ctx, span
+		}
+
+		step := r.startStep(name)
+		ctx = context.WithValue(ctx, stepKey{}, step)
+		return ctx, tracing.NewSpan(tracing.SpanImpl{
+			End: func() {
+				r.endStep(step)
+				span.End()
+			},
+			SetAttributes: span.SetAttributes,
+			AddEvent:      span.AddEvent,
+			SetStatus: func(code tracing.SpanStatus, desc string) {
+				if code == tracing.SpanStatusError {
+					r.failStep(r.resolve(step, nil), desc)
+				}
+				span.SetStatus(code, desc)
+			},
+		})
+	}, &tracing.TracerOptions{SpanFromContext: next.SpanFromContext})
+}
+
+// pollerSpan matches the spans of pollers and pagers, such as
+// "Poller[AccountsClientCreateResponse].PollUntilDone".
+var pollerSpan = regexp.MustCompile(`^(Poller|Pager)\[(\w+Client)(\w+)Response\]\.\w+$`)
+
+// startStep returns the step of a span. A poller span gets the step of the
+// operation it polls once it sends a request, see resolve.
+func (r *Reporter) startStep(name string) *spanStep {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m := pollerSpan.FindStringSubmatch(name); m != nil {
+		if m[1] == "Poller" {
+			return &spanStep{name: m[2] + ".Begin" + m[3]}
+		}
+		name = m[2] + ".New" + m[3] + "Pager"
+	}
+	return &spanStep{name: name, step: r.addStep(name)}
+}
+
+func (r *Reporter) addStep(name string) *StepReport {
+	step := &StepReport{Name: name, Start: time.Now()}
+	r.report.Steps = append(r.report.Steps, step)
+	return step
+}
+
+// resolve returns the step of s, looking up the operation polled at u for a
+// poller span, or adding a step of its own if the URL is not known.
+func (r *Reporter) resolve(s *spanStep, u *url.URL) *StepReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.step == nil && u != nil {
+		s.step = r.polled[pollKey(u)]
+	}
+	if s.step == nil {
+		s.step = r.addStep(s.name)
+	}
+	return s.step
+}
+
+func (r *Reporter) endStep(s *spanStep) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a poller span that sent no request has nothing to report
+	if s.step != nil {
+		s.step.DurationMS = time.Since(s.step.Start).Milliseconds()
+	}
+}
+
+// pollHeaders hold the URLs a long-running operation may be polled at.
+var pollHeaders = []string{"Azure-AsyncOperation", "Operation-Location", "Location"}
+
+// fakePollSuffix is appended by the fake servers to the URL of the operation
+// to poll it.
+const fakePollSuffix = "/get/fake/status"
+
+// pollKey returns the key of the operation polled at u in Reporter.polled.
+// The query is left out, as the API version of a poll may differ.
+func pollKey(u *url.URL) string {
+	return strings.ToLower(u.Host + strings.TrimSuffix(strings.TrimSuffix(u.Path, fakePollSuffix), "/"))
+}
+
+// addPolled records the URLs the operation of step may be polled at: that of
+// its request, polled by some resource types, and those its response names.
+func (r *Reporter) addPolled(step *StepReport, req *http.Request, resp *http.Response) {
+	r.polled[pollKey(req.URL)] = step
+	for _, header := range pollHeaders {
+		if u, err := url.Parse(resp.Header.Get(header)); err == nil && u.Host != "" {
+			r.polled[pollKey(u)] = step
+		}
+	}
+}
+
+func (r *Reporter) failStep(step *StepReport, desc string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if step.lastError != nil {
+		step.Error = step.lastError
+		return
+	}
+	// desc is the type of the error, then its message
+	if _, message, ok := strings.Cut(desc, "\n"); ok {
+		desc = message
+	}
+	step.Error = &StepError{Message: strings.TrimSpace(desc)}
+}
+
+// update fills in the step from a response to one of its requests.
+func (r *Reporter) update(step *StepReport, req *http.Request, resp *http.Response, body []byte) {
+	var v struct {
+		ID         string `json:"id"`
+		Status     string `json:"status"`
+		Properties struct {
+			ProvisioningState string `json:"provisioningState"`
+		} `json:"properties"`
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	// not every body is a JSON object
+	_ = json.Unmarshal(body, &v)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if strings.Contains(step.Name, ".Begin") {
+		r.addPolled(step, req, resp)
+	}
+	switch req.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if step.ResourceID == "" {
+			step.ResourceID = req.URL.Path
+		}
+	}
+	if v.ID != "" && strings.EqualFold(v.ID, strings.TrimSuffix(req.URL.Path, "/")) {
+		step.ResourceID = v.ID
+	}
+	if v.Properties.ProvisioningState != "" {
+		step.ProvisioningState = v.Properties.ProvisioningState
+	} else if v.Status != "" {
+		// the status of a long-running operation
+		step.ProvisioningState = v.Status
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		step.lastError = &StepError{StatusCode: resp.StatusCode, Code: v.Error.Code, Message: v.Error.Message}
+		if step.lastError.Message == "" {
+			step.lastError.Message = resp.Status
+		}
+	}
+}
+
+// reportPolicy passes the responses of each operation to the Reporter.
+type reportPolicy struct {
+	r *Reporter
+}
+
+func (p reportPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	s, ok := req.Raw().Context().Value(stepKey{}).(*spanStep)
+	if !ok {
+		return resp, err
+	}
+	step := p.r.resolve(s, req.Raw().URL)
+	var respErr *azcore.ResponseError
+	if resp == nil && errors.As(err, &respErr) {
+		p.r.mu.Lock()
+		step.lastError = &StepError{StatusCode: respErr.StatusCode, Code: respErr.ErrorCode, Message: http.StatusText(respErr.StatusCode)}
+		p.r.mu.Unlock()
+	}
+	if resp == nil {
+		return resp, err
+	}
+	body, bodyErr := runtime.Payload(resp)
+	if bodyErr != nil {
+		return resp, err
+	}
+	p.r.update(step, req.Raw(), resp, body)
+	return resp, err
+}
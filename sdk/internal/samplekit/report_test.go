@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestReport(t *testing.T) {
+	srv := resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{
+					ID:         to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name),
+					Name:       to.Ptr(name),
+					Location:   parameters.Location,
+					Properties: &armresources.ResourceGroupProperties{ProvisioningState: to.Ptr("Succeeded")},
+				},
+			}, nil)
+			return
+		},
+		Get: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientGetOptions) (resp azfake.Responder[armresources.ResourceGroupsClientGetResponse], errResp azfake.ErrorResponder) {
+			errResp.SetResponseError(http.StatusNotFound, "ResourceGroupNotFound")
+			return
+		},
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	}
+	sample := newFakeSample(&srv)
+	fake := sample.ClientOptions.Transport
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := fake.Do(req)
+		if err == nil {
+			// poll the fake server without waiting
+			resp.Header.Set("Retry-After-Ms", "1")
+		}
+		return resp, err
+	})
+	reporter := sample.StartReport()
+
+	err := sample.Run(context.Background(), func(ctx context.Context, s *Sample) error {
+		if _, err := s.CreateResourceGroup(ctx); err != nil {
+			return err
+		}
+		client, err := s.ResourceGroupsClient()
+		if err != nil {
+			return err
+		}
+		_, err = client.Get(ctx, "missing-resource-group", nil)
+		return err
+	})
+	if err == nil {
+		t.Fatal("expected the error of the missing resource group")
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := reporter.Report(err).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.ResourceGroupName != "sample-resource-group" || report.Error == "" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	resourceGroupID := "/subscriptions/" + subscriptionID + "/resourceGroups/sample-resource-group"
+	want := []StepReport{
+		{Name: "ResourceGroupsClient.CreateOrUpdate", ResourceID: resourceGroupID, ProvisioningState: "Succeeded"},
+		{Name: "ResourceGroupsClient.Get", Error: &StepError{StatusCode: http.StatusNotFound, Code: "ResourceGroupNotFound", Message: "Not Found"}},
+		// the polls of the deletion are part of its step
+		{Name: "ResourceGroupsClient.BeginDelete", ResourceID: "/subscriptions/" + subscriptionID + "/resourcegroups/sample-resource-group"},
+	}
+	if len(report.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d:\n%s", len(report.Steps), len(want), data)
+	}
+	for i, step := range report.Steps {
+		if step.Start.IsZero() {
+			t.Errorf("step %s has no start", step.Name)
+		}
+		step.Start = want[i].Start
+		step.DurationMS = 0
+		if !reflect.DeepEqual(*step, want[i]) {
+			got, _ := json.Marshal(step)
+			wanted, _ := json.Marshal(want[i])
+			t.Errorf("step %d = %s, want %s", i, got, wanted)
+		}
+	}
+}
+
+func TestReportParallelPollers(t *testing.T) {
+	srv := resourcesfake.ResourceGroupsServer{
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			if name == "failing-resource-group" {
+				resp.SetTerminalError(http.StatusConflict, "ResourceGroupDeletionBlocked")
+			} else {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			}
+			return
+		},
+	}
+	sample := newFakeSample(&srv)
+	fake := sample.ClientOptions.Transport
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := fake.Do(req)
+		if err == nil {
+			resp.Header.Set("Retry-After-Ms", "1")
+		}
+		return resp, err
+	})
+	reporter := sample.StartReport()
+
+	ctx := context.Background()
+	client, err := sample.ResourceGroupsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two operations of the same type polled at the same time
+	failing, err := client.BeginDelete(ctx, "failing-resource-group", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	succeeding, err := client.BeginDelete(ctx, "succeeding-resource-group", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Wait(ctx, "succeeding", succeeding, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Wait(ctx, "failing", failing, nil); err == nil {
+		t.Fatal("expected the error of the failing deletion")
+	}
+
+	report := reporter.Report(nil)
+	if len(report.Steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(report.Steps))
+	}
+	for _, step := range report.Steps {
+		failed := strings.HasSuffix(step.ResourceID, "/failing-resource-group")
+		if got := step.Error != nil; got != failed {
+			t.Errorf("step of %s has error %+v", step.ResourceID, step.Error)
+		}
+	}
+}
+
+func TestReportKeepsTracingProvider(t *testing.T) {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{})
+	reporter := sample.StartReport()
+	if !sample.ClientOptions.TracingProvider.NewTracer("module", "v1.0.0").Enabled() {
+		t.Fatal("the tracing provider is not enabled")
+	}
+	if report := reporter.Report(errors.New("failed")); report.Error != "failed" || len(report.Steps) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
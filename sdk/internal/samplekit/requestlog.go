@@ -0,0 +1,164 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// RequestLogEnv names the environment variable holding the path of the request
+// log to write. The -request-log flag takes precedence over it.
+const RequestLogEnv = "SAMPLE_REQUEST_LOG"
+
+const (
+	clientRequestIDHeader      = "x-ms-client-request-id"
+	requestIDHeader            = "x-ms-request-id"
+	correlationRequestIDHeader = "x-ms-correlation-request-id"
+)
+
+// maxStepNameLength bounds the step name in client request IDs, which
+// services limit in length.
+const maxStepNameLength = 40
+
+type stepNameKey struct{}
+
+// withStepName returns ctx for the requests of the step called name.
+func withStepName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, stepNameKey{}, name)
+}
+
+// StepName returns the name of the step the requests made with ctx belong to,
+// as given to Step, or "" if they belong to none.
+func StepName(ctx context.Context) string {
+	name, _ := ctx.Value(stepNameKey{}).(string)
+	return name
+}
+
+// StampRequests makes the clients created with the sample's ClientOptions
+// send every request with an x-ms-client-request-id made of the run ID, the
+// name of the step making the request and the number of the request in the
+// run, such as "0d3c...-createVirtualNetwork-12". Azure logs the ID with the
+// request, so a support case can name the requests of a failed step. The
+// retries of a request keep its ID. Requests outside any step are named by
+// their method and resource type instead of a step.
+func (s *Sample) StampRequests() {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	s.ClientOptions.PerCallPolicies = append(s.ClientOptions.PerCallPolicies, &clientRequestIDPolicy{runID: s.RunID})
+}
+
+// clientRequestIDPolicy stamps requests with the client request ID of StampRequests.
+type clientRequestIDPolicy struct {
+	runID string
+	// requests counts the requests of the run.
+	requests int64
+}
+
+func (p *clientRequestIDPolicy) Do(req *policy.Request) (*http.Response, error) {
+	n := atomic.AddInt64(&p.requests, 1)
+	step := StepName(req.Raw().Context())
+	if step == "" {
+		step = operationName(req.Raw())
+	}
+	req.Raw().Header.Set(clientRequestIDHeader, clientRequestID(p.runID, step, n))
+	return req.Next()
+}
+
+// clientRequestID returns the client request ID of the nth request of the run
+// runID, made by step. The step name keeps only the letters, digits, dashes
+// and underscores, so the ID is safe in any header or log.
+func clientRequestID(runID, step string, n int64) string {
+	var name strings.Builder
+	dash := false
+	for _, r := range step {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			name.WriteRune(r)
+			dash = false
+		case !dash && name.Len() > 0:
+			name.WriteByte('-')
+			dash = true
+		}
+		if name.Len() >= maxStepNameLength {
+			break
+		}
+	}
+	id := runID
+	if s := strings.TrimRight(name.String(), "-"); s != "" {
+		id += "-" + s
+	}
+	return fmt.Sprintf("%s-%d", id, n)
+}
+
+// LogRequests makes the clients created with the sample's ClientOptions write
+// a line to w for every attempt of every request, after a header line: when
+// it was sent, its method, URL, status code and duration, and the
+// x-ms-client-request-id it was sent with and the x-ms-request-id and
+// x-ms-correlation-request-id Azure answered with, "-" for the ones missing.
+// The fields are separated by tabs.
+func (s *Sample) LogRequests(w io.Writer) error {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	if _, err := fmt.Fprintln(w, "TIME\tMETHOD\tURL\tSTATUS\tDURATION\tCLIENT-REQUEST-ID\tREQUEST-ID\tCORRELATION-REQUEST-ID"); err != nil {
+		return err
+	}
+	s.ClientOptions.PerRetryPolicies = append(s.ClientOptions.PerRetryPolicies, &requestLogPolicy{w: w})
+	return nil
+}
+
+// requestLogPolicy writes the request log of LogRequests.
+type requestLogPolicy struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (p *requestLogPolicy) Do(req *policy.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := req.Next()
+	duration := time.Since(start)
+
+	raw := resp
+	if raw == nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) {
+			raw = respErr.RawResponse
+		}
+	}
+	status := "-"
+	requestID, correlationID := "-", "-"
+	if raw != nil {
+		status = fmt.Sprint(raw.StatusCode)
+		requestID = headerOr(raw.Header, requestIDHeader)
+		correlationID = headerOr(raw.Header, correlationRequestIDHeader)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// a failed write must not fail the request
+	fmt.Fprintf(p.w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		start.UTC().Format(time.RFC3339Nano), req.Raw().Method, req.Raw().URL, status, duration.Round(time.Millisecond),
+		headerOr(req.Raw().Header, clientRequestIDHeader), requestID, correlationID)
+	return resp, err
+}
+
+// headerOr returns the value of the header name, or "-" if there is none.
+func headerOr(h http.Header, name string) string {
+	if v := h.Get(name); v != "" {
+		return v
+	}
+	return "-"
+}
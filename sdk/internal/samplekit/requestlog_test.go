@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestRequestLog(t *testing.T) {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			resp.SetResponse(http.StatusCreated, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{ID: to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name)},
+			}, nil)
+			return
+		},
+		Get: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientGetOptions) (resp azfake.Responder[armresources.ResourceGroupsClientGetResponse], errResp azfake.ErrorResponder) {
+			errResp.SetResponseError(http.StatusNotFound, "ResourceGroupNotFound")
+			return
+		},
+	})
+	var clientRequestIDs []string
+	fake := sample.ClientOptions.Transport
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		clientRequestIDs = append(clientRequestIDs, req.Header.Get("x-ms-client-request-id"))
+		resp, err := fake.Do(req)
+		// Azure answers errors with a response too
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) {
+			resp, err = respErr.RawResponse, nil
+		}
+		if err == nil {
+			resp.Header.Set("x-ms-request-id", "request-"+req.Method)
+			resp.Header.Set("x-ms-correlation-request-id", "correlation-"+req.Method)
+		}
+		return resp, err
+	})
+	sample.RunID = "run"
+	sample.StampRequests()
+	var log strings.Builder
+	if err := sample.LogRequests(&log); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := Step(ctx, sample, "create resource group", sample.CreateResourceGroup); err != nil {
+		t.Fatal(err)
+	}
+	client, err := sample.ResourceGroupsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, sample.ResourceGroupName, nil); !NotFound(err) {
+		t.Fatalf("got error %v, want not found", err)
+	}
+
+	want := []string{"run-create-resource-group-1", "run-GET-resourcegroups-2"}
+	if strings.Join(clientRequestIDs, " ") != strings.Join(want, " ") {
+		t.Errorf("got client request IDs %q, want %q", clientRequestIDs, want)
+	}
+	lines := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TIME\tMETHOD\tURL") {
+		t.Fatalf("got request log\n%s", log.String())
+	}
+	for i, want := range []struct{ method, status, clientRequestID string }{
+		{"PUT", "201", "run-create-resource-group-1"},
+		{"GET", "404", "run-GET-resourcegroups-2"},
+	} {
+		fields := strings.Split(lines[i+1], "\t")
+		if len(fields) != 8 {
+			t.Fatalf("got line %q, want 8 fields", lines[i+1])
+		}
+		if !strings.HasSuffix(fields[2], "/resourcegroups/sample-resource-group?api-version=2021-04-01") {
+			t.Errorf("got URL %s", fields[2])
+		}
+		got := []string{fields[1], fields[3], fields[5], fields[6], fields[7]}
+		wantFields := []string{want.method, want.status, want.clientRequestID, "request-" + want.method, "correlation-" + want.method}
+		if strings.Join(got, " ") != strings.Join(wantFields, " ") {
+			t.Errorf("got fields %q, want %q", got, wantFields)
+		}
+	}
+}
+
+func TestClientRequestID(t *testing.T) {
+	tests := []struct {
+		step string
+		want string
+	}{
+		{"createVirtualNetwork", "run-createVirtualNetwork-7"},
+		{"PUT Microsoft.Network/virtualNetworks", "run-PUT-Microsoft-Network-virtualNetworks-7"},
+		{"delete resource group rg!", "run-delete-resource-group-rg-7"},
+		{"", "run-7"},
+		{strings.Repeat("a", 60), "run-" + strings.Repeat("a", maxStepNameLength) + "-7"},
+	}
+	for _, tt := range tests {
+		if got := clientRequestID("run", tt.step, 7); got != tt.want {
+			t.Errorf("clientRequestID(%q) = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
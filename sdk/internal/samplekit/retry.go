@@ -0,0 +1,253 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// rateLimitHeaderPrefix starts the headers in which ARM tells how many requests
+// of a kind are left before it throttles, such as
+// x-ms-ratelimit-remaining-subscription-writes.
+const rateLimitHeaderPrefix = "X-Ms-Ratelimit-Remaining-"
+
+// defaultRetryStatusCodes are the status codes azcore retries when
+// RetryOptions.StatusCodes is empty.
+var defaultRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryStats counts the retries and throttled responses of a sample's
+// requests, by operation.
+type RetryStats struct {
+	mu  sync.Mutex
+	ops map[string]*OperationRetries
+}
+
+// OperationRetries counts the requests of one operation, named by its method
+// and resource type such as "PUT Microsoft.Network/virtualNetworks".
+type OperationRetries struct {
+	Operation string
+	// Requests is the number of requests the sample made, not counting retries.
+	Requests int
+	// Retries is the number of times the requests were sent again.
+	Retries int
+	// Throttled is the number of 429 Too Many Requests responses.
+	Throttled int
+}
+
+// ConfigureRetries makes the clients created with the sample's ClientOptions
+// retry failed requests as options say, log every response they may retry with
+// its rate limit headers, and count the retries in the returned RetryStats.
+func (s *Sample) ConfigureRetries(options policy.RetryOptions) *RetryStats {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	stats := &RetryStats{ops: map[string]*OperationRetries{}}
+	s.ClientOptions.Retry = options
+	s.ClientOptions.PerCallPolicies = append(s.ClientOptions.PerCallPolicies, retryCallPolicy{stats})
+	s.ClientOptions.PerRetryPolicies = append(s.ClientOptions.PerRetryPolicies, newRetryAttemptPolicy(options))
+	return stats
+}
+
+// Summary returns the counts of every operation, sorted by operation.
+func (r *RetryStats) Summary() []OperationRetries {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summary := make([]OperationRetries, 0, len(r.ops))
+	for _, op := range r.ops {
+		summary = append(summary, *op)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Operation < summary[j].Operation })
+	return summary
+}
+
+// Print writes a table of the operations that were retried or throttled to w,
+// and nothing if no request was.
+func (r *RetryStats) Print(w io.Writer) error {
+	var retried []OperationRetries
+	for _, op := range r.Summary() {
+		if op.Retries > 0 || op.Throttled > 0 {
+			retried = append(retried, op)
+		}
+	}
+	if len(retried) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tREQUESTS\tRETRIES\tTHROTTLED")
+	for _, op := range retried {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", op.Operation, op.Requests, op.Retries, op.Throttled)
+	}
+	return tw.Flush()
+}
+
+func (r *RetryStats) add(call *callRetries) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, ok := r.ops[call.operation]
+	if !ok {
+		op = &OperationRetries{Operation: call.operation}
+		r.ops[call.operation] = op
+	}
+	op.Requests++
+	if call.attempts > 1 {
+		op.Retries += call.attempts - 1
+	}
+	op.Throttled += call.throttled
+}
+
+// callRetries counts the attempts of one request. The attempts of a request
+// are sent one after the other, so it needs no lock.
+type callRetries struct {
+	operation string
+	attempts  int
+	throttled int
+}
+
+type retryKey struct{}
+
+// retryCallPolicy counts the attempts of each request made by the sample in RetryStats.
+type retryCallPolicy struct {
+	stats *RetryStats
+}
+
+func (p retryCallPolicy) Do(req *policy.Request) (*http.Response, error) {
+	call := &callRetries{operation: operationName(req.Raw())}
+	defer p.stats.add(call)
+	return req.Clone(context.WithValue(req.Raw().Context(), retryKey{}, call)).Next()
+}
+
+// retryAttemptPolicy runs on every attempt of a request, after the retry
+// policy, and logs the responses the retry policy may retry.
+type retryAttemptPolicy struct {
+	maxRetries  int32
+	statusCodes []int
+}
+
+func newRetryAttemptPolicy(options policy.RetryOptions) retryAttemptPolicy {
+	p := retryAttemptPolicy{maxRetries: options.MaxRetries, statusCodes: options.StatusCodes}
+	switch {
+	case p.maxRetries == 0:
+		p.maxRetries = 3
+	case p.maxRetries < 0:
+		p.maxRetries = 0
+	}
+	if len(p.statusCodes) == 0 {
+		p.statusCodes = defaultRetryStatusCodes
+	}
+	return p
+}
+
+func (p retryAttemptPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	call, ok := req.Raw().Context().Value(retryKey{}).(*callRetries)
+	if !ok {
+		return resp, err
+	}
+	call.attempts++
+
+	statusCode := 0
+	var respErr *azcore.ResponseError
+	switch {
+	case resp != nil:
+		statusCode = resp.StatusCode
+	case errors.As(err, &respErr):
+		statusCode = respErr.StatusCode
+		resp = respErr.RawResponse
+	}
+	if statusCode == http.StatusTooManyRequests {
+		call.throttled++
+	}
+	if statusCode != 0 && !containsInt(p.statusCodes, statusCode) {
+		return resp, err
+	}
+	if statusCode == 0 && (err == nil || req.Raw().Context().Err() != nil) {
+		return resp, err
+	}
+
+	outcome := "giving up"
+	if int32(call.attempts) <= p.maxRetries {
+		outcome = "retrying"
+	}
+	got := fmt.Sprint(err)
+	if statusCode != 0 {
+		got = fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+	}
+	log.Printf("%s: attempt %d got %s%s; %s.", call.operation, call.attempts, got, retryHeaders(resp), outcome)
+	return resp, err
+}
+
+// retryHeaders formats the headers of resp telling when to retry and how many
+// requests are left before throttling.
+func retryHeaders(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	var headers []string
+	for name, values := range resp.Header {
+		if name == "Retry-After" || name == "Retry-After-Ms" || name == "X-Ms-Retry-After-Ms" || strings.HasPrefix(name, rateLimitHeaderPrefix) {
+			headers = append(headers, strings.ToLower(name)+": "+strings.Join(values, ","))
+		}
+	}
+	if len(headers) == 0 {
+		return ""
+	}
+	sort.Strings(headers)
+	return " (" + strings.Join(headers, ", ") + ")"
+}
+
+// operationName names the operation of req by its method and resource type,
+// such as "PUT Microsoft.Network/virtualNetworks/subnets".
+func operationName(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	// the types are every other segment after the provider namespace, or from
+	// the start for the resource groups and subscriptions
+	start, resourceType := 0, ""
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			start, resourceType = i+2, segments[i+1]
+			break
+		}
+	}
+	var types []string
+	if resourceType != "" {
+		types = append(types, resourceType)
+	}
+	for i := start; i < len(segments); i += 2 {
+		if resourceType == "" && strings.EqualFold(segments[i], "subscriptions") && i+2 < len(segments) {
+			continue
+		}
+		types = append(types, segments[i])
+	}
+	return req.Method + " " + strings.Join(types, "/")
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
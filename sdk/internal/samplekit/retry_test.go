@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestConfigureRetries(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{ID: to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name)},
+			}, nil)
+			return
+		},
+	})
+	fake := sample.ClientOptions.Transport
+	attempts := 0
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			// the first attempt is throttled
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header: http.Header{
+					"Retry-After-Ms": {"1"},
+					"X-Ms-Ratelimit-Remaining-Subscription-Writes": {"0"},
+				},
+				Body:    io.NopCloser(strings.NewReader("{}")),
+				Request: req,
+			}, nil
+		}
+		return fake.Do(req)
+	})
+	stats := sample.ConfigureRetries(policy.RetryOptions{MaxRetries: 2, RetryDelay: time.Millisecond})
+
+	if _, err := sample.CreateResourceGroup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []OperationRetries{{Operation: "PUT resourcegroups", Requests: 1, Retries: 1, Throttled: 1}}
+	if got := stats.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+	logged := "PUT resourcegroups: attempt 1 got 429 Too Many Requests (retry-after-ms: 1, x-ms-ratelimit-remaining-subscription-writes: 0); retrying."
+	if !strings.Contains(out.String(), logged) {
+		t.Errorf("the log does not contain %q:\n%s", logged, out.String())
+	}
+
+	var table bytes.Buffer
+	if err := stats.Print(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "PUT resourcegroups  1         1        1") {
+		t.Errorf("unexpected summary:\n%s", table.String())
+	}
+}
+
+func TestRetryStatsPrintNothingRetried(t *testing.T) {
+	stats := &RetryStats{ops: map[string]*OperationRetries{
+		"PUT resourcegroups": {Operation: "PUT resourcegroups", Requests: 1},
+	}}
+	var table bytes.Buffer
+	if err := stats.Print(&table); err != nil {
+		t.Fatal(err)
+	}
+	if table.Len() != 0 {
+		t.Errorf("Print() wrote %q, want nothing", table.String())
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{http.MethodPut, "/subscriptions/sub/resourcegroups/rg", "PUT resourcegroups"},
+		{http.MethodPut, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet", "PUT Microsoft.Network/virtualNetworks/subnets"},
+		{http.MethodGet, "/subscriptions/sub/providers/Microsoft.Network/locations/eastus/operations/id", "GET Microsoft.Network/locations/operations"},
+		{http.MethodPost, "/subscriptions/sub/providers/Microsoft.Storage/register", "POST Microsoft.Storage/register"},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, "https://management.azure.com"+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := operationName(req); got != test.want {
+			t.Errorf("operationName(%s %s) = %q, want %q", test.method, test.path, got, test.want)
+		}
+	}
+}
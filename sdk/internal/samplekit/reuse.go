@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+const (
+	// ReuseEnv names the environment variable that, when set to anything other
+	// than empty, runs the sample in reuse mode. The -reuse flag takes
+	// precedence over it.
+	ReuseEnv = "SAMPLE_REUSE"
+	// SoftDeletedEnv names the environment variable holding what reuse mode
+	// does with soft-deleted resources. The -soft-deleted flag takes precedence
+	// over it.
+	SoftDeletedEnv = "SAMPLE_SOFT_DELETED"
+)
+
+// What reuse mode does with a soft-deleted resource having the name of a
+// resource to create.
+const (
+	// RecoverSoftDeleted recovers the soft-deleted resource instead of
+	// creating a new one. It is the default.
+	RecoverSoftDeleted = "recover"
+	// PurgeSoftDeleted purges the soft-deleted resource, then creates a new one.
+	PurgeSoftDeleted = "purge"
+)
+
+// SoftDelete tells EnsureSoftDeleted how to find, recover and purge a
+// soft-deleted resource having the name of the resource to create.
+type SoftDelete[T any] struct {
+	// Find reports whether there is such a soft-deleted resource.
+	Find func(ctx context.Context) (bool, error)
+	// Recover recovers the soft-deleted resource and returns it.
+	Recover func(ctx context.Context) (T, error)
+	// Purge purges the soft-deleted resource for good.
+	Purge func(ctx context.Context) error
+}
+
+// Ensure returns the resource called name created by create. In reuse mode,
+// the resource is first looked up with get, and returned without calling
+// create if it exists already, so a sample run again with KEEP_RESOURCE set
+// does not create its resources again.
+func Ensure[T any](ctx context.Context, s *Sample, name string, get, create func(ctx context.Context) (T, error)) (T, error) {
+	return EnsureSoftDeleted(ctx, s, name, get, create, SoftDelete[T]{})
+}
+
+// EnsureSoftDeleted is Ensure for a resource that is soft-deleted when
+// deleted, such as a key vault or an API Management service. In reuse mode, a
+// soft-deleted resource found by sd.Find in place of the resource is recovered
+// or purged before creating the resource, as s.SoftDeleted says.
+func EnsureSoftDeleted[T any](ctx context.Context, s *Sample, name string, get, create func(ctx context.Context) (T, error), sd SoftDelete[T]) (T, error) {
+	if !s.Reuse {
+		return create(ctx)
+	}
+
+	existing, err := get(ctx)
+	if err == nil {
+		log.Printf("reusing %s.", name)
+		return existing, nil
+	}
+	if !NotFound(err) {
+		var zero T
+		return zero, fmt.Errorf("cannot check whether %s exists: %w", name, err)
+	}
+	if sd.Find == nil {
+		return create(ctx)
+	}
+
+	deleted, err := sd.Find(ctx)
+	if err != nil && !NotFound(err) {
+		var zero T
+		return zero, fmt.Errorf("cannot check whether %s is soft-deleted: %w", name, err)
+	}
+	if err != nil || !deleted {
+		return create(ctx)
+	}
+	switch s.SoftDeleted {
+	case PurgeSoftDeleted:
+		log.Printf("purging soft-deleted %s.", name)
+		if err := sd.Purge(ctx); err != nil {
+			var zero T
+			return zero, fmt.Errorf("cannot purge soft-deleted %s: %w", name, err)
+		}
+		return create(ctx)
+	default:
+		log.Printf("recovering soft-deleted %s.", name)
+		return sd.Recover(ctx)
+	}
+}
+
+// NotFound reports whether err is the response of Azure to a request for a
+// resource that does not exist.
+func NotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// checkSoftDeleted returns an error unless mode is what reuse mode can do with
+// soft-deleted resources.
+func checkSoftDeleted(mode string) error {
+	switch mode {
+	case "", RecoverSoftDeleted, PurgeSoftDeleted:
+		return nil
+	}
+	return fmt.Errorf("-soft-deleted must be %s or %s, not %q", RecoverSoftDeleted, PurgeSoftDeleted, mode)
+}
+
+// reuseSeed returns the seed of the unique names in reuse mode when none is
+// set, derived from the subscription and resource group name so a rerun gets
+// the same names, and the same group in another subscription does not.
+func reuseSeed(subscriptionID, resourceGroupName string) string {
+	h := fnv.New64a()
+	h.Write([]byte(subscriptionID + "/" + resourceGroupName))
+	return strconv.FormatInt(int64(h.Sum64()>>1), 10)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestEnsure(t *testing.T) {
+	notFound := &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: "ResourceNotFound"}
+	tests := []struct {
+		name        string
+		reuse       bool
+		softDeleted string
+		getErr      error
+		deleted     bool
+		want        string
+		wantCalls   []string
+		wantErr     string
+	}{
+		{name: "not reusing", want: "created", wantCalls: []string{"create"}},
+		{name: "exists", reuse: true, want: "existing", wantCalls: []string{"get"}},
+		{name: "missing", reuse: true, getErr: notFound, want: "created", wantCalls: []string{"get", "find", "create"}},
+		{name: "get fails", reuse: true, getErr: errors.New("forbidden"), wantCalls: []string{"get"}, wantErr: "cannot check whether vault v exists: forbidden"},
+		{name: "recover", reuse: true, getErr: notFound, deleted: true, want: "recovered", wantCalls: []string{"get", "find", "recover"}},
+		{name: "purge", reuse: true, softDeleted: PurgeSoftDeleted, getErr: notFound, deleted: true, want: "created", wantCalls: []string{"get", "find", "purge", "create"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			call := func(name, value string, err error) func(ctx context.Context) (string, error) {
+				return func(ctx context.Context) (string, error) {
+					calls = append(calls, name)
+					return value, err
+				}
+			}
+			sample := &Sample{Reuse: tt.reuse, SoftDeleted: tt.softDeleted}
+			got, err := EnsureSoftDeleted(context.Background(), sample, "vault v", call("get", "existing", tt.getErr), call("create", "created", nil), SoftDelete[string]{
+				Find: func(ctx context.Context) (bool, error) {
+					calls = append(calls, "find")
+					return tt.deleted, nil
+				},
+				Recover: call("recover", "recovered", nil),
+				Purge: func(ctx context.Context) error {
+					calls = append(calls, "purge")
+					return nil
+				},
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("called %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSettingsReuse(t *testing.T) {
+	t.Setenv(NameSeedEnv, "")
+	t.Setenv(SubscriptionIDEnv, "11111111-1111-1111-1111-111111111111")
+	load := func(args ...string) string {
+		vaultName := "sample-vault"
+		resourceGroupName := "sample-resource-group"
+		settings := Settings{
+			"vaultName":         Unique(&vaultName, KeyVault),
+			"resourceGroupName": &resourceGroupName,
+		}
+		if err := settings.Load(args); err != nil {
+			t.Fatal(err)
+		}
+		return vaultName
+	}
+
+	// a rerun in reuse mode gets the names of the previous run
+	first := load("-reuse")
+	if first == "sample-vault" {
+		t.Fatal("the vault name got no suffix")
+	}
+	if again := load("-reuse"); again != first {
+		t.Errorf("rerun vault name %q, want %q", again, first)
+	}
+	if other := load("-reuse", "-resource-group-name", "alice-rg"); other == first {
+		t.Errorf("another resource group got the same vault name %q", other)
+	}
+	t.Setenv(SubscriptionIDEnv, "22222222-2222-2222-2222-222222222222")
+	if other := load("-reuse"); other == first {
+		t.Errorf("the resource group of another subscription got the same vault name %q", other)
+	}
+
+	if err := (Settings{}).Load([]string{"-reuse", "-soft-deleted", "ignore"}); err == nil {
+		t.Error("expected an error for an unknown -soft-deleted")
+	}
+}
@@ -0,0 +1,483 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package samplekit holds the boilerplate shared by the resource manager samples:
+// reading the environment, building the credential and client options, and
+// creating and tearing down the sample's resource group.
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+const (
+	// SubscriptionIDEnv names the environment variable holding the subscription to run in.
+	SubscriptionIDEnv = "AZURE_SUBSCRIPTION_ID"
+	// KeepResourceEnv names the environment variable that, when set to anything
+	// other than empty, keeps the created resources after the sample finishes.
+	KeepResourceEnv = "KEEP_RESOURCE"
+)
+
+// placeholderID stands in for the subscription and other IDs a dry run or a
+// replay does not need, and for the IDs removed from recordings.
+const placeholderID = "00000000-0000-0000-0000-000000000000"
+
+// offline is set by Main when the sample runs without Azure, in dry-run or replay mode.
+var offline bool
+
+// Sample carries what every sample needs to talk to Azure Resource Manager.
+type Sample struct {
+	SubscriptionID    string
+	ResourceGroupName string
+	Location          string
+
+	// Cloud is the cloud the sample runs in. Its configuration is set in
+	// ClientOptions and in the credential.
+	Cloud Cloud
+
+	// TenantID and ObjectID identify the principal the sample signed in as;
+	// SignIn sets them, and they are placeholders offline.
+	TenantID string
+	ObjectID string
+
+	// Tags are applied to the resource group when it is created.
+	Tags map[string]*string
+
+	// RunID identifies the run. RunTags, set by Main, are added to Tags when
+	// the resource group is created.
+	RunID   string
+	RunTags map[string]*string
+
+	// KeepResource skips deleting the resource group in Cleanup.
+	KeepResource bool
+
+	// Reuse makes Ensure use the resources that already exist instead of
+	// creating them again. SoftDeleted is what it does with a soft-deleted
+	// resource: RecoverSoftDeleted, the default, or PurgeSoftDeleted.
+	Reuse       bool
+	SoftDeleted string
+
+	// RegisterProviders makes Preflight register the providers the sample
+	// needs that are not registered, instead of only logging them.
+	RegisterProviders bool
+
+	// Teardown holds the resources to delete in Cleanup. CreateResourceGroup
+	// records the resource group; samples record anything created outside it.
+	Teardown Teardown
+
+	// CleanupTimeout bounds the cleanup of Run; DefaultCleanupTimeout if zero.
+	CleanupTimeout time.Duration
+
+	// State saves the long-running operations in progress so they can be
+	// resumed; nil saves nothing.
+	State *State
+
+	Credential    azcore.TokenCredential
+	ClientOptions *arm.ClientOptions
+
+	// principal names who the sample signed in as, set by SignIn.
+	principal string
+
+	// nameSuffix is the random suffix of the unique names of the run, set by
+	// Main, which CheckTemplate normalizes.
+	nameSuffix string
+
+	// tracer starts the spans of the run, its steps and its cleanup; it
+	// starts none unless StartTracing was called.
+	tracer tracing.Tracer
+}
+
+// New returns a Sample for the resource group and location in the public
+// cloud, configured from the environment.
+func New(resourceGroupName, location string) (*Sample, error) {
+	return NewInCloud(resourceGroupName, location, PublicCloud, DefaultCredential)
+}
+
+// NewInCloud returns a Sample for the resource group and location in cloud c,
+// configured from the environment, signing in with the credential kinds as
+// NewCredential does.
+func NewInCloud(resourceGroupName, location string, c Cloud, kinds string) (*Sample, error) {
+	subscriptionID, err := Getenv(SubscriptionIDEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := NewCredential(kinds, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Cloud:             c,
+		KeepResource:      len(os.Getenv(KeepResourceEnv)) != 0,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: c.Configuration},
+		},
+	}, nil
+}
+
+// Main is the entry point of a sample. It applies the command line, environment
+// and config file overrides to settings, configures a Sample from them, runs fn
+// with it and cleans up. If anything failed, it logs the error with advice on
+// what to do about its Failure category, and exits with the category's exit code.
+//
+// In dry-run mode the sample's requests are printed instead of sent. In record
+// mode they are sent and saved to a cassette with Azure's responses, which
+// replay mode plays back instead of sending them. A JSON report and the
+// OpenTelemetry spans of the run can be written in any mode. Failed requests
+// are retried as the retry flags say, and a summary of the retried and
+// throttled operations ends the run. Every request is stamped with a client
+// request ID naming the run and the step, and can be logged with the request
+// and correlation IDs Azure answered with.
+//
+// Against Azure, the long-running operations waited for with PollUntilDone are
+// saved to a state file while in progress. In resume mode a run continues
+// waiting for the saved operations instead of beginning them again. In reuse
+// mode the resources created with Ensure are used if they exist already, in
+// the samples run with the WithReuse option.
+//
+// With a golden template, the template of the resource group is exported once
+// fn has created the resources, and compared with the golden one before the
+// cleanup.
+//
+// An interrupt cancels the sample, including any polling in progress, and the
+// resources created so far are cleaned up. A second interrupt quits without
+// waiting for the cleanup.
+func Main(settings Settings, fn func(ctx context.Context, s *Sample) error, options ...Option) {
+	var mainOpts mainOptions
+	for _, option := range options {
+		option(&mainOpts)
+	}
+	opts, err := settings.load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		exit(configError(err))
+	}
+	if opts.reuse && !mainOpts.reuse {
+		exit(configError(errors.New("this sample does not support -reuse: it creates its resources again on every run")))
+	}
+
+	var sample *Sample
+	var recorder *RecordingTransport
+	switch {
+	case opts.dryRun:
+		offline = true
+		sample = NewDryRun(settings.Get(ResourceGroupNameSetting), settings.Get(LocationSetting), os.Stdout)
+	case opts.cassette != nil:
+		offline = true
+		sample = NewReplay(settings.Get(ResourceGroupNameSetting), settings.Get(LocationSetting), opts.cassette)
+	default:
+		sample, err = NewInCloud(settings.Get(ResourceGroupNameSetting), settings.Get(LocationSetting), opts.cloud, opts.credential)
+		if err != nil {
+			exit(configError(err))
+		}
+		// before Record, so the recording removes the IDs of the principal
+		if err := sample.SignIn(context.Background()); err != nil {
+			exit(err)
+		}
+		if opts.record != "" {
+			recorder = sample.Record()
+		}
+		sample.State = opts.resumeState
+		if sample.State == nil {
+			if _, err := os.Stat(opts.state); err == nil {
+				log.Printf("%s holds operations of an earlier run; run with -resume to continue them, or they are forgotten.", opts.state)
+			}
+			sample.State = NewState(opts.state)
+			sample.State.NameSeed = opts.nameSeed
+		}
+	}
+
+	// offline, the requests still go to the endpoints of the cloud
+	sample.Cloud = opts.cloud
+	sample.ClientOptions.Cloud = opts.cloud.Configuration
+	sample.RunID = NewRunID()
+	sample.RunTags = NewRunTags(sample.RunID, samplePath(), sample.creator(), time.Now().Add(opts.expiresAfter))
+	if opts.cassette != nil {
+		// the replay sends the recorded tags
+		sample.RunTags = opts.cassette.RunTags
+		if id := sample.RunTags[RunIDTag]; id != nil {
+			sample.RunID = *id
+		}
+	}
+	log.Printf("run %s.", sample.RunID)
+	sample.StampRequests()
+	var requestLog *os.File
+	if opts.requestLog != "" {
+		if requestLog, err = os.Create(opts.requestLog); err != nil {
+			exit(configError(err))
+		}
+		if err := sample.LogRequests(requestLog); err != nil {
+			exit(configError(err))
+		}
+	}
+	sample.CleanupTimeout = opts.cleanupTimeout
+	operationTimeout = opts.operationTimeout
+	parallel = opts.parallel
+	sample.Reuse = opts.reuse
+	sample.SoftDeleted = opts.softDeleted
+	sample.RegisterProviders = opts.registerProviders
+	if opts.nameSeed != "" {
+		seed, _ := strconv.ParseInt(opts.nameSeed, 10, 64)
+		sample.nameSuffix = RandomSuffix(seed)
+	}
+	if opts.goldenTemplate != "" {
+		fn = checkingTemplate(fn, opts.goldenTemplate, opts.updateGolden)
+	}
+	retries := sample.ConfigureRetries(opts.retry)
+	var shutdownTracing func(context.Context) error
+	if opts.trace != "" {
+		exporter, err := NewTraceExporter(context.Background(), opts.trace, os.Stdout)
+		if err != nil {
+			exit(configError(err))
+		}
+		shutdownTracing = sample.StartTracing(exporter)
+	}
+	// the reporter gets the spans before the exporter
+	var reporter *Reporter
+	if opts.report != "" {
+		reporter = sample.StartReport()
+	}
+
+	// the first interrupt cancels the sample and lets Run clean up; the second one quits at once
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err = sample.Run(ctx, fn)
+	if player, ok := sample.ClientOptions.Transport.(*ReplayTransport); ok && player.Unplayed() > 0 {
+		log.Printf("%d recorded interactions were not played back.", player.Unplayed())
+	}
+	if recorder != nil {
+		cassette := recorder.Cassette()
+		cassette.NameSeed = opts.nameSeed
+		cassette.RunTags = sample.RunTags
+		if saveErr := cassette.Save(opts.record); saveErr != nil {
+			log.Printf("cannot save cassette: %v", saveErr)
+		} else {
+			log.Printf("recorded %d interactions to %s.", len(cassette.Interactions), opts.record)
+		}
+	}
+	if shutdownTracing != nil {
+		if traceErr := shutdownTracing(context.Background()); traceErr != nil {
+			log.Printf("cannot export the spans: %v", traceErr)
+		}
+	}
+	if requestLog != nil {
+		if closeErr := requestLog.Close(); closeErr != nil {
+			log.Printf("cannot write the request log: %v", closeErr)
+		} else {
+			log.Printf("logged the requests to %s.", opts.requestLog)
+		}
+	}
+	if printErr := retries.Print(os.Stderr); printErr != nil {
+		log.Printf("cannot print the retries: %v", printErr)
+	}
+	if reporter != nil {
+		if saveErr := reporter.Report(err).Save(opts.report); saveErr != nil {
+			log.Printf("cannot save run report: %v", saveErr)
+		}
+	}
+	if err != nil {
+		exit(err)
+	}
+}
+
+// Option changes how Main runs a sample.
+type Option func(*mainOptions)
+
+// mainOptions holds what the Options passed to Main set.
+type mainOptions struct {
+	reuse bool
+}
+
+// WithReuse tells Main the sample supports reuse mode, creating with Ensure
+// the resources that take long to create. Main rejects -reuse in the other
+// samples.
+func WithReuse() Option {
+	return func(o *mainOptions) { o.reuse = true }
+}
+
+// exit logs err, with what to do about it when its category tells, and exits
+// with the exit code of the category.
+func exit(err error) {
+	failure := Classify(err)
+	log.Print(err)
+	if advice := failure.Advice(); advice != "" {
+		log.Printf("%s: %s.", failure, advice)
+	}
+	os.Exit(failure.ExitCode())
+}
+
+// NewDryRun returns a Sample whose requests are printed to out instead of being
+// sent to Azure. It needs neither a subscription nor a sign-in.
+func NewDryRun(resourceGroupName, location string, out io.Writer) *Sample {
+	return newOffline(resourceGroupName, location, NewDryRunTransport(out))
+}
+
+// NewReplay returns a Sample whose requests are answered from cassette instead
+// of being sent to Azure. It needs neither a subscription nor a sign-in.
+func NewReplay(resourceGroupName, location string, cassette *Cassette) *Sample {
+	sample := newOffline(resourceGroupName, location, nil)
+	sample.ClientOptions.Transport = NewReplayTransport(cassette, sample.sanitizer())
+	return sample
+}
+
+// Record makes the sample record its requests and Azure's responses, sanitized,
+// and returns the transport holding the recording.
+func (s *Sample) Record() *RecordingTransport {
+	if s.ClientOptions == nil {
+		s.ClientOptions = &arm.ClientOptions{}
+	}
+	next := s.ClientOptions.Transport
+	if next == nil {
+		next = http.DefaultClient
+	}
+	recorder := NewRecordingTransport(next, s.sanitizer())
+	s.ClientOptions.Transport = recorder
+	return recorder
+}
+
+// sanitizer returns a Sanitizer removing the sample's subscription and the IDs
+// the samples read from the environment.
+func (s *Sample) sanitizer() *Sanitizer {
+	return NewSanitizer(s.SubscriptionID, s.TenantID, s.ObjectID, os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_OBJECT_ID"))
+}
+
+// newOffline returns a Sample whose requests go to transport instead of Azure.
+// AZURE_SUBSCRIPTION_ID is used when set, a placeholder otherwise.
+func newOffline(resourceGroupName, location string, transport policy.Transporter) *Sample {
+	subscriptionID := os.Getenv(SubscriptionIDEnv)
+	if len(subscriptionID) == 0 {
+		subscriptionID = placeholderID
+	}
+
+	return &Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Cloud:             PublicCloud,
+		TenantID:          placeholderID,
+		ObjectID:          placeholderID,
+		KeepResource:      len(os.Getenv(KeepResourceEnv)) != 0,
+		Credential:        offlineCredential{},
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: transport,
+			},
+		},
+	}
+}
+
+// offlineCredential hands out a token that is never checked, so running offline needs no sign-in.
+type offlineCredential struct{}
+
+func (offlineCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "offline", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// Getenv returns the value of the environment variable name, or an error if it is not set.
+// In dry-run and replay mode a placeholder is returned instead of the error.
+func Getenv(name string) (string, error) {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		if offline {
+			log.Printf("%s is not set; using a placeholder as the sample runs offline.", name)
+			return placeholderID, nil
+		}
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	return value, nil
+}
+
+// ResourceGroupsClient returns a client for the sample's subscription.
+func (s *Sample) ResourceGroupsClient() (*armresources.ResourceGroupsClient, error) {
+	return armresources.NewResourceGroupsClient(s.SubscriptionID, s.Credential, s.ClientOptions)
+}
+
+// CreateResourceGroup creates the sample's resource group, or updates it if it already exists.
+func (s *Sample) CreateResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
+	client, err := s.ResourceGroupsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.CreateOrUpdate(
+		ctx,
+		s.ResourceGroupName,
+		armresources.ResourceGroup{
+			Location: to.Ptr(s.Location),
+			Tags:     s.resourceGroupTags(),
+		},
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	s.Teardown.Add("resource group "+s.ResourceGroupName, s.DeleteResourceGroup)
+	return &resp.ResourceGroup, nil
+}
+
+// DeleteResourceGroup deletes the sample's resource group and waits for the deletion to finish.
+func (s *Sample) DeleteResourceGroup(ctx context.Context) error {
+	client, err := s.ResourceGroupsClient()
+	if err != nil {
+		return err
+	}
+
+	pollerResp, err := client.BeginDelete(ctx, s.ResourceGroupName, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = Wait(ctx, "delete resource group "+s.ResourceGroupName, pollerResp, nil)
+	return err
+}
+
+// Cleanup deletes the resources recorded in Teardown, most recent first,
+// unless KeepResource is set.
+func (s *Sample) Cleanup(ctx context.Context) error {
+	if s.Teardown.Len() == 0 {
+		return nil
+	}
+	if s.KeepResource {
+		log.Printf("keeping resource group %s because %s is set.", s.ResourceGroupName, KeepResourceEnv)
+		return nil
+	}
+
+	if err := s.Teardown.Run(ctx); err != nil {
+		return err
+	}
+	// the operations in progress were deleted with their resources
+	if err := s.State.Clear(); err != nil {
+		log.Printf("cannot clear the state: %v", err)
+	}
+	log.Println("cleaned up successfully.")
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// newFakeSample returns a Sample whose resource group calls are served by srv.
+func newFakeSample(srv *resourcesfake.ResourceGroupsServer) *Sample {
+	return &Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: "sample-resource-group",
+		Location:          "westus",
+		Credential:        &azfake.TokenCredential{},
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewResourceGroupsServerTransport(srv),
+			},
+		},
+	}
+}
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("SAMPLEKIT_TEST_VALUE", "value")
+	value, err := Getenv("SAMPLEKIT_TEST_VALUE")
+	if err != nil || value != "value" {
+		t.Fatalf("Getenv() = %q, %v", value, err)
+	}
+
+	t.Setenv("SAMPLEKIT_TEST_VALUE", "")
+	if _, err := Getenv("SAMPLEKIT_TEST_VALUE"); err == nil {
+		t.Fatal("expected an error for an empty variable")
+	}
+}
+
+func TestOfflineGetenv(t *testing.T) {
+	offline = true
+	t.Cleanup(func() { offline = false })
+	t.Setenv("SAMPLEKIT_TEST_VALUE", "")
+	value, err := Getenv("SAMPLEKIT_TEST_VALUE")
+	if err != nil || value != placeholderID {
+		t.Fatalf("Getenv() = %q, %v, want the placeholder", value, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(SubscriptionIDEnv, "")
+	if _, err := New("sample-resource-group", "westus"); err == nil {
+		t.Fatal("expected an error without a subscription")
+	}
+
+	t.Setenv(SubscriptionIDEnv, subscriptionID)
+	t.Setenv(KeepResourceEnv, "1")
+	sample, err := New("sample-resource-group", "westus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.SubscriptionID != subscriptionID || !sample.KeepResource || sample.ClientOptions == nil {
+		t.Fatalf("unexpected sample: %+v", sample)
+	}
+}
+
+func TestCreateResourceGroup(t *testing.T) {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			if parameters.Location == nil || *parameters.Location != "westus" {
+				t.Errorf("unexpected location: %v", parameters.Location)
+			}
+			if parameters.Tags == nil || *parameters.Tags["owner"] != "samples" {
+				t.Errorf("unexpected tags: %v", parameters.Tags)
+			}
+			resp.SetResponse(http.StatusCreated, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{
+					ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+					Name: to.Ptr(resourceGroupName),
+				},
+			}, nil)
+			return
+		},
+	})
+	sample.Tags = map[string]*string{"owner": to.Ptr("samples")}
+
+	resourceGroup, err := sample.CreateResourceGroup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *resourceGroup.Name != "sample-resource-group" {
+		t.Fatalf("unexpected resource group: %s", *resourceGroup.Name)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	deleted := 0
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			deleted++
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	})
+	sample.Teardown.Add("resource group", sample.DeleteResourceGroup)
+
+	sample.KeepResource = true
+	if err := sample.Cleanup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Fatal("resource group deleted although KeepResource is set")
+	}
+
+	sample.KeepResource = false
+	if err := sample.Cleanup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected one deletion, got %d", deleted)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	// StateEnv names the environment variable holding the path of the state
+	// file. The -state flag takes precedence over it.
+	StateEnv = "SAMPLE_STATE"
+	// ResumeEnv names the environment variable that, when set to anything other
+	// than empty, resumes the operations saved in the state file. The -resume
+	// flag takes precedence over it.
+	ResumeEnv = "SAMPLE_RESUME"
+	// DefaultStateFile is the state file used when neither -state nor
+	// SAMPLE_STATE is set, relative to the working directory.
+	DefaultStateFile = ".sample-state.json"
+)
+
+// State holds the long-running operations a sample is waiting for, saved to a
+// file so that a run whose process died can be resumed. The file is removed
+// once no operation is in progress.
+type State struct {
+	// NameSeed is the seed of the run's unique names, so a resumed run uses the same names.
+	NameSeed string `json:"nameSeed,omitempty"`
+	// ResumeTokens maps the name of each operation in progress to the resume token of its poller.
+	ResumeTokens map[string]string `json:"resumeTokens"`
+
+	mu   sync.Mutex
+	path string
+}
+
+// NewState returns an empty State saved to path.
+func NewState(path string) *State {
+	return &State{ResumeTokens: map[string]string{}, path: path}
+}
+
+// LoadState reads the State saved to path.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	st := NewState(path)
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	if st.ResumeTokens == nil {
+		st.ResumeTokens = map[string]string{}
+	}
+	return st, nil
+}
+
+// ResumeToken returns the saved resume token of the operation called name, or
+// "" if there is none. A nil State has none.
+func (st *State) ResumeToken(name string) string {
+	if st == nil {
+		return ""
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.ResumeTokens[name]
+}
+
+// SetResumeToken saves the resume token of the operation called name, or
+// forgets the operation if token is "". It does nothing on a nil State.
+func (st *State) SetResumeToken(name, token string) error {
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if token == "" {
+		if _, ok := st.ResumeTokens[name]; !ok {
+			return nil
+		}
+		delete(st.ResumeTokens, name)
+	} else {
+		st.ResumeTokens[name] = token
+	}
+	return st.save()
+}
+
+// Clear forgets every operation and removes the state file.
+func (st *State) Clear() error {
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.ResumeTokens = map[string]string{}
+	return st.save()
+}
+
+// save writes the state to its file, or removes the file if no operation is in
+// progress. The caller holds st.mu.
+func (st *State) save() error {
+	if len(st.ResumeTokens) == 0 {
+		if err := os.Remove(st.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(st.path, append(data, '\n'), 0o600)
+}
+
+// PollUntilDone begins the long-running operation called name by calling begin,
+// and waits for it to finish with Wait. While the operation is in progress its resume
+// token is saved in the sample's State, so that a resumed run continues waiting
+// for it instead of beginning it again.
+//
+// begin passes resumeToken in the ResumeToken option of the Begin method. It is
+// "" unless the operation is resumed, in which case the Begin method sends no
+// request and only rebuilds the poller.
+func PollUntilDone[T any](ctx context.Context, s *Sample, name string, begin func(resumeToken string) (*runtime.Poller[T], error)) (T, error) {
+	var zero T
+	resumeToken := s.State.ResumeToken(name)
+	if resumeToken != "" {
+		log.Printf("resuming %s.", name)
+	}
+	poller, err := begin(resumeToken)
+	if err != nil {
+		return zero, err
+	}
+	if !poller.Done() {
+		// a poller that is not done always has a token
+		if token, err := poller.ResumeToken(); err == nil {
+			if err := s.State.SetResumeToken(name, token); err != nil {
+				log.Printf("cannot save the state of %s: %v", name, err)
+			}
+		}
+	}
+
+	resp, err := Wait(ctx, name, poller, nil)
+	var respErr *azcore.ResponseError
+	if err == nil || errors.As(err, &respErr) {
+		// the operation is over, whether it succeeded or not; after any other
+		// error, such as an interrupt, it can still be resumed
+		if err := s.State.SetResumeToken(name, ""); err != nil {
+			log.Printf("cannot save the state of %s: %v", name, err)
+		}
+	}
+	return resp, err
+}
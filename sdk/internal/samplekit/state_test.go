@@ -0,0 +1,90 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestPollUntilDoneResumes(t *testing.T) {
+	begun := 0
+	srv := resourcesfake.ResourceGroupsServer{
+		BeginDelete: func(ctx context.Context, name string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+			begun++
+			resp.AddNonTerminalResponse(http.StatusAccepted, nil)
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+			return
+		},
+	}
+	sample := newFakeSample(&srv)
+	fake := sample.ClientOptions.Transport
+	sample.ClientOptions.Transport = transportFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := fake.Do(req)
+		if err == nil {
+			// poll the fake server without waiting
+			resp.Header.Set("Retry-After-Ms", "1")
+		}
+		return resp, err
+	})
+	path := filepath.Join(t.TempDir(), "state.json")
+	sample.State = NewState(path)
+	sample.State.NameSeed = "seed"
+	client, err := sample.ResourceGroupsClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleteGroup := func(ctx context.Context, afterBegin func()) error {
+		_, err := PollUntilDone(ctx, sample, "resource group deletion", func(resumeToken string) (*runtime.Poller[armresources.ResourceGroupsClientDeleteResponse], error) {
+			poller, err := client.BeginDelete(ctx, sample.ResourceGroupName, &armresources.ResourceGroupsClientBeginDeleteOptions{ResumeToken: resumeToken})
+			afterBegin()
+			return poller, err
+		})
+		return err
+	}
+
+	// the process is interrupted while polling
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := deleteGroup(ctx, cancel); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	saved, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.NameSeed != "seed" || saved.ResumeToken("resource group deletion") == "" {
+		t.Fatalf("unexpected state %+v", saved)
+	}
+
+	// a resumed run polls the same operation
+	sample.State = saved
+	if err := deleteGroup(context.Background(), func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if begun != 1 {
+		t.Errorf("the operation was begun %d times, want once", begun)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the state file was not removed: %v", err)
+	}
+}
+
+func TestNilState(t *testing.T) {
+	var st *State
+	if err := st.SetResumeToken("operation", "token"); err != nil {
+		t.Fatal(err)
+	}
+	if token := st.ResumeToken("operation"); token != "" {
+		t.Errorf("got token %q from a nil State", token)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// The tags Main puts on the resource group of a run, so the groups a crashed
+// run left behind can be found and swept.
+const (
+	RunIDTag      = "sample-run-id"
+	SamplePathTag = "sample-path"
+	CreatedByTag  = "sample-created-by"
+	// ExpiresTag holds the time after which the group can be deleted, in RFC 3339 format.
+	ExpiresTag = "sample-expires"
+)
+
+// DefaultExpiresAfter is how long after a run its resource group expires.
+const DefaultExpiresAfter = 24 * time.Hour
+
+// NewRunID returns a random ID for a run, formatted as a UUID.
+func NewRunID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// NewRunTags returns the tags identifying the run runID of the sample at
+// samplePath, created by createdBy and expiring at expires.
+func NewRunTags(runID, samplePath, createdBy string, expires time.Time) map[string]*string {
+	return map[string]*string{
+		RunIDTag:      to.Ptr(runID),
+		SamplePathTag: to.Ptr(samplePath),
+		CreatedByTag:  to.Ptr(createdBy),
+		ExpiresTag:    to.Ptr(expires.UTC().Format(time.RFC3339)),
+	}
+}
+
+// Expired reports whether the resource group tags say it expired before now.
+// Groups without a valid ExpiresTag never expire.
+func Expired(tags map[string]*string, now time.Time) bool {
+	value, ok := tags[ExpiresTag]
+	if !ok || value == nil {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, *value)
+	return err == nil && expires.Before(now)
+}
+
+// resourceGroupTags returns Tags with RunTags added.
+func (s *Sample) resourceGroupTags() map[string]*string {
+	if len(s.RunTags) == 0 {
+		return s.Tags
+	}
+	tags := make(map[string]*string, len(s.Tags)+len(s.RunTags))
+	for name, value := range s.Tags {
+		tags[name] = value
+	}
+	for name, value := range s.RunTags {
+		tags[name] = value
+	}
+	return tags
+}
+
+// samplePath returns the path of the running sample under sdk/resourcemanager,
+// such as "compute/create_vm", or its module path when it runs elsewhere.
+func samplePath() string {
+	if wd, err := os.Getwd(); err == nil {
+		wd = filepath.ToSlash(wd)
+		const dir = "/sdk/resourcemanager/"
+		if i := strings.LastIndex(wd, dir); i >= 0 {
+			return wd[i+len(dir):]
+		}
+	}
+	return sampleName()
+}
+
+// creator names who runs the sample: the principal it signed in as, else the
+// local user.
+func (s *Sample) creator() string {
+	if s.principal != "" {
+		return s.principal
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestCreateResourceGroupTags(t *testing.T) {
+	var tags map[string]*string
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		CreateOrUpdate: func(ctx context.Context, name string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+			tags = parameters.Tags
+			resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+				ResourceGroup: armresources.ResourceGroup{ID: to.Ptr("/subscriptions/" + subscriptionID + "/resourceGroups/" + name)},
+			}, nil)
+			return
+		},
+	})
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	sample.Tags = map[string]*string{"owner": to.Ptr("alice")}
+	sample.RunTags = NewRunTags("run", "compute/create_vm", "alice@example.com", expires)
+	if _, err := sample.CreateResourceGroup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"owner":       "alice",
+		RunIDTag:      "run",
+		SamplePathTag: "compute/create_vm",
+		CreatedByTag:  "alice@example.com",
+		ExpiresTag:    "2030-01-02T03:04:05Z",
+	}
+	if len(tags) != len(want) {
+		t.Errorf("got %d tags, want %d", len(tags), len(want))
+	}
+	for name, value := range want {
+		if tags[name] == nil || *tags[name] != value {
+			t.Errorf("tag %s = %v, want %q", name, tags[name], value)
+		}
+	}
+	if len(sample.Tags) != 1 {
+		t.Errorf("the run tags were added to sample.Tags: %v", sample.Tags)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		tags map[string]*string
+		want bool
+	}{
+		{NewRunTags("run", "path", "alice", now.Add(-time.Minute)), true},
+		{NewRunTags("run", "path", "alice", now.Add(time.Minute)), false},
+		{map[string]*string{ExpiresTag: to.Ptr("tomorrow")}, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := Expired(test.tags, now); got != test.want {
+			t.Errorf("Expired(%v) = %t, want %t", test.tags[ExpiresTag], got, test.want)
+		}
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	id := NewRunID()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("NewRunID() = %q, not a version 4 UUID", id)
+	}
+	if id == NewRunID() {
+		t.Error("NewRunID() returned the same ID twice")
+	}
+}
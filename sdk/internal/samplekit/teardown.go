@@ -0,0 +1,205 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+)
+
+// Teardown records how to delete the resources a sample creates, so they can be
+// deleted in reverse order of creation however the sample ends. The resources
+// recorded by a Graph are deleted in the reverse order of the graph, those
+// that do not depend on each other at the same time.
+type Teardown struct {
+	mu    sync.Mutex
+	steps []teardownStep
+}
+
+type teardownStep struct {
+	name   string
+	delete func(ctx context.Context) error
+	// after indexes the steps deleted after this one, the resources it depends on.
+	after []int
+}
+
+// Add records that the resource called name was created and is deleted by
+// calling fn, before any resource recorded earlier.
+func (t *Teardown) Add(name string, fn func(ctx context.Context) error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	after := make([]int, len(t.steps))
+	for i := range after {
+		after[i] = i
+	}
+	t.steps = append(t.steps, teardownStep{name: name, delete: fn, after: after})
+}
+
+// add records like Add the resource called name, to delete before the
+// resources at the indexes after only, and returns its index.
+func (t *Teardown) add(name string, fn func(ctx context.Context) error, after []int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps = append(t.steps, teardownStep{name: name, delete: fn, after: after})
+	return len(t.steps) - 1
+}
+
+// indexes returns the indexes of the resources recorded so far.
+func (t *Teardown) indexes() []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	indexes := make([]int, len(t.steps))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// Len returns the number of resources waiting to be deleted.
+func (t *Teardown) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.steps)
+}
+
+// Run deletes the recorded resources, each one after the resources that
+// depend on it, at most DefaultParallel at the same time. A failed deletion
+// does not stop the others; all failures are returned together, most
+// recently created resource first. The recorded resources are forgotten once
+// Run returns.
+func (t *Teardown) Run(ctx context.Context) error {
+	t.mu.Lock()
+	steps := t.steps
+	t.steps = nil
+	t.mu.Unlock()
+
+	// a resource waits for the deletion of the resources depending on it
+	waits := make([][]int, len(steps))
+	for i, step := range steps {
+		for _, j := range step.after {
+			waits[j] = append(waits[j], i)
+		}
+	}
+	results := runGraph(ctx, waits, DefaultParallel, false, func(ctx context.Context, i int) error {
+		step := steps[i]
+		log.Printf("deleting %s...", step.name)
+		if err := step.delete(withStepName(ctx, "delete "+step.name)); err != nil {
+			return fmt.Errorf("cannot delete %s: %w", step.name, err)
+		}
+		log.Printf("deleted %s", step.name)
+		return nil
+	})
+
+	var errs []error
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i] != nil {
+			errs = append(errs, results[i])
+		}
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors returns nil for no errors, the error itself for one, and an error
+// listing all of them otherwise.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return joinedErrors(errs)
+}
+
+// joinedErrors is the error of joinErrors.
+type joinedErrors []error
+
+func (e joinedErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is and As look through every error, as for errors.Join since Go 1.20.
+func (e joinedErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e joinedErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCleanupTimeout bounds the cleanup of Run when Sample.CleanupTimeout is zero.
+const DefaultCleanupTimeout = 30 * time.Minute
+
+// Run calls fn and then cleans up everything fn created, whether fn returns
+// normally, returns an error or panics. A panic is returned as an error.
+//
+// The cleanup gets a fresh context bounded by CleanupTimeout, so it still runs
+// when ctx has been canceled, for example by an interrupt.
+func (s *Sample) Run(ctx context.Context, fn func(ctx context.Context, s *Sample) error) (err error) {
+	ctx, span := s.tracer.Start(ctx, sampleName(), nil)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+
+		timeout := s.CleanupTimeout
+		if timeout == 0 {
+			timeout = DefaultCleanupTimeout
+		}
+		if ctx.Err() != nil && s.Teardown.Len() > 0 {
+			log.Printf("%v; cleaning up within %s.", ctx.Err(), timeout)
+		}
+		// the cleanup is part of the run's trace, but not canceled with it
+		cleanupCtx, cancel := context.WithTimeout(withoutCancel{ctx}, timeout)
+		defer cancel()
+		cleanupCtx, cleanupSpan := s.tracer.Start(cleanupCtx, "cleanup", nil)
+		if cleanupErr := s.Cleanup(cleanupCtx); cleanupErr != nil {
+			cleanupSpan.SetStatus(tracing.SpanStatusError, cleanupErr.Error())
+			if err == nil {
+				err = cleanupErr
+			} else {
+				err = fmt.Errorf("%w; cleanup failed: %v", err, cleanupErr)
+			}
+		}
+		cleanupSpan.End()
+
+		if err != nil {
+			span.SetStatus(tracing.SpanStatusError, err.Error())
+		}
+		span.End()
+	}()
+	return fn(ctx, s)
+}
+
+// withoutCancel is a context with the values of its parent, but not its
+// deadline or cancellation.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (withoutCancel) Done() <-chan struct{}       { return nil }
+func (withoutCancel) Err() error                  { return nil }
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeardownRunsInReverse(t *testing.T) {
+	var td Teardown
+	var deleted []string
+	for _, name := range []string{"vnet", "nic", "vm"} {
+		name := name
+		td.Add(name, func(ctx context.Context) error {
+			deleted = append(deleted, name)
+			if name == "nic" {
+				return errors.New("still in use")
+			}
+			return nil
+		})
+	}
+
+	err := td.Run(context.Background())
+	if want := []string{"vm", "nic", "vnet"}; !reflect.DeepEqual(deleted, want) {
+		t.Fatalf("deleted %v, want %v", deleted, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "cannot delete nic: still in use") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if td.Len() != 0 {
+		t.Fatalf("expected teardown to be emptied, %d left", td.Len())
+	}
+}
+
+func TestRunCleansUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		keep    bool
+		fn      func(ctx context.Context, s *Sample) error
+		wantErr string
+		deleted bool
+	}{
+		{
+			name:    "success",
+			fn:      func(ctx context.Context, s *Sample) error { return nil },
+			deleted: true,
+		},
+		{
+			name:    "error",
+			fn:      func(ctx context.Context, s *Sample) error { return errors.New("create failed") },
+			wantErr: "create failed",
+			deleted: true,
+		},
+		{
+			name:    "panic",
+			fn:      func(ctx context.Context, s *Sample) error { panic("boom") },
+			wantErr: "panic: boom",
+			deleted: true,
+		},
+		{
+			name:    "keep resource",
+			keep:    true,
+			fn:      func(ctx context.Context, s *Sample) error { return errors.New("create failed") },
+			wantErr: "create failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			sample := &Sample{ResourceGroupName: "sample-resource-group", KeepResource: tt.keep}
+			err := sample.Run(context.Background(), func(ctx context.Context, s *Sample) error {
+				s.Teardown.Add("resource", func(ctx context.Context) error {
+					deleted = true
+					return nil
+				})
+				return tt.fn(ctx, s)
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if deleted != tt.deleted {
+				t.Fatalf("deleted = %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
+
+func TestRunCleansUpAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sample := &Sample{ResourceGroupName: "sample-resource-group", CleanupTimeout: time.Minute}
+	var cleanupErr error
+	var deadline time.Time
+	err := sample.Run(ctx, func(ctx context.Context, s *Sample) error {
+		s.Teardown.Add("resource", func(ctx context.Context) error {
+			cleanupErr = ctx.Err()
+			deadline, _ = ctx.Deadline()
+			return nil
+		})
+		// an interrupt while polling
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if cleanupErr != nil {
+		t.Fatalf("cleanup got a done context: %v", cleanupErr)
+	}
+	if left := time.Until(deadline); left <= 0 || left > time.Minute {
+		t.Fatalf("cleanup deadline in %s, want within a minute", left)
+	}
+}
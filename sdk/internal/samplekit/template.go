@@ -0,0 +1,250 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+const (
+	// GoldenTemplateEnv names the environment variable holding the path of
+	// the golden template to compare the exported template with. The
+	// -golden-template flag takes precedence over it.
+	GoldenTemplateEnv = "SAMPLE_GOLDEN_TEMPLATE"
+	// UpdateGoldenEnv names the environment variable that, when set to
+	// anything other than empty, writes the golden template instead of
+	// comparing with it.
+	UpdateGoldenEnv = "SAMPLE_UPDATE_GOLDEN"
+
+	// ResourceGroupPlaceholder, LocationPlaceholder and SuffixPlaceholder
+	// stand in for the resource group name, the location and the random
+	// suffix of unique names in normalized templates.
+	ResourceGroupPlaceholder = "{resourceGroupName}"
+	LocationPlaceholder      = "{location}"
+	SuffixPlaceholder        = "{suffix}"
+
+	// GoldenTemplateFile is where a sample keeps its golden template,
+	// relative to its directory. The samples command compares the template
+	// of the samples that have one with it.
+	GoldenTemplateFile = "testdata/template.golden.json"
+)
+
+// placeholderTime stands in for the timestamps of normalized templates.
+const placeholderTime = "0001-01-01T00:00:00Z"
+
+var (
+	guidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	timePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`)
+)
+
+// volatileProperties are the template properties that change from one run to
+// the next whatever their value, and are removed by NormalizeTemplate.
+var volatileProperties = map[string]bool{
+	"etag":              true,
+	"ipAddress":         true,
+	"provisioningState": true,
+	"resourceGuid":      true,
+	"uniqueId":          true,
+}
+
+// ExportTemplate exports the template of the sample's resource group, with
+// every resource in it.
+func (s *Sample) ExportTemplate(ctx context.Context) (any, error) {
+	client, err := s.ResourceGroupsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	pollerResp, err := client.BeginExportTemplate(
+		ctx,
+		s.ResourceGroupName,
+		armresources.ExportTemplateRequest{
+			Resources: []*string{to.Ptr("*")},
+		},
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := Wait(ctx, "export template of "+s.ResourceGroupName, pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Template, nil
+}
+
+// NormalizeTemplate returns template as indented JSON with what changes from
+// one run to the next normalized, so templates of different runs compare
+// equal: GUIDs, such as the subscription in resource IDs, are replaced by a
+// placeholder ID, timestamps by the zero time, and the resource group name and
+// the suffix of unique names by ResourceGroupPlaceholder and
+// SuffixPlaceholder. A value that is the location becomes LocationPlaceholder,
+// so a sample run in another location matches. Etags, provisioning states and
+// the other volatileProperties are removed. The keys of objects are sorted.
+func NormalizeTemplate(template any, resourceGroupName, location, suffix string) ([]byte, error) {
+	// a round trip turns typed templates into maps and slices
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var replacements []string
+	if resourceGroupName != "" {
+		replacements = append(replacements, resourceGroupName, ResourceGroupPlaceholder)
+	}
+	if suffix != "" {
+		replacements = append(replacements, suffix, SuffixPlaceholder)
+	}
+	replacer := strings.NewReplacer(replacements...)
+	normalize := func(s string) string {
+		// only whole values, as a location such as westus is part of others
+		if location != "" && strings.EqualFold(s, location) {
+			return LocationPlaceholder
+		}
+		s = guidPattern.ReplaceAllString(s, placeholderID)
+		s = timePattern.ReplaceAllString(s, placeholderTime)
+		return replacer.Replace(s)
+	}
+	data, err = json.MarshalIndent(normalizeValue(v, normalize), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func normalizeValue(v any, normalize func(string) string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if volatileProperties[key] {
+				continue
+			}
+			// parameter names are made from the resource names
+			out[normalize(key)] = normalizeValue(value, normalize)
+		}
+		return out
+	case []any:
+		for i, value := range v {
+			v[i] = normalizeValue(value, normalize)
+		}
+		return v
+	case string:
+		return normalize(v)
+	}
+	return v
+}
+
+// CheckTemplate exports the template of the sample's resource group,
+// normalizes it with NormalizeTemplate and compares it with the golden
+// template at path. If they differ, it returns an error showing the lines
+// that differ. If update is set, it writes the template to path instead.
+func (s *Sample) CheckTemplate(ctx context.Context, path string, update bool) error {
+	template, err := Step(ctx, s, "export template", s.ExportTemplate)
+	if err != nil {
+		return fmt.Errorf("cannot export the template of %s: %w", s.ResourceGroupName, err)
+	}
+	got, err := NormalizeTemplate(template, s.ResourceGroupName, s.Location, s.nameSuffix)
+	if err != nil {
+		return fmt.Errorf("cannot normalize the template of %s: %w", s.ResourceGroupName, err)
+	}
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			return err
+		}
+		log.Printf("wrote the template of %s to %s.", s.ResourceGroupName, path)
+		return nil
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("golden template %s does not exist; run with -update-golden to write it", path)
+	}
+	if err != nil {
+		return err
+	}
+	if diff := diffLines(string(want), string(got)); diff != "" {
+		return fmt.Errorf("the template of %s differs from %s (-golden +exported); run with -update-golden if the change is expected:\n%s", s.ResourceGroupName, path, diff)
+	}
+	log.Printf("the template of %s matches %s.", s.ResourceGroupName, path)
+	return nil
+}
+
+// checkingTemplate returns fn followed, if it succeeds, by CheckTemplate, so
+// the template is compared after the create phase and before the cleanup.
+func checkingTemplate(fn func(ctx context.Context, s *Sample) error, path string, update bool) func(ctx context.Context, s *Sample) error {
+	return func(ctx context.Context, s *Sample) error {
+		if err := fn(ctx, s); err != nil {
+			return err
+		}
+		return s.CheckTemplate(ctx, path, update)
+	}
+}
+
+// diffLines returns the lines that differ between a and b, or "" if they are
+// equal. Each line is prefixed with its number, in a for those only in a,
+// followed by "-", and in b for those only in b, followed by "+".
+func diffLines(a, b string) string {
+	if a == b {
+		return ""
+	}
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&out, "%d: -%s\n", i+1, x[i])
+			i++
+		default:
+			fmt.Fprintf(&out, "%d: +%s\n", j+1, y[j])
+			j++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package samplekit
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+// newExportSample returns a Sample in resourceGroupName, whose unique names end
+// with suffix, exporting a template with a storage account of the SKU sku
+// created at created.
+func newExportSample(resourceGroupName, suffix, subscription, created, sku string) *Sample {
+	sample := newFakeSample(&resourcesfake.ResourceGroupsServer{
+		BeginExportTemplate: func(ctx context.Context, name string, parameters armresources.ExportTemplateRequest, options *armresources.ResourceGroupsClientBeginExportTemplateOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientExportTemplateResponse], errResp azfake.ErrorResponder) {
+			account := "sample" + suffix
+			resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientExportTemplateResponse{
+				ResourceGroupExportResult: armresources.ResourceGroupExportResult{Template: map[string]any{
+					"parameters": map[string]any{
+						"storageAccounts_" + account + "_name": map[string]any{"defaultValue": account, "type": "String"},
+					},
+					"resources": []any{map[string]any{
+						"type":     "Microsoft.Storage/storageAccounts",
+						"name":     "[parameters('storageAccounts_" + account + "_name')]",
+						"etag":     `W/"` + created + `"`,
+						"sku":      map[string]any{"name": sku},
+						"identity": map[string]any{"principalId": subscription},
+						"properties": map[string]any{
+							"creationTime":      created,
+							"provisioningState": "Succeeded",
+							"subnetId":          "/subscriptions/" + subscription + "/resourceGroups/" + name + "/providers/Microsoft.Network/virtualNetworks/vnet/subnets/default",
+						},
+					}},
+				}},
+			}, nil)
+			return
+		},
+	})
+	sample.ResourceGroupName = resourceGroupName
+	sample.nameSuffix = suffix
+	return sample
+}
+
+func TestCheckTemplate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "testdata", "template.json")
+
+	err := newExportSample("rg-a", "abc123", "11111111-2222-3333-4444-555555555555", "2024-01-02T03:04:05.1234567Z", "Standard_LRS").CheckTemplate(ctx, path, false)
+	if err == nil || !strings.Contains(err.Error(), "-update-golden") {
+		t.Fatalf("got error %v, want the golden template to be missing", err)
+	}
+
+	if err := newExportSample("rg-a", "abc123", "11111111-2222-3333-4444-555555555555", "2024-01-02T03:04:05.1234567Z", "Standard_LRS").CheckTemplate(ctx, path, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// another run, with other names, IDs and times
+	if err := newExportSample("rg-b", "xyz789", "66666666-7777-8888-9999-000000000000", "2025-06-07T08:09:10Z", "Standard_LRS").CheckTemplate(ctx, path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	err = newExportSample("rg-b", "xyz789", "66666666-7777-8888-9999-000000000000", "2025-06-07T08:09:10Z", "Standard_GRS").CheckTemplate(ctx, path, false)
+	if err == nil || !strings.Contains(err.Error(), `-        "name": "Standard_LRS"`) || !strings.Contains(err.Error(), `+        "name": "Standard_GRS"`) {
+		t.Fatalf("got error %v, want the SKU to differ", err)
+	}
+}
+
+func TestNormalizeTemplate(t *testing.T) {
+	template := map[string]any{
+		"resources": []any{map[string]any{
+			"name":     "sample-rg-vault-abc123",
+			"etag":     "0x8D",
+			"location": "westus2",
+			"id":       "/subscriptions/11111111-2222-3333-4444-555555555555/resourceGroups/sample-rg",
+			"tags":     map[string]any{"sample-expires": "2024-01-02T03:04:05+01:00"},
+			// a public IP address gets another address on every run
+			"properties": map[string]any{"ipAddress": "20.1.2.3", "publicIPAllocationMethod": "Static"},
+		}},
+	}
+	got, err := NormalizeTemplate(template, "sample-rg", "WestUS2", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "resources": [
+    {
+      "id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/{resourceGroupName}",
+      "location": "{location}",
+      "name": "{resourceGroupName}-vault-{suffix}",
+      "properties": {
+        "publicIPAllocationMethod": "Static"
+      },
+      "tags": {
+        "sample-expires": "0001-01-01T00:00:00Z"
+      }
+    }
+  ]
+}
+`
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	if diff := diffLines("a\nb\n", "a\nb\n"); diff != "" {
+		t.Errorf("equal texts differ:\n%s", diff)
+	}
+	want := "2: -b\n3: +x\n"
+	if diff := diffLines("a\nb\nc\n", "a\nc\nx\n"); diff != want {
+		t.Errorf("got\n%s\nwant\n%s", diff, want)
+	}
+}
@@ -6,119 +6,119 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 )
 
 var (
-	subscriptionID    string
-	location          = "westus"
-	resourceGroupName = "sample-resource-group"
-	serviceName       = "sample-api-service"
+	location                    = "westus"
+	resourceGroupName           = "sample-resource-group"
+	serviceName                 = "sample-api-service"
+	apiManagementServiceSKUName = string(armapimanagement.SKUTypeStandard)
 )
 
 var (
 	apimanagementClientFactory *armapimanagement.ClientFactory
-	resourcesClientFactory     *armresources.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	serviceClient       *armapimanagement.ServiceClient
+	serviceClient         *armapimanagement.ServiceClient
+	deletedServicesClient *armapimanagement.DeletedServicesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                    &location,
+		"resourceGroupName":           &resourceGroupName,
+		"serviceName":                 samplekit.Unique(&serviceName, samplekit.APIManagement),
+		"apiManagementServiceSKUName": &apiManagementServiceSKUName,
+	}, run, samplekit.WithReuse())
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	apimanagementClientFactory, err = armapimanagement.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	serviceClient = apimanagementClientFactory.NewServiceClient()
+	deletedServicesClient = apimanagementClientFactory.NewDeletedServicesClient()
 
-	apimanagementClientFactory, err = armapimanagement.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ApiManagement"}}); err != nil {
+		return err
 	}
-	serviceClient = apimanagementClientFactory.NewServiceClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	// if happen soft-delete please use delete_service sample to delete
-	apiManagementService, err := createApiManagementService(ctx)
+	// in reuse mode, the service of a previous run is used, or restored if soft-deleted
+	apiManagementService, err := samplekit.EnsureSoftDeleted(ctx, sample, "API Management service "+serviceName, getApiManagementService, func(ctx context.Context) (*armapimanagement.ServiceResource, error) {
+		return createApiManagementService(ctx, sample, false)
+	}, samplekit.SoftDelete[*armapimanagement.ServiceResource]{
+		Find: findDeletedService,
+		Recover: func(ctx context.Context) (*armapimanagement.ServiceResource, error) {
+			return createApiManagementService(ctx, sample, true)
+		},
+		Purge: purgeDeletedService,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("api management service:", *apiManagementService.ID)
 
 	apiManagementService, err = getApiManagementService(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get api management service:", *apiManagementService.ID)
 
 	ssoToken, err := getSsoToken(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("ssoToken:", *ssoToken.RedirectURI)
 
 	domainOwnershipIdentifier, err := getDomainOwnershipIdentifier(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("domain owner ship Identifier:", *domainOwnershipIdentifier.DomainOwnershipIdentifier)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
-func createApiManagementService(ctx context.Context) (*armapimanagement.ServiceResource, error) {
-
-	pollerResp, err := serviceClient.BeginCreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		serviceName,
-		armapimanagement.ServiceResource{
-			Location: to.Ptr(location),
-			Properties: &armapimanagement.ServiceProperties{
-				PublisherName:  to.Ptr("sample"),
-				PublisherEmail: to.Ptr("xxx@wircesoft.com"),
+// createApiManagementService creates the service, or with restore, restores
+// the soft-deleted service of the same name.
+func createApiManagementService(ctx context.Context, sample *samplekit.Sample, restore bool) (*armapimanagement.ServiceResource, error) {
+
+	resp, err := samplekit.PollUntilDone(ctx, sample, "API Management service "+serviceName, func(resumeToken string) (*runtime.Poller[armapimanagement.ServiceClientCreateOrUpdateResponse], error) {
+		return serviceClient.BeginCreateOrUpdate(
+			ctx,
+			resourceGroupName,
+			serviceName,
+			armapimanagement.ServiceResource{
+				Location: to.Ptr(location),
+				Properties: &armapimanagement.ServiceProperties{
+					PublisherName:  to.Ptr("sample"),
+					PublisherEmail: to.Ptr("xxx@wircesoft.com"),
+					Restore:        restoreFlag(restore),
+				},
+				SKU: &armapimanagement.ServiceSKUProperties{
+					Name:     to.Ptr(armapimanagement.SKUType(apiManagementServiceSKUName)),
+					Capacity: to.Ptr[int32](2),
+				},
 			},
-			SKU: &armapimanagement.ServiceSKUProperties{
-				Name:     to.Ptr(armapimanagement.SKUTypeStandard),
-				Capacity: to.Ptr[int32](2),
-			},
-		},
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+			&armapimanagement.ServiceClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -153,31 +153,30 @@ func getApiManagementService(ctx context.Context) (*armapimanagement.ServiceReso
 	return &resp.ServiceResource, nil
 }
 
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
+// restoreFlag returns the Restore property of a service to create, left out
+// unless restoring.
+func restoreFlag(restore bool) *bool {
+	if !restore {
+		return nil
 	}
-	return &resourceGroupResp.ResourceGroup, nil
+	return to.Ptr(true)
 }
 
-func cleanup(ctx context.Context) error {
+func findDeletedService(ctx context.Context) (bool, error) {
 
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
+	_, err := deletedServicesClient.GetByName(ctx, serviceName, location, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
+	return true, nil
+}
+
+func purgeDeletedService(ctx context.Context) error {
 
-	_, err = pollerResp.PollUntilDone(ctx, nil)
+	pollerResp, err := deletedServicesClient.BeginPurge(ctx, serviceName, location, nil)
 	if err != nil {
 		return err
 	}
-	return nil
+	_, err = samplekit.Wait(ctx, "purge deleted API Management service", pollerResp, nil)
+	return err
 }
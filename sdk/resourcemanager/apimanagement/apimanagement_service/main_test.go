@@ -0,0 +1,149 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	apimanagementfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestApimanagementService(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	apimanagementServer := apimanagementfake.ServerFactory{
+		ServiceServer: apimanagementfake.ServiceServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, parameters armapimanagement.ServiceResource, options *armapimanagement.ServiceClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armapimanagement.ServiceClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armapimanagement.ServiceClientCreateOrUpdateResponse{
+					ServiceResource: armapimanagement.ServiceResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s", subscriptionID, resourceGroupName, serviceName)),
+						Name: to.Ptr(serviceName),
+					},
+				}, nil)
+				return
+			},
+			GetDomainOwnershipIdentifier: func(ctx context.Context, options *armapimanagement.ServiceClientGetDomainOwnershipIdentifierOptions) (resp azfake.Responder[armapimanagement.ServiceClientGetDomainOwnershipIdentifierResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.ServiceClientGetDomainOwnershipIdentifierResponse{
+					ServiceGetDomainOwnershipIdentifierResult: armapimanagement.ServiceGetDomainOwnershipIdentifierResult{
+						DomainOwnershipIdentifier: to.Ptr("sample-domain-ownership-identifier"),
+					},
+				}, nil)
+				return
+			},
+			GetSsoToken: func(ctx context.Context, resourceGroupName string, serviceName string, options *armapimanagement.ServiceClientGetSsoTokenOptions) (resp azfake.Responder[armapimanagement.ServiceClientGetSsoTokenResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.ServiceClientGetSsoTokenResponse{
+					ServiceGetSsoTokenResult: armapimanagement.ServiceGetSsoTokenResult{
+						RedirectURI: to.Ptr(fmt.Sprintf("https://%s.portal.azure-api.net/signin-sso?token=sample-token", serviceName)),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, serviceName string, options *armapimanagement.ServiceClientGetOptions) (resp azfake.Responder[armapimanagement.ServiceClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.ServiceClientGetResponse{
+					ServiceResource: armapimanagement.ServiceResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s", subscriptionID, resourceGroupName, serviceName)),
+						Name: to.Ptr(serviceName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	apimanagementClientFactory, err = armapimanagement.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: apimanagementfake.NewServerFactoryTransport(&apimanagementServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceClient = apimanagementClientFactory.NewServiceClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	// if happen soft-delete please use delete_service sample to delete
+	apiManagementService, err := createApiManagementService(ctx, sample, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("api management service:", *apiManagementService.ID)
+
+	apiManagementService, err = getApiManagementService(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get api management service:", *apiManagementService.ID)
+
+	ssoToken, err := getSsoToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("ssoToken:", *ssoToken.RedirectURI)
+
+	domainOwnershipIdentifier, err := getDomainOwnershipIdentifier(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("domain owner ship Identifier:", *domainOwnershipIdentifier.DomainOwnershipIdentifier)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
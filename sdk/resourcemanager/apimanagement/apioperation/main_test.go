@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	apimanagementfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestApioperation(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	apimanagementServer := apimanagementfake.ServerFactory{
+		ServiceServer: apimanagementfake.ServiceServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, parameters armapimanagement.ServiceResource, options *armapimanagement.ServiceClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armapimanagement.ServiceClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armapimanagement.ServiceClientCreateOrUpdateResponse{
+					ServiceResource: armapimanagement.ServiceResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s", subscriptionID, resourceGroupName, serviceName)),
+						Name: to.Ptr(serviceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		APIServer: apimanagementfake.APIServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, apiID string, parameters armapimanagement.APICreateOrUpdateParameter, options *armapimanagement.APIClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armapimanagement.APIClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armapimanagement.APIClientCreateOrUpdateResponse{
+					APIContract: armapimanagement.APIContract{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s/apis/%s", subscriptionID, resourceGroupName, serviceName, apiID)),
+						Name: to.Ptr(apiID),
+					},
+				}, nil)
+				return
+			},
+		},
+		APIOperationServer: apimanagementfake.APIOperationServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, apiID string, operationID string, parameters armapimanagement.OperationContract, options *armapimanagement.APIOperationClientCreateOrUpdateOptions) (resp azfake.Responder[armapimanagement.APIOperationClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.APIOperationClientCreateOrUpdateResponse{
+					OperationContract: armapimanagement.OperationContract{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s/apis/%s/operations/%s", subscriptionID, resourceGroupName, serviceName, apiID, operationID)),
+						Name: to.Ptr(operationID),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	apimanagementClientFactory, err = armapimanagement.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: apimanagementfake.NewServerFactoryTransport(&apimanagementServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceClient = apimanagementClientFactory.NewServiceClient()
+	apiClient = apimanagementClientFactory.NewAPIClient()
+	apiOperationClient = apimanagementClientFactory.NewAPIOperationClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	apiManagementService, err := createApiManagementService(ctx, sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("api management service:", *apiManagementService.ID)
+
+	api, err := createApi(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("api:", *api.ID)
+
+	apiOperation, err := createApiOperation(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("api operation:", *apiOperation.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,118 +5,107 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
-	location          = "westus"
-	resourceGroupName = "sample-resource-group"
-	serviceName       = "sample-api-service"
-	apiID             = "sample-api"
-	releaseID         = "sample-api-release"
+	location                    = "westus"
+	resourceGroupName           = "sample-resource-group"
+	serviceName                 = "sample-api-service"
+	apiID                       = "sample-api"
+	releaseID                   = "sample-api-release"
+	apiManagementServiceSKUName = string(armapimanagement.SKUTypeStandard)
 )
 
 var (
 	apimanagementClientFactory *armapimanagement.ClientFactory
-	resourcesClientFactory     *armresources.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	serviceClient       *armapimanagement.ServiceClient
-	apiClient           *armapimanagement.APIClient
-	apiReleaseClient    *armapimanagement.APIReleaseClient
+	serviceClient    *armapimanagement.ServiceClient
+	apiClient        *armapimanagement.APIClient
+	apiReleaseClient *armapimanagement.APIReleaseClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":                    &location,
+		"resourceGroupName":           &resourceGroupName,
+		"serviceName":                 samplekit.Unique(&serviceName, samplekit.APIManagement),
+		"apiID":                       &apiID,
+		"releaseID":                   &releaseID,
+		"apiManagementServiceSKUName": &apiManagementServiceSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	apimanagementClientFactory, err = armapimanagement.NewClientFactory(subscriptionID, cred, nil)
+	apimanagementClientFactory, err = armapimanagement.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serviceClient = apimanagementClientFactory.NewServiceClient()
 	apiClient = apimanagementClientFactory.NewAPIClient()
 	apiReleaseClient = apimanagementClientFactory.NewAPIReleaseClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ApiManagement"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	apiManagementService, err := createApiManagementService(ctx)
+	apiManagementService, err := createApiManagementService(ctx, sample)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("api management service:", *apiManagementService.ID)
 
 	api, err := createApi(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("api:", *api.ID)
 
 	apiRelease, err := createApiRelease(ctx, *api.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("api release:", *apiRelease.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
-func createApiManagementService(ctx context.Context) (*armapimanagement.ServiceResource, error) {
-
-	pollerResp, err := serviceClient.BeginCreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		serviceName,
-		armapimanagement.ServiceResource{
-			Location: to.Ptr(location),
-			Properties: &armapimanagement.ServiceProperties{
-				PublisherName:  to.Ptr("sample"),
-				PublisherEmail: to.Ptr("xxx@wircesoft.com"),
-			},
-			SKU: &armapimanagement.ServiceSKUProperties{
-				Name:     to.Ptr(armapimanagement.SKUTypeStandard),
-				Capacity: to.Ptr[int32](2),
+func createApiManagementService(ctx context.Context, sample *samplekit.Sample) (*armapimanagement.ServiceResource, error) {
+
+	resp, err := samplekit.PollUntilDone(ctx, sample, "API Management service "+serviceName, func(resumeToken string) (*runtime.Poller[armapimanagement.ServiceClientCreateOrUpdateResponse], error) {
+		return serviceClient.BeginCreateOrUpdate(
+			ctx,
+			resourceGroupName,
+			serviceName,
+			armapimanagement.ServiceResource{
+				Location: to.Ptr(location),
+				Properties: &armapimanagement.ServiceProperties{
+					PublisherName:  to.Ptr("sample"),
+					PublisherEmail: to.Ptr("xxx@wircesoft.com"),
+				},
+				SKU: &armapimanagement.ServiceSKUProperties{
+					Name:     to.Ptr(armapimanagement.SKUType(apiManagementServiceSKUName)),
+					Capacity: to.Ptr[int32](2),
+				},
 			},
-		},
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+			&armapimanagement.ServiceClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +134,7 @@ func createApi(ctx context.Context) (*armapimanagement.APIContract, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create API", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -173,32 +162,3 @@ func createApiRelease(ctx context.Context, apiId string) (*armapimanagement.APIR
 	}
 	return &resp.APIReleaseContract, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
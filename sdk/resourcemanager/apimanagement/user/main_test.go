@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2"
+	apimanagementfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestUser(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	apimanagementServer := apimanagementfake.ServerFactory{
+		ServiceServer: apimanagementfake.ServiceServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, parameters armapimanagement.ServiceResource, options *armapimanagement.ServiceClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armapimanagement.ServiceClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armapimanagement.ServiceClientCreateOrUpdateResponse{
+					ServiceResource: armapimanagement.ServiceResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s", subscriptionID, resourceGroupName, serviceName)),
+						Name: to.Ptr(serviceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		UserServer: apimanagementfake.UserServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, userID string, parameters armapimanagement.UserCreateParameters, options *armapimanagement.UserClientCreateOrUpdateOptions) (resp azfake.Responder[armapimanagement.UserClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.UserClientCreateOrUpdateResponse{
+					UserContract: armapimanagement.UserContract{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s/users/%s", subscriptionID, resourceGroupName, serviceName, userID)),
+						Name: to.Ptr(userID),
+					},
+				}, nil)
+				return
+			},
+			GetEntityTag: func(ctx context.Context, resourceGroupName string, serviceName string, userID string, options *armapimanagement.UserClientGetEntityTagOptions) (resp azfake.Responder[armapimanagement.UserClientGetEntityTagResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.UserClientGetEntityTagResponse{
+					ETag:    to.Ptr("\"AAAAAAAAAAA=\""),
+					Success: true,
+				}, nil)
+				return
+			},
+			GetSharedAccessToken: func(ctx context.Context, resourceGroupName string, serviceName string, userID string, parameters armapimanagement.UserTokenParameters, options *armapimanagement.UserClientGetSharedAccessTokenOptions) (resp azfake.Responder[armapimanagement.UserClientGetSharedAccessTokenResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.UserClientGetSharedAccessTokenResponse{
+					UserTokenResult: armapimanagement.UserTokenResult{
+						Value: to.Ptr("sample-shared-access-token"),
+					},
+				}, nil)
+				return
+			},
+			GenerateSsoURL: func(ctx context.Context, resourceGroupName string, serviceName string, userID string, options *armapimanagement.UserClientGenerateSsoURLOptions) (resp azfake.Responder[armapimanagement.UserClientGenerateSsoURLResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armapimanagement.UserClientGenerateSsoURLResponse{
+					GenerateSsoURLResult: armapimanagement.GenerateSsoURLResult{
+						Value: to.Ptr(fmt.Sprintf("https://%s.portal.azure-api.net/signin-sso?token=sample-token", serviceName)),
+					},
+				}, nil)
+				return
+			},
+			NewListByServicePager: func(resourceGroupName string, serviceName string, options *armapimanagement.UserClientListByServiceOptions) (resp azfake.PagerResponder[armapimanagement.UserClientListByServiceResponse]) {
+				resp.AddPage(http.StatusOK, armapimanagement.UserClientListByServiceResponse{
+					UserCollection: armapimanagement.UserCollection{
+						Value: []*armapimanagement.UserContract{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ApiManagement/service/%s/users/%s", subscriptionID, resourceGroupName, serviceName, userID)),
+								Name: to.Ptr(userID),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	apimanagementClientFactory, err = armapimanagement.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: apimanagementfake.NewServerFactoryTransport(&apimanagementServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceClient = apimanagementClientFactory.NewServiceClient()
+	userClient = apimanagementClientFactory.NewUserClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	apiManagementService, err := createApiManagementService(ctx, sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("api management service:", *apiManagementService.ID)
+
+	user, err := createUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("user:", *user.ID)
+
+	entityTag, err := getEntityTag(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("entity tag:", *entityTag.ETag, entityTag.Success)
+
+	sharedAccessToken, err := getSharedAccessToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("shared access token:", *sharedAccessToken.Value)
+
+	generateSsoUrl, err := generateSsoURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("generate Sso URL:", *generateSsoUrl.Value)
+
+	users, err := listUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range users {
+		t.Logf("user name:%s,user id:%s\n", *u.Name, *u.ID)
+	}
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
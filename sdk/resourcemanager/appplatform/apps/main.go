@@ -6,102 +6,91 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appplatform/armappplatform"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID    string
-	location          = "westus"
-	resourceGroupName = "sample-resource-group"
-	serviceName       = "sample-spring-cloud"
-	appName           = "sample-app"
+	location                  = "westus"
+	resourceGroupName         = "sample-resource-group"
+	serviceName               = "sample-spring-cloud"
+	appName                   = "sample-app"
+	springCloudServiceSKUName = "S0"
 )
 
 var (
-	resourcesClientFactory   *armresources.ClientFactory
 	appplatformClientFactory *armappplatform.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	servicesClient      *armappplatform.ServicesClient
-	appsClient          *armappplatform.AppsClient
+	servicesClient *armappplatform.ServicesClient
+	appsClient     *armappplatform.AppsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                  &location,
+		"resourceGroupName":         &resourceGroupName,
+		"serviceName":               samplekit.Unique(&serviceName, samplekit.SpringApps),
+		"appName":                   &appName,
+		"springCloudServiceSKUName": &springCloudServiceSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	appplatformClientFactory, err = armappplatform.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	appplatformClientFactory, err = armappplatform.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	servicesClient = appplatformClientFactory.NewServicesClient()
 	appsClient = appplatformClientFactory.NewAppsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.AppPlatform"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	service, err := createSpringCloudService(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("app platform service:", *service.ID)
 
 	app, err := createAPP(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("spring cloud app:", *app.ID)
 
 	app, err = getAppResource(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get spring cloud app:", *app.ID)
 
 	uploadURL, err := getAppResourceUploadURL(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("app resource upload url:", *uploadURL.RelativePath, *uploadURL.UploadURL)
 
 	domain, err := validateDomain(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("validate domain:", *domain.IsValid, *domain.Message)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createSpringCloudService(ctx context.Context) (*armappplatform.ServiceResource, error) {
@@ -113,7 +102,7 @@ func createSpringCloudService(ctx context.Context) (*armappplatform.ServiceResou
 		armappplatform.ServiceResource{
 			Location: to.Ptr(location),
 			SKU: &armappplatform.SKU{
-				Name: to.Ptr("S0"),
+				Name: to.Ptr(springCloudServiceSKUName),
 				Tier: to.Ptr("Standard"),
 			},
 		},
@@ -123,7 +112,7 @@ func createSpringCloudService(ctx context.Context) (*armappplatform.ServiceResou
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create Spring Cloud service", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +147,7 @@ func createAPP(ctx context.Context) (*armappplatform.AppResource, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create app", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -204,33 +193,3 @@ func validateDomain(ctx context.Context) (*armappplatform.CustomDomainValidateRe
 
 	return &resp.CustomDomainValidateResult, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
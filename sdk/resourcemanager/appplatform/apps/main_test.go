@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appplatform/armappplatform"
+	appplatformfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appplatform/armappplatform/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestApps(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	appplatformServer := appplatformfake.ServerFactory{
+		ServicesServer: appplatformfake.ServicesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, resource armappplatform.ServiceResource, options *armappplatform.ServicesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armappplatform.ServicesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if resource.Location == nil || *resource.Location != location {
+					t.Errorf("unexpected location: %v", resource.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armappplatform.ServicesClientCreateOrUpdateResponse{
+					ServiceResource: armappplatform.ServiceResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.AppPlatform/Spring/%s", subscriptionID, resourceGroupName, serviceName)),
+						Name: to.Ptr(serviceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		AppsServer: appplatformfake.AppsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serviceName string, appName string, appResource armappplatform.AppResource, options *armappplatform.AppsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armappplatform.AppsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armappplatform.AppsClientCreateOrUpdateResponse{
+					AppResource: armappplatform.AppResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.AppPlatform/Spring/%s/apps/%s", subscriptionID, resourceGroupName, serviceName, appName)),
+						Name: to.Ptr(appName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, serviceName string, appName string, options *armappplatform.AppsClientGetOptions) (resp azfake.Responder[armappplatform.AppsClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappplatform.AppsClientGetResponse{
+					AppResource: armappplatform.AppResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.AppPlatform/Spring/%s/apps/%s", subscriptionID, resourceGroupName, serviceName, appName)),
+						Name: to.Ptr(appName),
+					},
+				}, nil)
+				return
+			},
+			GetResourceUploadURL: func(ctx context.Context, resourceGroupName string, serviceName string, appName string, options *armappplatform.AppsClientGetResourceUploadURLOptions) (resp azfake.Responder[armappplatform.AppsClientGetResourceUploadURLResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappplatform.AppsClientGetResourceUploadURLResponse{
+					ResourceUploadDefinition: armappplatform.ResourceUploadDefinition{
+						RelativePath: to.Ptr("resources/sample-app/sample.jar"),
+						UploadURL:    to.Ptr("https://sample.file.core.windows.net/resources/sample-app/sample.jar"),
+					},
+				}, nil)
+				return
+			},
+			ValidateDomain: func(ctx context.Context, resourceGroupName string, serviceName string, appName string, validatePayload armappplatform.CustomDomainValidatePayload, options *armappplatform.AppsClientValidateDomainOptions) (resp azfake.Responder[armappplatform.AppsClientValidateDomainResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappplatform.AppsClientValidateDomainResponse{
+					CustomDomainValidateResult: armappplatform.CustomDomainValidateResult{
+						IsValid: to.Ptr(true),
+						Message: to.Ptr("sample-message"),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	appplatformClientFactory, err = armappplatform.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: appplatformfake.NewServerFactoryTransport(&appplatformServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	servicesClient = appplatformClientFactory.NewServicesClient()
+	appsClient = appplatformClientFactory.NewAppsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	service, err := createSpringCloudService(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("app platform service:", *service.ID)
+
+	app, err := createAPP(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("spring cloud app:", *app.ID)
+
+	app, err = getAppResource(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get spring cloud app:", *app.ID)
+
+	uploadURL, err := getAppResourceUploadURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("app resource upload url:", *uploadURL.RelativePath, *uploadURL.UploadURL)
+
+	domain, err := validateDomain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("validate domain:", *domain.IsValid, *domain.Message)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,118 +5,109 @@ package main
 
 import (
 	"context"
+	"errors"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus2"
 	resourceGroupName = "sample-resource-group"
 	staticSiteName    = "sample-static-site"
+	staticSiteSKUName = "Free"
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	appserviceClientFactory *armappservice.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	staticSitesClient   *armappservice.StaticSitesClient
+	staticSitesClient *armappservice.StaticSitesClient
 )
 
-// replace your repo information
-var repoURL = "" // https://github.com/<github-name>/azure-rest-api-specs
+// replace your repo information, or set it with -repo-url and -repo-token (SAMPLE_REPO_URL and SAMPLE_REPO_TOKEN)
+var repoURL = ""   // https://github.com/<github-name>/azure-rest-api-specs
 var repoToken = "" // github token https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/creating-a-personal-access-token
 func main() {
-	if repoToken == "" {
-		log.Fatal("Please input repo information.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"staticSiteName":    &staticSiteName,
+		"repoURL":           &repoURL,
+		"repoToken":         &repoToken,
+		"staticSiteSKUName": &staticSiteSKUName,
+	}, run)
+}
 
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	if repoToken == "" {
+		return errors.New("Please input repo information.")
 	}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	appserviceClientFactory, err = armappservice.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	staticSitesClient = appserviceClientFactory.NewStaticSitesClient()
 
-	appserviceClientFactory, err = armappservice.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Web"}}); err != nil {
+		return err
 	}
-	staticSitesClient = appserviceClientFactory.NewStaticSitesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	staticSite, err := createStaticSite(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("static site:", *staticSite.ID)
 
 	staticSite, err = getStaticSite(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get static site:", *staticSite.ID)
 
 	listFunctions, err := listStaticSiteFunctions(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("list static site functions:", len(listFunctions))
 
 	list, err := listStaticSite(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("list static site:", len(list))
 
 	listCustimDomain, err := listStaticSiteCustomDomain(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("list static site custom domain:", len(listCustimDomain))
 
 	err = resetStaticSiteApiKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("reset static site api key")
 
 	err = detachStaticSite(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("detached static site")
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createStaticSite(ctx context.Context) (*armappservice.StaticSiteARMResource, error) {
@@ -128,7 +119,7 @@ func createStaticSite(ctx context.Context) (*armappservice.StaticSiteARMResource
 		armappservice.StaticSiteARMResource{
 			Location: to.Ptr(location),
 			SKU: &armappservice.SKUDescription{
-				Name: to.Ptr("Free"),
+				Name: to.Ptr(staticSiteSKUName),
 			},
 			Properties: &armappservice.StaticSite{
 				RepositoryURL:   to.Ptr(repoURL),
@@ -145,7 +136,7 @@ func createStaticSite(ctx context.Context) (*armappservice.StaticSiteARMResource
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create static site", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,36 +220,7 @@ func detachStaticSite(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "detach static site", pollerResp, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	appservicefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestStaticsite(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	appserviceServer := appservicefake.ServerFactory{
+		StaticSitesServer: appservicefake.StaticSitesServer{
+			BeginCreateOrUpdateStaticSite: func(ctx context.Context, resourceGroupName string, name string, staticSiteEnvelope armappservice.StaticSiteARMResource, options *armappservice.StaticSitesClientBeginCreateOrUpdateStaticSiteOptions) (resp azfake.PollerResponder[armappservice.StaticSitesClientCreateOrUpdateStaticSiteResponse], errResp azfake.ErrorResponder) {
+				if staticSiteEnvelope.Location == nil || *staticSiteEnvelope.Location != location {
+					t.Errorf("unexpected location: %v", staticSiteEnvelope.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armappservice.StaticSitesClientCreateOrUpdateStaticSiteResponse{
+					StaticSiteARMResource: armappservice.StaticSiteARMResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/staticSites/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+			NewListStaticSiteFunctionsPager: func(resourceGroupName string, name string, options *armappservice.StaticSitesClientListStaticSiteFunctionsOptions) (resp azfake.PagerResponder[armappservice.StaticSitesClientListStaticSiteFunctionsResponse]) {
+				resp.AddPage(http.StatusOK, armappservice.StaticSitesClientListStaticSiteFunctionsResponse{
+					StaticSiteFunctionOverviewCollection: armappservice.StaticSiteFunctionOverviewCollection{
+						Value: []*armappservice.StaticSiteFunctionOverviewARMResource{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/staticSites/%s/functions/%s", subscriptionID, resourceGroupName, name, "sample-function")),
+								Name: to.Ptr("sample-function"),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armappservice.StaticSitesClientListOptions) (resp azfake.PagerResponder[armappservice.StaticSitesClientListResponse]) {
+				resp.AddPage(http.StatusOK, armappservice.StaticSitesClientListResponse{
+					StaticSiteCollection: armappservice.StaticSiteCollection{
+						Value: []*armappservice.StaticSiteARMResource{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Web/staticSites/%s", subscriptionID, staticSiteName)),
+								Name: to.Ptr(staticSiteName),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			NewListStaticSiteCustomDomainsPager: func(resourceGroupName string, name string, options *armappservice.StaticSitesClientListStaticSiteCustomDomainsOptions) (resp azfake.PagerResponder[armappservice.StaticSitesClientListStaticSiteCustomDomainsResponse]) {
+				resp.AddPage(http.StatusOK, armappservice.StaticSitesClientListStaticSiteCustomDomainsResponse{
+					StaticSiteCustomDomainOverviewCollection: armappservice.StaticSiteCustomDomainOverviewCollection{
+						Value: []*armappservice.StaticSiteCustomDomainOverviewARMResource{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/staticSites/%s/customDomains/%s", subscriptionID, resourceGroupName, name, "www.contoso.com")),
+								Name: to.Ptr("www.contoso.com"),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			GetStaticSite: func(ctx context.Context, resourceGroupName string, name string, options *armappservice.StaticSitesClientGetStaticSiteOptions) (resp azfake.Responder[armappservice.StaticSitesClientGetStaticSiteResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappservice.StaticSitesClientGetStaticSiteResponse{
+					StaticSiteARMResource: armappservice.StaticSiteARMResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/staticSites/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+			ResetStaticSiteAPIKey: func(ctx context.Context, resourceGroupName string, name string, resetPropertiesEnvelope armappservice.StaticSiteResetPropertiesARMResource, options *armappservice.StaticSitesClientResetStaticSiteAPIKeyOptions) (resp azfake.Responder[armappservice.StaticSitesClientResetStaticSiteAPIKeyResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappservice.StaticSitesClientResetStaticSiteAPIKeyResponse{}, nil)
+				return
+			},
+			BeginDetachStaticSite: func(ctx context.Context, resourceGroupName string, name string, options *armappservice.StaticSitesClientBeginDetachStaticSiteOptions) (resp azfake.PollerResponder[armappservice.StaticSitesClientDetachStaticSiteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armappservice.StaticSitesClientDetachStaticSiteResponse{}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+	repoURL = "https://github.com/Azure-Samples/sample-static-site"
+	repoToken = "fake-token"
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	appserviceClientFactory, err = armappservice.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: appservicefake.NewServerFactoryTransport(&appserviceServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staticSitesClient = appserviceClientFactory.NewStaticSitesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	staticSite, err := createStaticSite(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("static site:", *staticSite.ID)
+
+	staticSite, err = getStaticSite(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get static site:", *staticSite.ID)
+
+	listFunctions, err := listStaticSiteFunctions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list static site functions:", len(listFunctions))
+
+	list, err := listStaticSite(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list static site:", len(list))
+
+	listCustimDomain, err := listStaticSiteCustomDomain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list static site custom domain:", len(listCustimDomain))
+
+	err = resetStaticSiteApiKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("reset static site api key")
+
+	err = detachStaticSite(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("detached static site")
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,81 +5,69 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "westus"
-	resourceGroupName  = "sample-resource-group"
-	appServicePlanName = "sample-appservice-plan"
+	location              = "westus"
+	resourceGroupName     = "sample-resource-group"
+	appServicePlanName    = "sample-appservice-plan"
+	appServicePlanSKUName = "P1V2"
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	appserviceClientFactory *armappservice.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	plansClient         *armappservice.PlansClient
+	plansClient *armappservice.PlansClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"appServicePlanName":    &appServicePlanName,
+		"appServicePlanSKUName": &appServicePlanSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	appserviceClientFactory, err = armappservice.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	plansClient = appserviceClientFactory.NewPlansClient()
 
-	appserviceClientFactory, err = armappservice.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Web"}}); err != nil {
+		return err
 	}
-	plansClient = appserviceClientFactory.NewPlansClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	appServicePlan, err := createAppServicePlan(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("app service plan:", *appServicePlan.ID)
 
 	appServicePlan, err = getAppServicePlan(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get app service plan:", *appServicePlan.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
@@ -91,7 +79,7 @@ func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
 		armappservice.Plan{
 			Location: to.Ptr(location),
 			SKU: &armappservice.SKUDescription{
-				Name:     to.Ptr("P1V2"),
+				Name:     to.Ptr(appServicePlanSKUName),
 				Capacity: to.Ptr[int32](1),
 			},
 			Properties: &armappservice.PlanProperties{
@@ -104,7 +92,7 @@ func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create app service plan", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -124,32 +112,3 @@ func getAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
 	}
 	return &resp.Plan, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
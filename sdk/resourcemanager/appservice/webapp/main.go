@@ -5,110 +5,100 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "eastus"
-	resourceGroupName  = "sample-resource-group"
-	appServicePlanName = "sample-appservice-planx"
-	appServiceName     = "sample-appservice-appxyz"
-	slotName           = "sample-slotxyz"
+	location              = "eastus"
+	resourceGroupName     = "sample-resource-group"
+	appServicePlanName    = "sample-appservice-planx"
+	appServiceName        = "sample-appservice-appxyz"
+	slotName              = "sample-slotxyz"
+	appServicePlanSKUName = "S1"
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	appserviceClientFactory *armappservice.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	plansClient         *armappservice.PlansClient
-	webAppsClient       *armappservice.WebAppsClient
+	plansClient   *armappservice.PlansClient
+	webAppsClient *armappservice.WebAppsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"appServicePlanName":    &appServicePlanName,
+		"appServiceName":        samplekit.Unique(&appServiceName, samplekit.AppService),
+		"slotName":              &slotName,
+		"appServicePlanSKUName": &appServicePlanSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	appserviceClientFactory, err = armappservice.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	appserviceClientFactory, err = armappservice.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	plansClient = appserviceClientFactory.NewPlansClient()
 	webAppsClient = appserviceClientFactory.NewWebAppsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Web"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	appServicePlan, err := createAppServicePlan(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("app service plan:", *appServicePlan.ID)
 
 	// If encounter missing error information, it may be that appServiceName has already been used.
 	appService, err := createWebApp(ctx, *appServicePlan.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("appservice app:", *appService.ID)
 
 	appService, err = getWebApp(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get appservice app:", *appService.ID)
 
 	appServiceSlot, err := createWebAppSlot(ctx, *appServicePlan.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("appservice app slot:", *appServiceSlot.ID)
 
 	appServiceSlot, err = getWebAppSlot(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get appservice app slot:", *appServiceSlot.ID)
 
 	appConfiguration, err := getAppConfiguration(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("app configuration:", *appConfiguration.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
@@ -120,7 +110,7 @@ func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
 		armappservice.Plan{
 			Location: to.Ptr(location),
 			SKU: &armappservice.SKUDescription{
-				Name:     to.Ptr("S1"),
+				Name:     to.Ptr(appServicePlanSKUName),
 				Capacity: to.Ptr[int32](1),
 				Tier:     to.Ptr("STANDARD"),
 			},
@@ -134,7 +124,7 @@ func createAppServicePlan(ctx context.Context) (*armappservice.Plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create app service plan", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +164,7 @@ func createWebApp(ctx context.Context, appServicePlanID string) (*armappservice.
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create web app", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +199,7 @@ func createWebAppSlot(ctx context.Context, appServicePlanID string) (*armappserv
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create web app slot", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -243,32 +233,3 @@ func getAppConfiguration(ctx context.Context) (*armappservice.SiteConfigResource
 	}
 	return &resp.SiteConfigResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
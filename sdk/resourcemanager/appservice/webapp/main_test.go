@@ -0,0 +1,187 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	appservicefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestWebapp(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	appserviceServer := appservicefake.ServerFactory{
+		PlansServer: appservicefake.PlansServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, name string, appServicePlan armappservice.Plan, options *armappservice.PlansClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armappservice.PlansClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if appServicePlan.Location == nil || *appServicePlan.Location != location {
+					t.Errorf("unexpected location: %v", appServicePlan.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armappservice.PlansClientCreateOrUpdateResponse{
+					Plan: armappservice.Plan{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/serverfarms/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+		},
+		WebAppsServer: appservicefake.WebAppsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, name string, siteEnvelope armappservice.Site, options *armappservice.WebAppsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armappservice.WebAppsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if siteEnvelope.Location == nil || *siteEnvelope.Location != location {
+					t.Errorf("unexpected location: %v", siteEnvelope.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armappservice.WebAppsClientCreateOrUpdateResponse{
+					Site: armappservice.Site{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+			BeginCreateOrUpdateSlot: func(ctx context.Context, resourceGroupName string, name string, slot string, siteEnvelope armappservice.Site, options *armappservice.WebAppsClientBeginCreateOrUpdateSlotOptions) (resp azfake.PollerResponder[armappservice.WebAppsClientCreateOrUpdateSlotResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armappservice.WebAppsClientCreateOrUpdateSlotResponse{
+					Site: armappservice.Site{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s/slots/%s", subscriptionID, resourceGroupName, name, slot)),
+						Name: to.Ptr(slot),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, name string, options *armappservice.WebAppsClientGetOptions) (resp azfake.Responder[armappservice.WebAppsClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappservice.WebAppsClientGetResponse{
+					Site: armappservice.Site{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+			GetSlot: func(ctx context.Context, resourceGroupName string, name string, slot string, options *armappservice.WebAppsClientGetSlotOptions) (resp azfake.Responder[armappservice.WebAppsClientGetSlotResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappservice.WebAppsClientGetSlotResponse{
+					Site: armappservice.Site{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s/slots/%s", subscriptionID, resourceGroupName, name, slot)),
+						Name: to.Ptr(slot),
+					},
+				}, nil)
+				return
+			},
+			GetConfiguration: func(ctx context.Context, resourceGroupName string, name string, options *armappservice.WebAppsClientGetConfigurationOptions) (resp azfake.Responder[armappservice.WebAppsClientGetConfigurationResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armappservice.WebAppsClientGetConfigurationResponse{
+					SiteConfigResource: armappservice.SiteConfigResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s/config/web", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	appserviceClientFactory, err = armappservice.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: appservicefake.NewServerFactoryTransport(&appserviceServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plansClient = appserviceClientFactory.NewPlansClient()
+	webAppsClient = appserviceClientFactory.NewWebAppsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	appServicePlan, err := createAppServicePlan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("app service plan:", *appServicePlan.ID)
+
+	// If encounter missing error information, it may be that appServiceName has already been used.
+	appService, err := createWebApp(ctx, *appServicePlan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("appservice app:", *appService.ID)
+
+	appService, err = getWebApp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get appservice app:", *appService.ID)
+
+	appServiceSlot, err := createWebAppSlot(ctx, *appServicePlan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("appservice app slot:", *appServiceSlot.ID)
+
+	appServiceSlot, err = getWebAppSlot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get appservice app slot:", *appServiceSlot.ID)
+
+	appConfiguration, err := getAppConfiguration(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("app configuration:", *appConfiguration.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
+	automationfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestAccount(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	automationServer := automationfake.ServerFactory{
+		AccountServer: automationfake.AccountServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, automationAccountName string, parameters armautomation.AccountCreateOrUpdateParameters, options *armautomation.AccountClientCreateOrUpdateOptions) (resp azfake.Responder[armautomation.AccountClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armautomation.AccountClientCreateOrUpdateResponse{
+					Account: armautomation.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s", subscriptionID, resourceGroupName, automationAccountName)),
+						Name: to.Ptr(automationAccountName),
+					},
+				}, nil)
+				return
+			},
+			Update: func(ctx context.Context, resourceGroupName string, automationAccountName string, parameters armautomation.AccountUpdateParameters, options *armautomation.AccountClientUpdateOptions) (resp azfake.Responder[armautomation.AccountClientUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.AccountClientUpdateResponse{
+					Account: armautomation.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s", subscriptionID, resourceGroupName, automationAccountName)),
+						Name: to.Ptr(automationAccountName),
+						Tags: parameters.Tags,
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, automationAccountName string, options *armautomation.AccountClientGetOptions) (resp azfake.Responder[armautomation.AccountClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.AccountClientGetResponse{
+					Account: armautomation.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s", subscriptionID, resourceGroupName, automationAccountName)),
+						Name: to.Ptr(automationAccountName),
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armautomation.AccountClientListOptions) (resp azfake.PagerResponder[armautomation.AccountClientListResponse]) {
+				resp.AddPage(http.StatusOK, armautomation.AccountClientListResponse{
+					AccountListResult: armautomation.AccountListResult{
+						Value: []*armautomation.Account{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Automation/automationAccounts/%s", subscriptionID, automationAccountName)),
+								Name: to.Ptr(automationAccountName),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	automationClientFactory, err = armautomation.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: automationfake.NewServerFactoryTransport(&automationServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountClient = automationClientFactory.NewAccountClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	account, err := createAutomationAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("automation account:", *account.ID)
+
+	account, err = updateAutomationAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("update automation account:", *account.ID, *account.Tags["automation"])
+
+	account, err = getAutomationAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get automation account:", *account.ID)
+
+	accounts, err := listAutomationAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list automation account:")
+	for _, tmp := range accounts {
+		t.Logf("\t%v", *tmp.ID)
+	}
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,96 +6,85 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID        string
-	location              = "westus"
-	resourceGroupName     = "sample-resource-group"
-	automationAccountName = "sample-automation-account"
-	credentialName        = "sample-automation-credential"
+	location                 = "westus"
+	resourceGroupName        = "sample-resource-group"
+	automationAccountName    = "sample-automation-account"
+	credentialName           = "sample-automation-credential"
+	automationAccountSKUName = string(armautomation.SKUNameEnumFree)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	automationClientFactory *armautomation.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	accountClient       *armautomation.AccountClient
-	credentialClient    *armautomation.CredentialClient
+	accountClient    *armautomation.AccountClient
+	credentialClient *armautomation.CredentialClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                 &location,
+		"resourceGroupName":        &resourceGroupName,
+		"automationAccountName":    &automationAccountName,
+		"credentialName":           &credentialName,
+		"automationAccountSKUName": &automationAccountSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	automationClientFactory, err = armautomation.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	automationClientFactory, err = armautomation.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountClient = automationClientFactory.NewAccountClient()
 	credentialClient = automationClientFactory.NewCredentialClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Automation"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	account, err := createAutomationAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation account:", *account.ID)
 
 	credential, err := createAutomationCredential(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation credential:", *credential.ID)
 
 	credential, err = updateAutomationCredential(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("update automation credential:", *credential.ID, *credential.Properties.Description)
 
 	credential, err = getAutomationCredential(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get automation credential:", *credential.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAutomationAccount(ctx context.Context) (*armautomation.Account, error) {
@@ -109,7 +98,7 @@ func createAutomationAccount(ctx context.Context) (*armautomation.Account, error
 			Name:     to.Ptr(automationAccountName),
 			Properties: &armautomation.AccountCreateOrUpdateProperties{
 				SKU: &armautomation.SKU{
-					Name: to.Ptr(armautomation.SKUNameEnumFree),
+					Name: to.Ptr(armautomation.SKUNameEnum(automationAccountSKUName)),
 				},
 			},
 		},
@@ -177,34 +166,3 @@ func getAutomationCredential(ctx context.Context) (*armautomation.Credential, er
 
 	return &resp.Credential, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -6,111 +6,101 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID        string
-	location              = "westus"
-	resourceGroupName     = "sample-resource-group"
-	automationAccountName = "sample-automation-account"
-	runbookName           = "Get-AzureVMTutorial"
-	jobName               = "sample-automation-job"
+	location                 = "westus"
+	resourceGroupName        = "sample-resource-group"
+	automationAccountName    = "sample-automation-account"
+	runbookName              = "Get-AzureVMTutorial"
+	jobName                  = "sample-automation-job"
+	automationAccountSKUName = string(armautomation.SKUNameEnumFree)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	automationClientFactory *armautomation.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	accountClient       *armautomation.AccountClient
-	runbookClient       *armautomation.RunbookClient
-	jobClient           *armautomation.JobClient
+	accountClient *armautomation.AccountClient
+	runbookClient *armautomation.RunbookClient
+	jobClient     *armautomation.JobClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                 &location,
+		"resourceGroupName":        &resourceGroupName,
+		"automationAccountName":    &automationAccountName,
+		"runbookName":              &runbookName,
+		"jobName":                  &jobName,
+		"automationAccountSKUName": &automationAccountSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	automationClientFactory, err = armautomation.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	automationClientFactory, err = armautomation.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountClient = automationClientFactory.NewAccountClient()
 	runbookClient = automationClientFactory.NewRunbookClient()
 	jobClient = automationClientFactory.NewJobClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Automation"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	account, err := createAutomationAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation account:", *account.ID)
 
 	runbook, err := createAutomationRunbook(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation runbook:", *runbook.ID)
 
 	job, err := createAutomationJob(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation job:", *job.ID)
 
 	job, err = getAutomationJob(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get automation job:", *job.ID)
 
 	jobOutput, err := getJobOutput(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get automation job output:", jobOutput)
 
 	runbookContent, err := getRunbookContent(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get automation job runbook content:", runbookContent)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAutomationAccount(ctx context.Context) (*armautomation.Account, error) {
@@ -124,7 +114,7 @@ func createAutomationAccount(ctx context.Context) (*armautomation.Account, error
 			Name:     to.Ptr(automationAccountName),
 			Properties: &armautomation.AccountCreateOrUpdateProperties{
 				SKU: &armautomation.SKU{
-					Name: to.Ptr(armautomation.SKUNameEnumFree),
+					Name: to.Ptr(armautomation.SKUNameEnum(automationAccountSKUName)),
 				},
 			},
 		},
@@ -226,34 +216,3 @@ func getRunbookContent(ctx context.Context) (string, error) {
 
 	return *resp.Value, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
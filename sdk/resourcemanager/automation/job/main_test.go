@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
+	automationfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestJob(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	automationServer := automationfake.ServerFactory{
+		AccountServer: automationfake.AccountServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, automationAccountName string, parameters armautomation.AccountCreateOrUpdateParameters, options *armautomation.AccountClientCreateOrUpdateOptions) (resp azfake.Responder[armautomation.AccountClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armautomation.AccountClientCreateOrUpdateResponse{
+					Account: armautomation.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s", subscriptionID, resourceGroupName, automationAccountName)),
+						Name: to.Ptr(automationAccountName),
+					},
+				}, nil)
+				return
+			},
+		},
+		RunbookServer: automationfake.RunbookServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, automationAccountName string, runbookName string, parameters armautomation.RunbookCreateOrUpdateParameters, options *armautomation.RunbookClientCreateOrUpdateOptions) (resp azfake.Responder[armautomation.RunbookClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.RunbookClientCreateOrUpdateResponse{
+					Runbook: armautomation.Runbook{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s/runbooks/%s", subscriptionID, resourceGroupName, automationAccountName, runbookName)),
+						Name: to.Ptr(runbookName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobServer: automationfake.JobServer{
+			Create: func(ctx context.Context, resourceGroupName string, automationAccountName string, jobName string, parameters armautomation.JobCreateParameters, options *armautomation.JobClientCreateOptions) (resp azfake.Responder[armautomation.JobClientCreateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusCreated, armautomation.JobClientCreateResponse{
+					Job: armautomation.Job{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s/jobs/%s", subscriptionID, resourceGroupName, automationAccountName, jobName)),
+						Name: to.Ptr(jobName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, automationAccountName string, jobName string, options *armautomation.JobClientGetOptions) (resp azfake.Responder[armautomation.JobClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.JobClientGetResponse{
+					Job: armautomation.Job{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Automation/automationAccounts/%s/jobs/%s", subscriptionID, resourceGroupName, automationAccountName, jobName)),
+						Name: to.Ptr(jobName),
+					},
+				}, nil)
+				return
+			},
+			GetOutput: func(ctx context.Context, resourceGroupName string, automationAccountName string, jobName string, options *armautomation.JobClientGetOutputOptions) (resp azfake.Responder[armautomation.JobClientGetOutputResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.JobClientGetOutputResponse{}, nil)
+				return
+			},
+			GetRunbookContent: func(ctx context.Context, resourceGroupName string, automationAccountName string, jobName string, options *armautomation.JobClientGetRunbookContentOptions) (resp azfake.Responder[armautomation.JobClientGetRunbookContentResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armautomation.JobClientGetRunbookContentResponse{}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	automationClientFactory, err = armautomation.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: automationfake.NewServerFactoryTransport(&automationServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountClient = automationClientFactory.NewAccountClient()
+	runbookClient = automationClientFactory.NewRunbookClient()
+	jobClient = automationClientFactory.NewJobClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	account, err := createAutomationAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("automation account:", *account.ID)
+
+	runbook, err := createAutomationRunbook(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("automation runbook:", *runbook.ID)
+
+	job, err := createAutomationJob(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("automation job:", *job.ID)
+
+	job, err = getAutomationJob(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get automation job:", *job.ID)
+
+	jobOutput, err := getJobOutput(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get automation job output:", jobOutput)
+
+	runbookContent, err := getRunbookContent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get automation job runbook content:", runbookContent)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,90 +6,79 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID        string
-	location              = "westus"
-	resourceGroupName     = "sample-resource-group"
-	automationAccountName = "sample-automation-account"
-	moduleName            = "sample-automation-module"
+	location                 = "westus"
+	resourceGroupName        = "sample-resource-group"
+	automationAccountName    = "sample-automation-account"
+	moduleName               = "sample-automation-module"
+	automationAccountSKUName = string(armautomation.SKUNameEnumFree)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	automationClientFactory *armautomation.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	accountClient       *armautomation.AccountClient
-	moduleClient        *armautomation.ModuleClient
+	accountClient *armautomation.AccountClient
+	moduleClient  *armautomation.ModuleClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":                 &location,
+		"resourceGroupName":        &resourceGroupName,
+		"automationAccountName":    &automationAccountName,
+		"moduleName":               &moduleName,
+		"automationAccountSKUName": &automationAccountSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	automationClientFactory, err = armautomation.NewClientFactory(subscriptionID, cred, nil)
+	automationClientFactory, err = armautomation.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountClient = automationClientFactory.NewAccountClient()
 	moduleClient = automationClientFactory.NewModuleClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Automation"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	account, err := createAutomationAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation account:", *account.ID)
 
 	module, err := createAutomationModule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("automation module:", *module.ID)
 
 	module, err = getAutomationModule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get automation module:", *module.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAutomationAccount(ctx context.Context) (*armautomation.Account, error) {
@@ -103,7 +92,7 @@ func createAutomationAccount(ctx context.Context) (*armautomation.Account, error
 			Name:     to.Ptr(automationAccountName),
 			Properties: &armautomation.AccountCreateOrUpdateProperties{
 				SKU: &armautomation.SKU{
-					Name: to.Ptr(armautomation.SKUNameEnumFree),
+					Name: to.Ptr(armautomation.SKUNameEnum(automationAccountSKUName)),
 				},
 			},
 		},
@@ -153,34 +142,3 @@ func getAutomationModule(ctx context.Context) (*armautomation.Module, error) {
 
 	return &resp.Module, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -5,70 +5,65 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID      string
-	location            = "westus"
-	resourceGroupName   = "sample-resource-group"
-	availabilitySetName = "sample-availability-sets"
+	location               = "westus"
+	resourceGroupName      = "sample-resource-group"
+	availabilitySetName    = "sample-availability-sets"
+	availabilitySetSKUName = "Aligned"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
 )
 
 var (
-	resourceGroupClient    *armresources.ResourceGroupsClient
 	availabilitySetsClient *armcompute.AvailabilitySetsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":               &location,
+		"resourceGroupName":      &resourceGroupName,
+		"availabilitySetName":    &availabilitySetName,
+		"availabilitySetSKUName": &availabilitySetSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	availabilitySetsClient = computeClientFactory.NewAvailabilitySetsClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute"}}); err != nil {
+		return err
 	}
-	availabilitySetsClient = computeClientFactory.NewAvailabilitySetsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	availabilitySets, err := createAvailabilitySet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("availability set:", *availabilitySets.ID)
 
 	availabilitySetList, err := listAvailabilitySet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for i, v := range availabilitySetList {
 		log.Println(i, *v.ID)
@@ -76,20 +71,13 @@ func main() {
 
 	availabilitySetSizesList, err := listAvailabilitySizes(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for i, v := range availabilitySetSizesList {
 		log.Println(i, v.Name)
 	}
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err := cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createAvailabilitySet(ctx context.Context) (*armcompute.AvailabilitySet, error) {
@@ -105,7 +93,7 @@ func createAvailabilitySet(ctx context.Context) (*armcompute.AvailabilitySet, er
 				PlatformUpdateDomainCount: to.Ptr[int32](1),
 			},
 			SKU: &armcompute.SKU{
-				Name: to.Ptr("Aligned"),
+				Name: to.Ptr(availabilitySetSKUName),
 			},
 		},
 		nil,
@@ -146,32 +134,3 @@ func listAvailabilitySizes(ctx context.Context) ([]*armcompute.VirtualMachineSiz
 
 	return availabilitySizes, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,19 +5,15 @@ package main
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
-var subscriptionId string
-
-const (
+var (
 	resourceGroupName = "sample-resource-group"
 	vmName            = "sample-vm"
 	vnetName          = "sample-vnet"
@@ -27,17 +23,15 @@ const (
 	diskName          = "sample-disk"
 	publicIPName      = "sample-public-ip"
 	location          = "westus2"
+	vmSize            = "Standard_F2s"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-
 	virtualNetworksClient   *armnetwork.VirtualNetworksClient
 	subnetsClient           *armnetwork.SubnetsClient
 	securityGroupsClient    *armnetwork.SecurityGroupsClient
@@ -49,36 +43,27 @@ var (
 )
 
 func main() {
-	subscriptionId = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionId) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-	//create virtual machine
-	createVM()
-
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		//delete virtual machine
-		cleanup()
-	}
+	samplekit.Main(samplekit.Settings{
+		"resourceGroupName": &resourceGroupName,
+		"vmName":            &vmName,
+		"vnetName":          &vnetName,
+		"subnetName":        &subnetName,
+		"nsgName":           &nsgName,
+		"nicName":           &nicName,
+		"diskName":          &diskName,
+		"publicIPName":      &publicIPName,
+		"location":          &location,
+		"vmSize":            &vmSize,
+	}, run)
 }
 
-func createVM() {
-	conn, err := connectionAzure()
-	if err != nil {
-		log.Fatalf("cannot connect to Azure:%+v", err)
-	}
-	ctx := context.Background()
-
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionId, conn, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	sample.Tags = map[string]*string{"sample-rs-tag": to.Ptr("sample-tag")} // resource group update tags
 
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionId, conn, nil)
+	var err error
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
@@ -86,150 +71,132 @@ func createVM() {
 	publicIPAddressesClient = networkClientFactory.NewPublicIPAddressesClient()
 	interfacesClient = networkClientFactory.NewInterfacesClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionId, conn, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualMachinesClient = computeClientFactory.NewVirtualMachinesClient()
 	disksClient = computeClientFactory.NewDisksClient()
 
-	log.Println("start creating virtual machine...")
-	resourceGroup, err := createResourceGroup(ctx)
-	if err != nil {
-		log.Fatalf("cannot create resource group:%+v", err)
-	}
-	log.Printf("Created resource group: %s", *resourceGroup.ID)
-
-	virtualNetwork, err := createVirtualNetwork(ctx)
-	if err != nil {
-		log.Fatalf("cannot create virtual network:%+v", err)
-	}
-	log.Printf("Created virtual network: %s", *virtualNetwork.ID)
-
-	subnet, err := createSubnets(ctx)
-	if err != nil {
-		log.Fatalf("cannot create subnet:%+v", err)
-	}
-	log.Printf("Created subnet: %s", *subnet.ID)
-
-	publicIP, err := createPublicIP(ctx)
-	if err != nil {
-		log.Fatalf("cannot create public IP address:%+v", err)
-	}
-	log.Printf("Created public IP address: %s", *publicIP.ID)
-
-	// network security group
-	nsg, err := createNetworkSecurityGroup(ctx)
-	if err != nil {
-		log.Fatalf("cannot create network security group:%+v", err)
-	}
-	log.Printf("Created network security group: %s", *nsg.ID)
-
-	netWorkInterface, err := createNetWorkInterface(ctx, *subnet.ID, *publicIP.ID, *nsg.ID)
-	if err != nil {
-		log.Fatalf("cannot create network interface:%+v", err)
-	}
-	log.Printf("Created network interface: %s", *netWorkInterface.ID)
-
-	networkInterfaceID := netWorkInterface.ID
-	virtualMachine, err := createVirtualMachine(ctx, *networkInterfaceID)
-	if err != nil {
-		log.Fatalf("cannot create virual machine:%+v", err)
-	}
-	log.Printf("Created network virual machine: %s", *virtualMachine.ID)
-
-	log.Println("Virtual machine created successfully")
-}
-
-func cleanup() {
-	ctx := context.Background()
-
-	log.Println("start deleting virtual machine...")
-	err := deleteVirtualMachine(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete virtual machine:%+v", err)
-	}
-	log.Println("deleted virtual machine")
-
-	err = deleteDisk(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete disk:%+v", err)
-	}
-	log.Println("deleted disk")
-
-	err = deleteNetWorkInterface(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete network interface:%+v", err)
-	}
-	log.Println("deleted network interface")
-
-	err = deleteNetworkSecurityGroup(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete network security group:%+v", err)
-	}
-	log.Println("deleted network security group")
-
-	err = deletePublicIP(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete public IP address:%+v", err)
-	}
-	log.Println("deleted public IP address")
-
-	err = deleteSubnets(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete subnet:%+v", err)
-	}
-	log.Println("deleted subnet")
-
-	err = deleteVirtualNetWork(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete virtual network:%+v", err)
-	}
-	log.Println("deleted virtual network")
-
-	err = deleteResourceGroup(ctx)
-	if err != nil {
-		log.Fatalf("cannot delete resource group:%+v", err)
-	}
-	log.Println("deleted resource group")
-	log.Println("success deleted virtual machine.")
-}
-
-func connectionAzure() (azcore.TokenCredential, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, err
-	}
-	return cred, nil
-}
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	parameters := armresources.ResourceGroup{
-		Location: to.Ptr(location),
-		Tags:     map[string]*string{"sample-rs-tag": to.Ptr("sample-tag")}, // resource group update tags
-	}
-
-	resp, err := resourceGroupClient.CreateOrUpdate(ctx, resourceGroupName, parameters, nil)
-	if err != nil {
-		return nil, err
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{
+		Providers: []string{"Microsoft.Compute", "Microsoft.Network"},
+		VMSizes:   []string{vmSize},
+	}); err != nil {
+		return err
 	}
 
-	return &resp.ResourceGroup, nil
+	//create virtual machine; samplekit deletes whatever was created, in reverse order
+	return createVM(ctx, sample)
 }
 
-func deleteResourceGroup(ctx context.Context) error {
-
-	pollerResponse, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
+// createVM creates the virtual machine and the resources it depends on. The
+// virtual network, public IP address and network security group do not depend
+// on each other, so they are created at the same time; each resource is
+// recorded in sample.Teardown as soon as it exists, to be deleted in the
+// reverse order of the graph. Each creation is a step of the trace when the
+// sample runs with -trace.
+func createVM(ctx context.Context, sample *samplekit.Sample) error {
+	log.Println("start creating virtual machine...")
+	resourceGroup, err := samplekit.Step(ctx, sample, "createResourceGroup", sample.CreateResourceGroup)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot create resource group: %w", err)
 	}
+	log.Printf("Created resource group: %s", *resourceGroup.ID)
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
-	if err != nil {
+	var subnetID, publicIPID, nsgID, networkInterfaceID string
+	var graph samplekit.Graph
+	graph.Add(samplekit.GraphStep{
+		Name: "createVirtualNetwork",
+		Create: func(ctx context.Context) error {
+			virtualNetwork, err := createVirtualNetwork(ctx)
+			if err != nil {
+				return fmt.Errorf("cannot create virtual network: %w", err)
+			}
+			log.Printf("Created virtual network: %s", *virtualNetwork.ID)
+			return nil
+		},
+		Delete: deleteVirtualNetWork,
+	})
+	graph.Add(samplekit.GraphStep{
+		Name:      "createSubnets",
+		DependsOn: []string{"createVirtualNetwork"},
+		Create: func(ctx context.Context) error {
+			subnet, err := createSubnets(ctx)
+			if err != nil {
+				return fmt.Errorf("cannot create subnet: %w", err)
+			}
+			subnetID = *subnet.ID
+			log.Printf("Created subnet: %s", subnetID)
+			return nil
+		},
+		Delete: deleteSubnets,
+	})
+	graph.Add(samplekit.GraphStep{
+		Name: "createPublicIP",
+		Create: func(ctx context.Context) error {
+			publicIP, err := createPublicIP(ctx)
+			if err != nil {
+				return fmt.Errorf("cannot create public IP address: %w", err)
+			}
+			publicIPID = *publicIP.ID
+			log.Printf("Created public IP address: %s", publicIPID)
+			return nil
+		},
+		Delete: deletePublicIP,
+	})
+	// network security group
+	graph.Add(samplekit.GraphStep{
+		Name: "createNetworkSecurityGroup",
+		Create: func(ctx context.Context) error {
+			nsg, err := createNetworkSecurityGroup(ctx)
+			if err != nil {
+				return fmt.Errorf("cannot create network security group: %w", err)
+			}
+			nsgID = *nsg.ID
+			log.Printf("Created network security group: %s", nsgID)
+			return nil
+		},
+		Delete: deleteNetworkSecurityGroup,
+	})
+	graph.Add(samplekit.GraphStep{
+		Name:      "createNetWorkInterface",
+		DependsOn: []string{"createSubnets", "createPublicIP", "createNetworkSecurityGroup"},
+		Create: func(ctx context.Context) error {
+			netWorkInterface, err := createNetWorkInterface(ctx, subnetID, publicIPID, nsgID)
+			if err != nil {
+				return fmt.Errorf("cannot create network interface: %w", err)
+			}
+			networkInterfaceID = *netWorkInterface.ID
+			log.Printf("Created network interface: %s", networkInterfaceID)
+			return nil
+		},
+		Delete: deleteNetWorkInterface,
+	})
+	graph.Add(samplekit.GraphStep{
+		Name:      "createVirtualMachine",
+		DependsOn: []string{"createNetWorkInterface"},
+		Create: func(ctx context.Context) error {
+			virtualMachine, err := createVirtualMachine(ctx, networkInterfaceID)
+			if err != nil {
+				return fmt.Errorf("cannot create virual machine: %w", err)
+			}
+			log.Printf("Created network virual machine: %s", *virtualMachine.ID)
+			return nil
+		},
+		// the OS disk is created with the virtual machine and can only be deleted after it
+		Delete: func(ctx context.Context) error {
+			if err := deleteVirtualMachine(ctx); err != nil {
+				return err
+			}
+			return deleteDisk(ctx)
+		},
+	})
+	if err := graph.Run(ctx, sample); err != nil {
 		return err
 	}
 
+	log.Println("Virtual machine created successfully")
 	return nil
 }
 
@@ -259,7 +226,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -274,7 +241,7 @@ func deleteVirtualNetWork(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete virtual network", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -295,7 +262,7 @@ func createSubnets(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnets", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -310,7 +277,7 @@ func deleteSubnets(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete subnets", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -364,7 +331,7 @@ func createNetworkSecurityGroup(ctx context.Context) (*armnetwork.SecurityGroup,
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create network security group", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -378,7 +345,7 @@ func deleteNetworkSecurityGroup(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete network security group", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -399,7 +366,7 @@ func createPublicIP(ctx context.Context) (*armnetwork.PublicIPAddress, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create public IP", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -413,7 +380,7 @@ func deletePublicIP(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete public IP", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -451,7 +418,7 @@ func createNetWorkInterface(ctx context.Context, subnetID string, publicIPID str
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create network interface", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -466,7 +433,7 @@ func deleteNetWorkInterface(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete network interface", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -517,7 +484,7 @@ func createVirtualMachine(ctx context.Context, networkInterfaceID string) (*armc
 				},
 			},
 			HardwareProfile: &armcompute.HardwareProfile{
-				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes("Standard_F2s")), // VM size include vCPUs,RAM,Data Disks,Temp storage.
+				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmSize)), // VM size include vCPUs,RAM,Data Disks,Temp storage.
 			},
 			OSProfile: &armcompute.OSProfile{ //
 				ComputerName:  to.Ptr("sample-compute"),
@@ -551,7 +518,7 @@ func createVirtualMachine(ctx context.Context, networkInterfaceID string) (*armc
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual machine", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +533,7 @@ func deleteVirtualMachine(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete virtual machine", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -581,7 +548,7 @@ func deleteDisk(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResponse.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "delete disk", pollerResponse, nil)
 	if err != nil {
 		return err
 	}
@@ -5,102 +5,88 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
-	TenantID          string
-	location          = "westus"
-	resourceGroupName = "sample-resource-group"
-	hostGroupName     = "sample-host-group"
-	hostName          = "sample-host"
+	TenantID             string
+	location             = "eastus" // host groups are zonal, and westus has no availability zones
+	resourceGroupName    = "sample-resource-group"
+	hostGroupName        = "sample-host-group"
+	hostName             = "sample-host"
+	dedicatedHostSKUName = "DSv3-Type1"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
 )
 
 var (
-	resourceGroupClient       *armresources.ResourceGroupsClient
 	dedicatedHostGroupsClient *armcompute.DedicatedHostGroupsClient
 	dedicatedHostsClient      *armcompute.DedicatedHostsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":             &location,
+		"resourceGroupName":    &resourceGroupName,
+		"hostGroupName":        &hostGroupName,
+		"hostName":             &hostName,
+		"dedicatedHostSKUName": &dedicatedHostSKUName,
+	}, run)
+}
 
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	TenantID = sample.TenantID
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	dedicatedHostGroupsClient = computeClientFactory.NewDedicatedHostGroupsClient()
 	dedicatedHostsClient = computeClientFactory.NewDedicatedHostsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	dedicatedHostGroup, err := createDedicatedHostGroups(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("dedicated host group:", *dedicatedHostGroup.ID)
 
 	dedicatedHost, err := createDedicatedHost(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("dedicated host:", *dedicatedHost.ID)
 
 	dedicatedHostGroup, err = getDedicatedHostGroups(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get dedicated host:", *dedicatedHostGroup.ID)
 
 	dedicatedHost, err = getDedicatedHost(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get dedicated host:", *dedicatedHost.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err := cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDedicatedHostGroups(ctx context.Context) (*armcompute.DedicatedHostGroup, error) {
@@ -110,7 +96,7 @@ func createDedicatedHostGroups(ctx context.Context) (*armcompute.DedicatedHostGr
 		resourceGroupName,
 		hostGroupName,
 		armcompute.DedicatedHostGroup{
-			Location: to.Ptr("eastus"),
+			Location: to.Ptr(location),
 			Properties: &armcompute.DedicatedHostGroupProperties{
 				PlatformFaultDomainCount: to.Ptr[int32](3),
 			},
@@ -143,12 +129,12 @@ func createDedicatedHost(ctx context.Context) (*armcompute.DedicatedHost, error)
 		hostGroupName,
 		hostName,
 		armcompute.DedicatedHost{
-			Location: to.Ptr("eastus"),
+			Location: to.Ptr(location),
 			Properties: &armcompute.DedicatedHostProperties{
 				PlatformFaultDomain: to.Ptr[int32](1),
 			},
 			SKU: &armcompute.SKU{
-				Name: to.Ptr("DSv3-Type1"),
+				Name: to.Ptr(dedicatedHostSKUName),
 			},
 		},
 		nil,
@@ -157,7 +143,7 @@ func createDedicatedHost(ctx context.Context) (*armcompute.DedicatedHost, error)
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create dedicated host", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -173,32 +159,3 @@ func getDedicatedHost(ctx context.Context) (*armcompute.DedicatedHost, error) {
 
 	return &resp.DedicatedHost, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,17 +5,14 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID        string
 	TenantID              string
 	location              = "westus"
 	resourceGroupName     = "sample-resource-group"
@@ -23,16 +20,16 @@ var (
 	vaultName             = "sample2vault"
 	keyName               = "sample2key"
 	diskEncryptionSetName = "sample-disk-encryption"
+	diskSKUName           = string(armcompute.DiskStorageAccountTypesStandardLRS)
+	vaultSKUName          = string(armkeyvault.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	keyvaultClientFactory  *armkeyvault.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
+	keyvaultClientFactory *armkeyvault.ClientFactory
+	computeClientFactory  *armcompute.ClientFactory
 )
 
 var (
-	resourceGroupClient      *armresources.ResourceGroupsClient
 	vaultsClient             *armkeyvault.VaultsClient
 	keysClient               *armkeyvault.KeysClient
 	disksClient              *armcompute.DisksClient
@@ -40,80 +37,73 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"diskName":              &diskName,
+		"vaultName":             samplekit.Unique(&vaultName, samplekit.KeyVault),
+		"keyName":               &keyName,
+		"diskEncryptionSetName": &diskEncryptionSetName,
+		"diskSKUName":           &diskSKUName,
+		"vaultSKUName":          &vaultSKUName,
+	}, run)
+}
 
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	TenantID = sample.TenantID
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	vaultsClient = keyvaultClientFactory.NewVaultsClient()
 	keysClient = keyvaultClientFactory.NewKeysClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	disksClient = computeClientFactory.NewDisksClient()
 	diskEncryptionSetsClient = computeClientFactory.NewDiskEncryptionSetsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute", "Microsoft.KeyVault"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	vault, err := createVault(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("vault:", *vault.ID)
 
 	key, err := createKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("key:", *key.ID)
 
 	disk, err := createDisk(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual disk:", *disk.ID)
 
 	diskEncryptionSet, err := diskEncryptionSets(ctx, *vault.ID, *key.Properties.KeyURIWithVersion)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("disk encryption set:", *diskEncryptionSet.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err := cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDisk(ctx context.Context) (*armcompute.Disk, error) {
@@ -125,7 +115,7 @@ func createDisk(ctx context.Context) (*armcompute.Disk, error) {
 		armcompute.Disk{
 			Location: to.Ptr(location),
 			SKU: &armcompute.DiskSKU{
-				Name: to.Ptr(armcompute.DiskStorageAccountTypesStandardLRS),
+				Name: to.Ptr(armcompute.DiskStorageAccountTypes(diskSKUName)),
 			},
 			Properties: &armcompute.DiskProperties{
 				CreationData: &armcompute.CreationData{
@@ -140,7 +130,7 @@ func createDisk(ctx context.Context) (*armcompute.Disk, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create disk", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +149,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 			Properties: &armkeyvault.VaultProperties{
 				SKU: &armkeyvault.SKU{
 					Family: to.Ptr(armkeyvault.SKUFamilyA),
-					Name:   to.Ptr(armkeyvault.SKUNameStandard),
+					Name:   to.Ptr(armkeyvault.SKUName(vaultSKUName)),
 				},
 				TenantID: to.Ptr(TenantID),
 				AccessPolicies: []*armkeyvault.AccessPolicyEntry{
@@ -192,7 +182,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create vault", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -253,39 +243,10 @@ func diskEncryptionSets(ctx context.Context, vaultID, keyURL string) (*armcomput
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "disk encryption sets", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return &resp.DiskEncryptionSet, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	computefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	keyvaultfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestDisk(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	keyvaultServer := keyvaultfake.ServerFactory{
+		VaultsServer: keyvaultfake.VaultsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, vaultName string, parameters armkeyvault.VaultCreateOrUpdateParameters, options *armkeyvault.VaultsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armkeyvault.VaultsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armkeyvault.VaultsClientCreateOrUpdateResponse{
+					Vault: armkeyvault.Vault{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/vaults/%s", subscriptionID, resourceGroupName, vaultName)),
+						Name: to.Ptr(vaultName),
+					},
+				}, nil)
+				return
+			},
+		},
+		KeysServer: keyvaultfake.KeysServer{
+			CreateIfNotExist: func(ctx context.Context, resourceGroupName string, vaultName string, keyName string, parameters armkeyvault.KeyCreateParameters, options *armkeyvault.KeysClientCreateIfNotExistOptions) (resp azfake.Responder[armkeyvault.KeysClientCreateIfNotExistResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armkeyvault.KeysClientCreateIfNotExistResponse{
+					Key: armkeyvault.Key{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/vaults/%s/keys/%s", subscriptionID, resourceGroupName, vaultName, keyName)),
+						Name: to.Ptr(keyName),
+						Properties: &armkeyvault.KeyProperties{
+							KeyURIWithVersion: to.Ptr(fmt.Sprintf("https://%s.vault.azure.net/keys/%s/00000000000000000000000000000000", vaultName, keyName)),
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+	computeServer := computefake.ServerFactory{
+		DisksServer: computefake.DisksServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, diskName string, disk armcompute.Disk, options *armcompute.DisksClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.DisksClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if disk.Location == nil || *disk.Location != location {
+					t.Errorf("unexpected location: %v", disk.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcompute.DisksClientCreateOrUpdateResponse{
+					Disk: armcompute.Disk{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/disks/%s", subscriptionID, resourceGroupName, diskName)),
+						Name: to.Ptr(diskName),
+					},
+				}, nil)
+				return
+			},
+		},
+		DiskEncryptionSetsServer: computefake.DiskEncryptionSetsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, diskEncryptionSetName string, diskEncryptionSet armcompute.DiskEncryptionSet, options *armcompute.DiskEncryptionSetsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.DiskEncryptionSetsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if diskEncryptionSet.Location == nil || *diskEncryptionSet.Location != location {
+					t.Errorf("unexpected location: %v", diskEncryptionSet.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcompute.DiskEncryptionSetsClientCreateOrUpdateResponse{
+					DiskEncryptionSet: armcompute.DiskEncryptionSet{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/diskEncryptionSets/%s", subscriptionID, resourceGroupName, diskEncryptionSetName)),
+						Name: to.Ptr(diskEncryptionSetName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	TenantID = "00000000-0000-0000-0000-000000000000"
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: keyvaultfake.NewServerFactoryTransport(&keyvaultServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaultsClient = keyvaultClientFactory.NewVaultsClient()
+	keysClient = keyvaultClientFactory.NewKeysClient()
+
+	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: computefake.NewServerFactoryTransport(&computeServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disksClient = computeClientFactory.NewDisksClient()
+	diskEncryptionSetsClient = computeClientFactory.NewDiskEncryptionSetsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	vault, err := createVault(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("vault:", *vault.ID)
+
+	key, err := createKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("key:", *key.ID)
+
+	disk, err := createDisk(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("virtual disk:", *disk.ID)
+
+	diskEncryptionSet, err := diskEncryptionSets(ctx, *vault.ID, *key.Properties.KeyURIWithVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("disk encryption set:", *diskEncryptionSet.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID         string
 	TenantID               string
 	location               = "westus"
 	resourceGroupName      = "sample-resource-group"
@@ -23,15 +20,14 @@ var (
 	galleryName            = "sample_gallery"
 	galleryApplicationName = "sample_gallery_application"
 	galleryImageName       = "sample_gallery_image"
+	diskSKUName            = string(armcompute.DiskStorageAccountTypesStandardLRS)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
 )
 
 var (
-	resourceGroupClient       *armresources.ResourceGroupsClient
 	disksClient               *armcompute.DisksClient
 	snapshotsClient           *armcompute.SnapshotsClient
 	galleriesClient           *armcompute.GalleriesClient
@@ -40,31 +36,26 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":               &location,
+		"resourceGroupName":      &resourceGroupName,
+		"diskName":               &diskName,
+		"snapshotName":           &snapshotName,
+		"galleryName":            &galleryName,
+		"galleryApplicationName": &galleryApplicationName,
+		"galleryImageName":       &galleryImageName,
+		"diskSKUName":            &diskSKUName,
+	}, run)
+}
 
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	TenantID = sample.TenantID
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	disksClient = computeClientFactory.NewDisksClient()
 	snapshotsClient = computeClientFactory.NewSnapshotsClient()
@@ -72,50 +63,48 @@ func main() {
 	galleryApplicationsClient = computeClientFactory.NewGalleryApplicationsClient()
 	galleryImagesClient = computeClientFactory.NewGalleryImagesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	disk, err := createDisk(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("disk:", *disk.ID)
 
 	snapshot, err := createSnapshot(ctx, *disk.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("snapshot:", *snapshot.ID)
 
 	gallery, err := createGallery(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("gallery:", *gallery.ID)
 
 	galleryApplication, err := createGalleryApplication(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("gallery application:", *galleryApplication.ID)
 
 	galleryImage, err := createGalleryImage(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("gallery image:", *galleryImage.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err := cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDisk(ctx context.Context) (*armcompute.Disk, error) {
@@ -127,7 +116,7 @@ func createDisk(ctx context.Context) (*armcompute.Disk, error) {
 		armcompute.Disk{
 			Location: to.Ptr(location),
 			SKU: &armcompute.DiskSKU{
-				Name: to.Ptr(armcompute.DiskStorageAccountTypesStandardLRS),
+				Name: to.Ptr(armcompute.DiskStorageAccountTypes(diskSKUName)),
 			},
 			Properties: &armcompute.DiskProperties{
 				CreationData: &armcompute.CreationData{
@@ -142,7 +131,7 @@ func createDisk(ctx context.Context) (*armcompute.Disk, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create disk", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +160,7 @@ func createSnapshot(ctx context.Context, diskID string) (*armcompute.Snapshot, e
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create snapshot", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +186,7 @@ func createGallery(ctx context.Context) (*armcompute.Gallery, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create gallery", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -226,7 +215,7 @@ func createGalleryApplication(ctx context.Context) (*armcompute.GalleryApplicati
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create gallery application", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -260,39 +249,10 @@ func createGalleryImage(ctx context.Context) (*armcompute.GalleryImage, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create gallery image", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return &resp.GalleryImage, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
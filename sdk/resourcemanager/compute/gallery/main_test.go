@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	computefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestGallery(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	computeServer := computefake.ServerFactory{
+		DisksServer: computefake.DisksServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, diskName string, disk armcompute.Disk, options *armcompute.DisksClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.DisksClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if disk.Location == nil || *disk.Location != location {
+					t.Errorf("unexpected location: %v", disk.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcompute.DisksClientCreateOrUpdateResponse{
+					Disk: armcompute.Disk{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/disks/%s", subscriptionID, resourceGroupName, diskName)),
+						Name: to.Ptr(diskName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SnapshotsServer: computefake.SnapshotsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, snapshotName string, snapshot armcompute.Snapshot, options *armcompute.SnapshotsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.SnapshotsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if snapshot.Location == nil || *snapshot.Location != location {
+					t.Errorf("unexpected location: %v", snapshot.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcompute.SnapshotsClientCreateOrUpdateResponse{
+					Snapshot: armcompute.Snapshot{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/snapshots/%s", subscriptionID, resourceGroupName, snapshotName)),
+						Name: to.Ptr(snapshotName),
+					},
+				}, nil)
+				return
+			},
+		},
+		GalleriesServer: computefake.GalleriesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, galleryName string, gallery armcompute.Gallery, options *armcompute.GalleriesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.GalleriesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if gallery.Location == nil || *gallery.Location != location {
+					t.Errorf("unexpected location: %v", gallery.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcompute.GalleriesClientCreateOrUpdateResponse{
+					Gallery: armcompute.Gallery{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/galleries/%s", subscriptionID, resourceGroupName, galleryName)),
+						Name: to.Ptr(galleryName),
+					},
+				}, nil)
+				return
+			},
+		},
+		GalleryApplicationsServer: computefake.GalleryApplicationsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, galleryName string, galleryApplicationName string, galleryApplication armcompute.GalleryApplication, options *armcompute.GalleryApplicationsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.GalleryApplicationsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armcompute.GalleryApplicationsClientCreateOrUpdateResponse{
+					GalleryApplication: armcompute.GalleryApplication{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/galleries/%s/applications/%s", subscriptionID, resourceGroupName, galleryName, galleryApplicationName)),
+						Name: to.Ptr(galleryApplicationName),
+					},
+				}, nil)
+				return
+			},
+		},
+		GalleryImagesServer: computefake.GalleryImagesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, galleryName string, galleryImageName string, galleryImage armcompute.GalleryImage, options *armcompute.GalleryImagesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcompute.GalleryImagesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armcompute.GalleryImagesClientCreateOrUpdateResponse{
+					GalleryImage: armcompute.GalleryImage{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/galleries/%s/images/%s", subscriptionID, resourceGroupName, galleryName, galleryImageName)),
+						Name: to.Ptr(galleryImageName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	TenantID = "00000000-0000-0000-0000-000000000000"
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: computefake.NewServerFactoryTransport(&computeServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disksClient = computeClientFactory.NewDisksClient()
+	snapshotsClient = computeClientFactory.NewSnapshotsClient()
+	galleriesClient = computeClientFactory.NewGalleriesClient()
+	galleryApplicationsClient = computeClientFactory.NewGalleryApplicationsClient()
+	galleryImagesClient = computeClientFactory.NewGalleryImagesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	disk, err := createDisk(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("disk:", *disk.ID)
+
+	snapshot, err := createSnapshot(ctx, *disk.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("snapshot:", *snapshot.ID)
+
+	gallery, err := createGallery(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("gallery:", *gallery.ID)
+
+	galleryApplication, err := createGalleryApplication(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("gallery application:", *galleryApplication.ID)
+
+	galleryImage, err := createGalleryImage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("gallery image:", *galleryImage.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID              string
 	TenantID                    string
 	location                    = "westus"
 	resourceGroupName           = "sample-resource-group"
@@ -22,70 +19,56 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
 )
 
 var (
-	resourceGroupClient            *armresources.ResourceGroupsClient
 	proximityPlacementGroupsClient *armcompute.ProximityPlacementGroupsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                    &location,
+		"resourceGroupName":           &resourceGroupName,
+		"proximityPlacementGroupName": &proximityPlacementGroupName,
+	}, run)
+}
 
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	TenantID = sample.TenantID
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	proximityPlacementGroupsClient = computeClientFactory.NewProximityPlacementGroupsClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute"}}); err != nil {
+		return err
 	}
-	proximityPlacementGroupsClient = computeClientFactory.NewProximityPlacementGroupsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	proximityPlacement, err := createProximityPlacement(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("proximity placement group:", *proximityPlacement.ID)
 
 	proximityPlacement, err = getProximityPlacement(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get proximity placement group:", *proximityPlacement.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err := cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createProximityPlacement(ctx context.Context) (*armcompute.ProximityPlacementGroup, error) {
@@ -118,32 +101,3 @@ func getProximityPlacement(ctx context.Context) (*armcompute.ProximityPlacementG
 
 	return &resp.ProximityPlacementGroup, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,33 +5,28 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
 	location           = "eastus"
 	resourceGroupName  = "sample-resource-group"
 	virtualNetworkName = "sample-virtual-network"
 	subnetName         = "sample-subnet"
 	vmScaleSetName     = "sample-vm-scale-set"
+	vmssSKUName        = "Basic_A0"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-
 	virtualNetworksClient *armnetwork.VirtualNetworksClient
 	subnetsClient         *armnetwork.SubnetsClient
 
@@ -39,68 +34,66 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":           &location,
+		"resourceGroupName":  &resourceGroupName,
+		"virtualNetworkName": &virtualNetworkName,
+		"subnetName":         &subnetName,
+		"vmScaleSetName":     &vmScaleSetName,
+		"vmssSKUName":        &vmssSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualMachineScaleSetsClient = computeClientFactory.NewVirtualMachineScaleSetsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample;
+	// the scale set starts with one instance
+	if err := sample.Preflight(ctx, samplekit.Needs{
+		Providers: []string{"Microsoft.Compute", "Microsoft.Network"},
+		VMSizes:   []string{vmssSKUName},
+	}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	vmss, err := createVMSS(ctx, *subnet.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual machine scale sets:", *vmss.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, error) {
@@ -125,7 +118,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +143,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +159,7 @@ func createVMSS(ctx context.Context, subnetID string) (*armcompute.VirtualMachin
 		armcompute.VirtualMachineScaleSet{
 			Location: to.Ptr(location),
 			SKU: &armcompute.SKU{
-				Name:     to.Ptr("Basic_A0"), //armcompute.VirtualMachineSizeTypesBasicA0
+				Name:     to.Ptr(vmssSKUName), //armcompute.VirtualMachineSizeTypesBasicA0
 				Capacity: to.Ptr[int64](1),
 			},
 			Properties: &armcompute.VirtualMachineScaleSetProperties{
@@ -222,38 +215,9 @@ func createVMSS(ctx context.Context, subnetID string) (*armcompute.VirtualMachin
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create VMSS", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.VirtualMachineScaleSet, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,81 +5,69 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	registryName      = "sample2registry"
+	registrySKUName   = string(armcontainerregistry.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory         *armresources.ClientFactory
 	containerRegistryClientFactory *armcontainerregistry.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	registriesClient    *armcontainerregistry.RegistriesClient
+	registriesClient *armcontainerregistry.RegistriesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"registryName":      samplekit.Unique(&registryName, samplekit.ContainerRegistry),
+		"registrySKUName":   &registrySKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	registriesClient = containerRegistryClientFactory.NewRegistriesClient()
 
-	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ContainerRegistry"}}); err != nil {
+		return err
 	}
-	registriesClient = containerRegistryClientFactory.NewRegistriesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	registry, err := createRegistry(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("registry:", *registry.ID)
 
 	registry, err = getRegistry(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get registry:", *registry.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error) {
@@ -94,7 +82,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 				"key": to.Ptr("value"),
 			},
 			SKU: &armcontainerregistry.SKU{
-				Name: to.Ptr(armcontainerregistry.SKUNameStandard),
+				Name: to.Ptr(armcontainerregistry.SKUName(registrySKUName)),
 			},
 			Properties: &armcontainerregistry.RegistryProperties{
 				AdminUserEnabled: to.Ptr(true),
@@ -105,7 +93,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create registry", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -125,32 +113,3 @@ func getRegistry(ctx context.Context) (*armcontainerregistry.Registry, error) {
 	}
 	return &resp.Registry, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
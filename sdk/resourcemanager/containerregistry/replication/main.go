@@ -5,90 +5,81 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
+	pairedLocation    = "eastus"
 	resourceGroupName = "sample-resource-group"
 	registryName      = "sample2registry"
 	replicationName   = "sample2replication"
+	registrySKUName   = string(armcontainerregistry.SKUNamePremium)
 )
 
 var (
-	resourcesClientFactory         *armresources.ClientFactory
 	containerRegistryClientFactory *armcontainerregistry.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	registriesClient    *armcontainerregistry.RegistriesClient
-	replicationsClient  *armcontainerregistry.ReplicationsClient
+	registriesClient   *armcontainerregistry.RegistriesClient
+	replicationsClient *armcontainerregistry.ReplicationsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"pairedLocation":    &pairedLocation,
+		"resourceGroupName": &resourceGroupName,
+		"registryName":      samplekit.Unique(&registryName, samplekit.ContainerRegistry),
+		"replicationName":   &replicationName,
+		"registrySKUName":   &registrySKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(subscriptionID, cred, nil)
+	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	registriesClient = containerRegistryClientFactory.NewRegistriesClient()
 	replicationsClient = containerRegistryClientFactory.NewReplicationsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ContainerRegistry"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	registry, err := createRegistry(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("registry:", *registry.ID)
 
 	replication, err := createReplication(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("replication:", *replication.ID)
 
 	replication, err = getReplication(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get replication:", *replication.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error) {
@@ -103,7 +94,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 				"key": to.Ptr("value"),
 			},
 			SKU: &armcontainerregistry.SKU{
-				Name: to.Ptr(armcontainerregistry.SKUNamePremium),
+				Name: to.Ptr(armcontainerregistry.SKUName(registrySKUName)),
 			},
 			Properties: &armcontainerregistry.RegistryProperties{
 				AdminUserEnabled: to.Ptr(true),
@@ -114,7 +105,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create registry", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -129,14 +120,14 @@ func createReplication(ctx context.Context) (*armcontainerregistry.Replication,
 		registryName,
 		replicationName,
 		armcontainerregistry.Replication{
-			Location: to.Ptr("eastus"),
+			Location: to.Ptr(pairedLocation),
 		},
 		nil,
 	)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create replication", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -151,32 +142,3 @@ func getReplication(ctx context.Context) (*armcontainerregistry.Replication, err
 	}
 	return &resp.Replication, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
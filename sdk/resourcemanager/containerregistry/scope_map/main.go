@@ -5,90 +5,79 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	registryName      = "sample2registry"
 	scopeMapName      = "sample-scope-map"
+	registrySKUName   = string(armcontainerregistry.SKUNamePremium)
 )
 
 var (
-	resourcesClientFactory         *armresources.ClientFactory
 	containerRegistryClientFactory *armcontainerregistry.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	registriesClient    *armcontainerregistry.RegistriesClient
-	scopeMapsClient     *armcontainerregistry.ScopeMapsClient
+	registriesClient *armcontainerregistry.RegistriesClient
+	scopeMapsClient  *armcontainerregistry.ScopeMapsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"registryName":      samplekit.Unique(&registryName, samplekit.ContainerRegistry),
+		"scopeMapName":      &scopeMapName,
+		"registrySKUName":   &registrySKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(subscriptionID, cred, nil)
+	containerRegistryClientFactory, err = armcontainerregistry.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	registriesClient = containerRegistryClientFactory.NewRegistriesClient()
 	scopeMapsClient = containerRegistryClientFactory.NewScopeMapsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ContainerRegistry"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	registry, err := createRegistry(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("registry:", *registry.ID)
 
 	scopeMap, err := createScopeMap(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("scope map:", *scopeMap.ID)
 
 	scopeMap, err = getScopeMap(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get scope map:", *scopeMap.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error) {
@@ -103,7 +92,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 				"key": to.Ptr("value"),
 			},
 			SKU: &armcontainerregistry.SKU{
-				Name: to.Ptr(armcontainerregistry.SKUNamePremium),
+				Name: to.Ptr(armcontainerregistry.SKUName(registrySKUName)),
 			},
 			Properties: &armcontainerregistry.RegistryProperties{
 				AdminUserEnabled: to.Ptr(true),
@@ -114,7 +103,7 @@ func createRegistry(ctx context.Context) (*armcontainerregistry.Registry, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create registry", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +131,7 @@ func createScopeMap(ctx context.Context) (*armcontainerregistry.ScopeMap, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create scope map", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -157,32 +146,3 @@ func getScopeMap(ctx context.Context) (*armcontainerregistry.ScopeMap, error) {
 	}
 	return &resp.ScopeMap, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
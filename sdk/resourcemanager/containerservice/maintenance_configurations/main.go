@@ -6,144 +6,136 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
 )
 
 var (
-	subscriptionID      string
-	objectID            string
+	clientID            string
 	clientSecret        string
 	location            = "westus"
 	resourceGroupName   = "sample-resource-group"
 	managedClustersName = "sample-aks-cluster"
 	configName          = "sample-aks-maintenance-config"
+	vmSize              = "Standard_DS2_v2"
 )
 
 var (
-	resourcesClientFactory        *armresources.ClientFactory
 	containerserviceClientFactory *armcontainerservice.ClientFactory
 )
 
 var (
-	resourceGroupClient             *armresources.ResourceGroupsClient
 	managedClustersClient           *armcontainerservice.ManagedClustersClient
 	maintenanceConfigurationsClient *armcontainerservice.MaintenanceConfigurationsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":            &location,
+		"resourceGroupName":   &resourceGroupName,
+		"managedClustersName": &managedClustersName,
+		"configName":          &configName,
+		"vmSize":              &vmSize,
+	}, run)
+}
 
-	objectID = os.Getenv("AZURE_OBJECT_ID")
-	if len(objectID) == 0 {
-		log.Fatal("AZURE_OBJECT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	clientSecret = os.Getenv("AZURE_CLIENT_SECRET")
-	if len(clientSecret) == 0 {
-		log.Fatal("AZURE_CLIENT_SECRET is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	clientID, err = samplekit.Getenv("AZURE_CLIENT_ID")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	ctx := context.Background()
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	clientSecret, err = samplekit.Getenv("AZURE_CLIENT_SECRET")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
 
-	containerserviceClientFactory, err = armcontainerservice.NewClientFactory(subscriptionID, cred, nil)
+	containerserviceClientFactory, err = armcontainerservice.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	managedClustersClient = containerserviceClientFactory.NewManagedClustersClient()
 	maintenanceConfigurationsClient = containerserviceClientFactory.NewMaintenanceConfigurationsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample;
+	// the node pool starts with one node
+	if err := sample.Preflight(ctx, samplekit.Needs{
+		Providers: []string{"Microsoft.ContainerService", "Microsoft.Compute", "Microsoft.Network"},
+		VMSizes:   []string{vmSize},
+	}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	managedCluster, err := createManagedCluster(ctx)
+	managedCluster, err := createManagedCluster(ctx, sample)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("managed cluster:", *managedCluster.ID)
 
 	maintenanceConfiguration, err := createMaintenanceConfiguration(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("maintenance configuration:", *maintenanceConfiguration.ID)
 
 	maintenanceConfigurations, err := listMaintenanceConfiguration(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("List By Managed Cluster:", managedClustersName)
 	for _, mc := range maintenanceConfigurations {
 		log.Printf("\t%s:%s", *mc.Name, *mc.ID)
 	}
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
-func createManagedCluster(ctx context.Context) (*armcontainerservice.ManagedCluster, error) {
-
-	pollerResp, err := managedClustersClient.BeginCreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		managedClustersName,
-		armcontainerservice.ManagedCluster{
-			Location: to.Ptr(location),
-			Properties: &armcontainerservice.ManagedClusterProperties{
-				DNSPrefix: to.Ptr("aksgosdk"),
-				AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
-					{
-						Name:              to.Ptr("askagent"),
-						Count:             to.Ptr[int32](1),
-						VMSize:            to.Ptr("Standard_DS2_v2"),
-						MaxPods:           to.Ptr[int32](110),
-						MinCount:          to.Ptr[int32](1),
-						MaxCount:          to.Ptr[int32](100),
-						OSType:            to.Ptr(armcontainerservice.OSTypeLinux),
-						Type:              to.Ptr(armcontainerservice.AgentPoolTypeVirtualMachineScaleSets),
-						EnableAutoScaling: to.Ptr(true),
-						Mode:              to.Ptr(armcontainerservice.AgentPoolModeSystem),
+func createManagedCluster(ctx context.Context, sample *samplekit.Sample) (*armcontainerservice.ManagedCluster, error) {
+
+	resp, err := samplekit.PollUntilDone(ctx, sample, "managed cluster "+managedClustersName, func(resumeToken string) (*runtime.Poller[armcontainerservice.ManagedClustersClientCreateOrUpdateResponse], error) {
+		return managedClustersClient.BeginCreateOrUpdate(
+			ctx,
+			resourceGroupName,
+			managedClustersName,
+			armcontainerservice.ManagedCluster{
+				Location: to.Ptr(location),
+				Properties: &armcontainerservice.ManagedClusterProperties{
+					DNSPrefix: to.Ptr("aksgosdk"),
+					AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+						{
+							Name:              to.Ptr("askagent"),
+							Count:             to.Ptr[int32](1),
+							VMSize:            to.Ptr(vmSize),
+							MaxPods:           to.Ptr[int32](110),
+							MinCount:          to.Ptr[int32](1),
+							MaxCount:          to.Ptr[int32](100),
+							OSType:            to.Ptr(armcontainerservice.OSTypeLinux),
+							Type:              to.Ptr(armcontainerservice.AgentPoolTypeVirtualMachineScaleSets),
+							EnableAutoScaling: to.Ptr(true),
+							Mode:              to.Ptr(armcontainerservice.AgentPoolModeSystem),
+						},
+					},
+					ServicePrincipalProfile: &armcontainerservice.ManagedClusterServicePrincipalProfile{
+						ClientID: to.Ptr(clientID),
+						Secret:   to.Ptr(clientSecret),
 					},
-				},
-				ServicePrincipalProfile: &armcontainerservice.ManagedClusterServicePrincipalProfile{
-					ClientID: to.Ptr(objectID),
-					Secret:   to.Ptr(clientSecret),
 				},
 			},
-		},
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+			&armcontainerservice.ManagedClustersClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -152,11 +144,11 @@ func createManagedCluster(ctx context.Context) (*armcontainerservice.ManagedClus
 
 func createMaintenanceConfiguration(ctx context.Context) (*armcontainerservice.MaintenanceConfiguration, error) {
 
-	start, err := time.Parse("2006-01-02 15:04:05 06", "2021-09-25T13:00:00Z")
+	start, err := time.Parse(time.RFC3339, "2021-09-25T13:00:00Z")
 	if err != nil {
 		return nil, err
 	}
-	end, err := time.Parse("2006-01-02 15:04:05 06", "2021-09-25T14:00:00Z")
+	end, err := time.Parse(time.RFC3339, "2021-09-25T14:00:00Z")
 	if err != nil {
 		return nil, err
 	}
@@ -197,32 +189,3 @@ func listMaintenanceConfiguration(ctx context.Context) ([]*armcontainerservice.M
 	}
 	return maintenanceConfigurations, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
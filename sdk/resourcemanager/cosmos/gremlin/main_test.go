@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
+	cosmosfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestGremlin(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	cosmosServer := cosmosfake.ServerFactory{
+		DatabaseAccountsServer: cosmosfake.DatabaseAccountsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, accountName string, createUpdateParameters armcosmos.DatabaseAccountCreateUpdateParameters, options *armcosmos.DatabaseAccountsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armcosmos.DatabaseAccountsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if createUpdateParameters.Location == nil || *createUpdateParameters.Location != location {
+					t.Errorf("unexpected location: %v", createUpdateParameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armcosmos.DatabaseAccountsClientCreateOrUpdateResponse{
+					DatabaseAccountGetResults: armcosmos.DatabaseAccountGetResults{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+					},
+				}, nil)
+				return
+			},
+		},
+		GremlinResourcesServer: cosmosfake.GremlinResourcesServer{
+			BeginCreateUpdateGremlinDatabase: func(ctx context.Context, resourceGroupName string, accountName string, databaseName string, createUpdateGremlinDatabaseParameters armcosmos.GremlinDatabaseCreateUpdateParameters, options *armcosmos.GremlinResourcesClientBeginCreateUpdateGremlinDatabaseOptions) (resp azfake.PollerResponder[armcosmos.GremlinResourcesClientCreateUpdateGremlinDatabaseResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armcosmos.GremlinResourcesClientCreateUpdateGremlinDatabaseResponse{
+					GremlinDatabaseGetResults: armcosmos.GremlinDatabaseGetResults{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s/gremlinDatabases/%s", subscriptionID, resourceGroupName, accountName, databaseName)),
+						Name: to.Ptr(databaseName),
+					},
+				}, nil)
+				return
+			},
+			BeginCreateUpdateGremlinGraph: func(ctx context.Context, resourceGroupName string, accountName string, databaseName string, graphName string, createUpdateGremlinGraphParameters armcosmos.GremlinGraphCreateUpdateParameters, options *armcosmos.GremlinResourcesClientBeginCreateUpdateGremlinGraphOptions) (resp azfake.PollerResponder[armcosmos.GremlinResourcesClientCreateUpdateGremlinGraphResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armcosmos.GremlinResourcesClientCreateUpdateGremlinGraphResponse{
+					GremlinGraphGetResults: armcosmos.GremlinGraphGetResults{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s/gremlinDatabases/%s/graphs/%s", subscriptionID, resourceGroupName, accountName, databaseName, graphName)),
+						Name: to.Ptr(graphName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	cosmosClientFactory, err = armcosmos.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: cosmosfake.NewServerFactoryTransport(&cosmosServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	databaseAccountsClient = cosmosClientFactory.NewDatabaseAccountsClient()
+	gremlinResourcesClient = cosmosClientFactory.NewGremlinResourcesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	databaseAccount, err := createDatabaseAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("cosmos database account:", *databaseAccount.ID)
+
+	gremlinDatabase, err := createGremlinDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("cosmos gremlin database:", *gremlinDatabase.ID)
+
+	gremlinGraph, err := createGremlinGraph(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("cosmos gremlin graph:", *gremlinGraph.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	accountName       = "sample-cosmos-mongodb"
@@ -23,79 +20,70 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	cosmosClientFactory    *armcosmos.ClientFactory
+	cosmosClientFactory *armcosmos.ClientFactory
 )
 
 var (
-	resourceGroupClient    *armresources.ResourceGroupsClient
 	databaseAccountsClient *armcosmos.DatabaseAccountsClient
 	mongoDBResourcesClient *armcosmos.MongoDBResourcesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"accountName":       samplekit.Unique(&accountName, samplekit.CosmosDBAccount),
+		"mongodbName":       &mongodbName,
+		"collectionName":    &collectionName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cosmosClientFactory, err = armcosmos.NewClientFactory(subscriptionID, cred, nil)
+	cosmosClientFactory, err = armcosmos.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	databaseAccountsClient = cosmosClientFactory.NewDatabaseAccountsClient()
 	mongoDBResourcesClient = cosmosClientFactory.NewMongoDBResourcesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DocumentDB"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	databaseAccount, err := createDatabaseAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos database account:", *databaseAccount.ID)
 
 	mongodbDatabase, err := createMongoDBDatabase(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos mongodb:", *mongodbDatabase.ID)
 
 	getMongodb, err := getMongoDB(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get cosmos mongodb:", *getMongodb.ID)
 
 	mongodbCollection, err := createMongoDBCollection(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos mongodb collection:", *mongodbCollection.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDatabaseAccount(ctx context.Context) (*armcosmos.DatabaseAccountGetResults, error) {
@@ -123,7 +111,7 @@ func createDatabaseAccount(ctx context.Context) (*armcosmos.DatabaseAccountGetRe
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create database account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +142,7 @@ func createMongoDBDatabase(ctx context.Context) (*armcosmos.MongoDBDatabaseGetRe
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create MongoDB database", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +177,7 @@ func createMongoDBCollection(ctx context.Context) (*armcosmos.MongoDBCollectionG
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create MongoDB collection", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -205,32 +193,3 @@ func getMongoDB(ctx context.Context) (*armcosmos.MongoDBDatabaseGetResults, erro
 
 	return &resp.MongoDBDatabaseGetResults, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	accountName       = "sample-cosmos-account"
@@ -23,73 +20,64 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	cosmosClientFactory    *armcosmos.ClientFactory
+	cosmosClientFactory *armcosmos.ClientFactory
 )
 
 var (
-	resourceGroupClient    *armresources.ResourceGroupsClient
 	databaseAccountsClient *armcosmos.DatabaseAccountsClient
 	sqlResourcesClient     *armcosmos.SQLResourcesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"accountName":       samplekit.Unique(&accountName, samplekit.CosmosDBAccount),
+		"databaseName":      &databaseName,
+		"containerName":     &containerName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cosmosClientFactory, err = armcosmos.NewClientFactory(subscriptionID, cred, nil)
+	cosmosClientFactory, err = armcosmos.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	databaseAccountsClient = cosmosClientFactory.NewDatabaseAccountsClient()
 	sqlResourcesClient = cosmosClientFactory.NewSQLResourcesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DocumentDB"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	databaseAccount, err := createDatabaseAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos database account:", *databaseAccount.ID)
 
 	sqlDatabase, err := createSqlDatabase(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos sql database:", *sqlDatabase.ID)
 
 	sqlContainer, err := createSqlContainer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cosmos sql container:", *sqlContainer.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDatabaseAccount(ctx context.Context) (*armcosmos.DatabaseAccountGetResults, error) {
@@ -118,7 +106,7 @@ func createDatabaseAccount(ctx context.Context) (*armcosmos.DatabaseAccountGetRe
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create database account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +137,7 @@ func createSqlDatabase(ctx context.Context) (*armcosmos.SQLDatabaseGetResults, e
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create SQL database", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -223,38 +211,9 @@ func createSqlContainer(ctx context.Context) (*armcosmos.SQLContainerGetResults,
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create SQL container", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.SQLContainerGetResults, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v3"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	factoryName       = "sample-data2factory"
@@ -22,73 +19,63 @@ var (
 )
 
 var (
-	resourcesClientFactory   *armresources.ClientFactory
 	datafactoryClientFactory *armdatafactory.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	factoriesClient     *armdatafactory.FactoriesClient
-	dataFlowsClient     *armdatafactory.DataFlowsClient
+	factoriesClient *armdatafactory.FactoriesClient
+	dataFlowsClient *armdatafactory.DataFlowsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"factoryName":       samplekit.Unique(&factoryName, samplekit.DataFactory),
+		"dataflowName":      &dataflowName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	datafactoryClientFactory, err = armdatafactory.NewClientFactory(subscriptionID, cred, nil)
+	datafactoryClientFactory, err = armdatafactory.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	factoriesClient = datafactoryClientFactory.NewFactoriesClient()
 	dataFlowsClient = datafactoryClientFactory.NewDataFlowsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DataFactory"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	dataFactory, err := createDataFactory(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("data factory:", *dataFactory.ID)
 
 	dataFlow, err := createDataFlow(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("data flow:", *dataFlow.ID)
 
 	dataFlow, err = getDataFlow(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get data flow:", *dataFlow.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDataFactory(ctx context.Context) (*armdatafactory.Factory, error) {
@@ -142,33 +129,3 @@ func getDataFlow(ctx context.Context) (*armdatafactory.DataFlowResource, error)
 
 	return &resp.DataFlowResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -6,90 +6,76 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v3"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	factoryName       = "sample-data2factory"
 )
 
 var (
-	resourcesClientFactory   *armresources.ClientFactory
 	datafactoryClientFactory *armdatafactory.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	factoriesClient     *armdatafactory.FactoriesClient
+	factoriesClient *armdatafactory.FactoriesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"factoryName":       samplekit.Unique(&factoryName, samplekit.DataFactory),
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	datafactoryClientFactory, err = armdatafactory.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	factoriesClient = datafactoryClientFactory.NewFactoriesClient()
 
-	datafactoryClientFactory, err = armdatafactory.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DataFactory"}}); err != nil {
+		return err
 	}
-	factoriesClient = datafactoryClientFactory.NewFactoriesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	dataFactory, err := createDataFactory(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("data factory:", *dataFactory.ID)
 
 	dataFactory, err = getDataFactory(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get data factory:", *dataFactory.ID)
 
 	factories, err := getFactories(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("list data factory:size(%d)\n", len(factories))
 	for _, f := range factories {
 		fmt.Printf("\t%v\n", *f.ID)
 	}
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDataFactory(ctx context.Context) (*armdatafactory.Factory, error) {
@@ -142,34 +128,3 @@ func getFactories(ctx context.Context) ([]*armdatafactory.Factory, error) {
 	}
 	return factories, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v4"
+	datafactoryfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v4/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestFactories(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	datafactoryServer := datafactoryfake.ServerFactory{
+		FactoriesServer: datafactoryfake.FactoriesServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, factoryName string, factory armdatafactory.Factory, options *armdatafactory.FactoriesClientCreateOrUpdateOptions) (resp azfake.Responder[armdatafactory.FactoriesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if factory.Location == nil || *factory.Location != location {
+					t.Errorf("unexpected location: %v", factory.Location)
+				}
+				resp.SetResponse(http.StatusOK, armdatafactory.FactoriesClientCreateOrUpdateResponse{
+					Factory: armdatafactory.Factory{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DataFactory/factories/%s", subscriptionID, resourceGroupName, factoryName)),
+						Name: to.Ptr(factoryName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, factoryName string, options *armdatafactory.FactoriesClientGetOptions) (resp azfake.Responder[armdatafactory.FactoriesClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armdatafactory.FactoriesClientGetResponse{
+					Factory: armdatafactory.Factory{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DataFactory/factories/%s", subscriptionID, resourceGroupName, factoryName)),
+						Name: to.Ptr(factoryName),
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armdatafactory.FactoriesClientListOptions) (resp azfake.PagerResponder[armdatafactory.FactoriesClientListResponse]) {
+				resp.AddPage(http.StatusOK, armdatafactory.FactoriesClientListResponse{
+					FactoryListResponse: armdatafactory.FactoryListResponse{
+						Value: []*armdatafactory.Factory{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.DataFactory/factories/%s", subscriptionID, factoryName)),
+								Name: to.Ptr(factoryName),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	datafactoryClientFactory, err = armdatafactory.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: datafactoryfake.NewServerFactoryTransport(&datafactoryServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	factoriesClient = datafactoryClientFactory.NewFactoriesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	dataFactory, err := createDataFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("data factory:", *dataFactory.ID)
+
+	dataFactory, err = getDataFactory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get data factory:", *dataFactory.ID)
+
+	factories, err := getFactories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("list data factory:size(%d)\n", len(factories))
+	for _, f := range factories {
+		fmt.Printf("\t%v\n", *f.ID)
+	}
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
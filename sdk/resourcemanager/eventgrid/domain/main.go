@@ -5,95 +5,81 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventgrid/armeventgrid/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	domainName        = "sample-domain"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
 	eventgridClientFactory *armeventgrid.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	domainsClient       *armeventgrid.DomainsClient
+	domainsClient *armeventgrid.DomainsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"domainName":        &domainName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	eventgridClientFactory, err = armeventgrid.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	domainsClient = eventgridClientFactory.NewDomainsClient()
 
-	eventgridClientFactory, err = armeventgrid.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.EventGrid"}}); err != nil {
+		return err
 	}
-	domainsClient = eventgridClientFactory.NewDomainsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	domain, err := createDomain(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("domain:", *domain.ID)
 
 	domain, err = getDomain(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get domain:", *domain.ID)
 
 	keys, err := regenerateKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("regenerate key:", *keys.Key1, *keys.Key2)
 
 	domains, err := listDomain(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for _, d := range domains {
 		log.Println(*d.Name, *d.ID)
 	}
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createDomain(ctx context.Context) (*armeventgrid.Domain, error) {
@@ -110,7 +96,7 @@ func createDomain(ctx context.Context) (*armeventgrid.Domain, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create domain", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -157,32 +143,3 @@ func listDomain(ctx context.Context) ([]*armeventgrid.Domain, error) {
 	}
 	return domains, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,156 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventgrid/armeventgrid/v2"
+	eventgridfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventgrid/armeventgrid/v2/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestDomain(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	eventgridServer := eventgridfake.ServerFactory{
+		DomainsServer: eventgridfake.DomainsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, domainName string, domainInfo armeventgrid.Domain, options *armeventgrid.DomainsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armeventgrid.DomainsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if domainInfo.Location == nil || *domainInfo.Location != location {
+					t.Errorf("unexpected location: %v", domainInfo.Location)
+				}
+				resp.SetTerminalResponse(http.StatusCreated, armeventgrid.DomainsClientCreateOrUpdateResponse{
+					Domain: armeventgrid.Domain{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventGrid/domains/%s", subscriptionID, resourceGroupName, domainName)),
+						Name: to.Ptr(domainName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, domainName string, options *armeventgrid.DomainsClientGetOptions) (resp azfake.Responder[armeventgrid.DomainsClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armeventgrid.DomainsClientGetResponse{
+					Domain: armeventgrid.Domain{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventGrid/domains/%s", subscriptionID, resourceGroupName, domainName)),
+						Name: to.Ptr(domainName),
+					},
+				}, nil)
+				return
+			},
+			RegenerateKey: func(ctx context.Context, resourceGroupName string, domainName string, regenerateKeyRequest armeventgrid.DomainRegenerateKeyRequest, options *armeventgrid.DomainsClientRegenerateKeyOptions) (resp azfake.Responder[armeventgrid.DomainsClientRegenerateKeyResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armeventgrid.DomainsClientRegenerateKeyResponse{
+					DomainSharedAccessKeys: armeventgrid.DomainSharedAccessKeys{
+						Key1: to.Ptr("sample-key1"),
+						Key2: to.Ptr("sample-key2"),
+					},
+				}, nil)
+				return
+			},
+			NewListByResourceGroupPager: func(resourceGroupName string, options *armeventgrid.DomainsClientListByResourceGroupOptions) (resp azfake.PagerResponder[armeventgrid.DomainsClientListByResourceGroupResponse]) {
+				resp.AddPage(http.StatusOK, armeventgrid.DomainsClientListByResourceGroupResponse{
+					DomainsListResult: armeventgrid.DomainsListResult{
+						Value: []*armeventgrid.Domain{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventGrid/domains/%s", subscriptionID, resourceGroupName, domainName)),
+								Name: to.Ptr(domainName),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	eventgridClientFactory, err = armeventgrid.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: eventgridfake.NewServerFactoryTransport(&eventgridServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	domainsClient = eventgridClientFactory.NewDomainsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	domain, err := createDomain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("domain:", *domain.ID)
+
+	domain, err = getDomain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get domain:", *domain.ID)
+
+	keys, err := regenerateKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("regenerate key:", *keys.Key1, *keys.Key2)
+
+	domains, err := listDomain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range domains {
+		t.Log(*d.Name, *d.ID)
+	}
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,75 +5,63 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	clusterName       = "sample-cluster"
+	clusterSKUName    = string(armeventhub.ClusterSKUNameDedicated)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	eventhubClientFactory  *armeventhub.ClientFactory
+	eventhubClientFactory *armeventhub.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	clustersClient      *armeventhub.ClustersClient
+	clustersClient *armeventhub.ClustersClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"clusterName":       &clusterName,
+		"clusterSKUName":    &clusterSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	eventhubClientFactory, err = armeventhub.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	clustersClient = eventhubClientFactory.NewClustersClient()
 
-	eventhubClientFactory, err = armeventhub.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.EventHub"}}); err != nil {
+		return err
 	}
-	clustersClient = eventhubClientFactory.NewClustersClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	cluster, err := createCluster(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("cluster:", *cluster.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createCluster(ctx context.Context) (*armeventhub.Cluster, error) {
@@ -85,7 +73,7 @@ func createCluster(ctx context.Context) (*armeventhub.Cluster, error) {
 		armeventhub.Cluster{
 			Location: to.Ptr(location),
 			SKU: &armeventhub.ClusterSKU{
-				Name:     to.Ptr(armeventhub.ClusterSKUNameDedicated),
+				Name:     to.Ptr(armeventhub.ClusterSKUName(clusterSKUName)),
 				Capacity: to.Ptr[int32](3),
 			},
 		},
@@ -94,38 +82,9 @@ func createCluster(ctx context.Context) (*armeventhub.Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create cluster", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Cluster, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,175 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
+	eventhubfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	storagefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage/fake"
+)
+
+func TestConsumerGroups(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	storageServer := storagefake.ServerFactory{
+		AccountsServer: storagefake.AccountsServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, accountName string, parameters armstorage.AccountCreateParameters, options *armstorage.AccountsClientBeginCreateOptions) (resp azfake.PollerResponder[armstorage.AccountsClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armstorage.AccountsClientCreateResponse{
+					Account: armstorage.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+	eventhubServer := eventhubfake.ServerFactory{
+		NamespacesServer: eventhubfake.NamespacesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, parameters armeventhub.EHNamespace, options *armeventhub.NamespacesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armeventhub.NamespacesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armeventhub.NamespacesClientCreateOrUpdateResponse{
+					EHNamespace: armeventhub.EHNamespace{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventHub/namespaces/%s", subscriptionID, resourceGroupName, namespaceName)),
+						Name: to.Ptr(namespaceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		EventHubsServer: eventhubfake.EventHubsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, eventHubName string, parameters armeventhub.Eventhub, options *armeventhub.EventHubsClientCreateOrUpdateOptions) (resp azfake.Responder[armeventhub.EventHubsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armeventhub.EventHubsClientCreateOrUpdateResponse{
+					Eventhub: armeventhub.Eventhub{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventHub/namespaces/%s/eventhubs/%s", subscriptionID, resourceGroupName, namespaceName, eventHubName)),
+						Name: to.Ptr(eventHubName),
+					},
+				}, nil)
+				return
+			},
+		},
+		ConsumerGroupsServer: eventhubfake.ConsumerGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, eventHubName string, consumerGroupName string, parameters armeventhub.ConsumerGroup, options *armeventhub.ConsumerGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armeventhub.ConsumerGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armeventhub.ConsumerGroupsClientCreateOrUpdateResponse{
+					ConsumerGroup: armeventhub.ConsumerGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.EventHub/namespaces/%s/eventhubs/%s/consumergroups/%s", subscriptionID, resourceGroupName, namespaceName, eventHubName, consumerGroupName)),
+						Name: to.Ptr(consumerGroupName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: storagefake.NewServerFactoryTransport(&storageServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountsClient = storageClientFactory.NewAccountsClient()
+
+	eventhubClientFactory, err = armeventhub.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: eventhubfake.NewServerFactoryTransport(&eventhubServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespacesClient = eventhubClientFactory.NewNamespacesClient()
+	eventHubsClient = eventhubClientFactory.NewEventHubsClient()
+	consumerGroupsClient = eventhubClientFactory.NewConsumerGroupsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	storageAccount, err := createStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("storage account:", *storageAccount.ID)
+
+	namespace, err := createNamespace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("eventhub namespace:", *namespace.ID)
+
+	eventhub, err := createEventHub(ctx, *storageAccount.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("eventhub:", *eventhub.ID)
+
+	consumerGroup, err := createConsumerGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("consumer group:", *consumerGroup.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
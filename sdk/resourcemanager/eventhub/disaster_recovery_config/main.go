@@ -5,116 +5,108 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID             string
 	location                   = "westus"
+	pairedLocation             = "eastus"
 	resourceGroupName          = "sample-resource-group"
 	namespacesName             = "sample1namespace"
 	secondNamespacesName       = "sample1second1namespace"
 	disasterRecoveryConfigName = "sample-disaster-recovery-config"
+	namespaceSKUName           = string(armeventhub.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	eventhubClientFactory  *armeventhub.ClientFactory
+	eventhubClientFactory *armeventhub.ClientFactory
 )
 
 var (
-	resourceGroupClient           *armresources.ResourceGroupsClient
 	namespacesClient              *armeventhub.NamespacesClient
 	disasterRecoveryConfigsClient *armeventhub.DisasterRecoveryConfigsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":                   &location,
+		"pairedLocation":             &pairedLocation,
+		"resourceGroupName":          &resourceGroupName,
+		"namespacesName":             samplekit.Unique(&namespacesName, samplekit.EventHubNamespace),
+		"secondNamespacesName":       samplekit.Unique(&secondNamespacesName, samplekit.EventHubNamespace),
+		"disasterRecoveryConfigName": &disasterRecoveryConfigName,
+		"namespaceSKUName":           &namespaceSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	eventhubClientFactory, err = armeventhub.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	eventhubClientFactory, err = armeventhub.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	namespacesClient = eventhubClientFactory.NewNamespacesClient()
 	disasterRecoveryConfigsClient = eventhubClientFactory.NewDisasterRecoveryConfigsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.EventHub"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	namespace, err := createNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("eventhub namespace:", *namespace.ID)
 
 	secondNamespace, err := createSecondNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("eventhub second namespace:", *secondNamespace.ID)
 
 	ava, err := checkNameAva(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("check name availability:", *ava.NameAvailable)
 
 	disasterRecoveryConfig, err := createDisasterRecoveryConfig(ctx, *secondNamespace.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("disaster recovery config:", *disasterRecoveryConfig.ID)
 
 	disasterRecoveryConfig, err = getDisasterRecoveryConfig(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get disaster recovery config:", *disasterRecoveryConfig.ID)
 
 	// Only after breakPairing or failOVer can clean resource
 	err = breakPairingDisasterRecoveryConfig(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("break pairing")
 
 	//failOver, err := failOverDisasterRecoveryConfig(ctx, conn)
 	//if err != nil {
-	//	log.Fatal(err)
+	//	return err
 	//}
 	//log.Println("fail over:", *failOver)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
@@ -130,7 +122,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 				"tag2": to.Ptr("value2"),
 			},
 			SKU: &armeventhub.SKU{
-				Name: to.Ptr(armeventhub.SKUNameStandard),
+				Name: to.Ptr(armeventhub.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armeventhub.SKUTierStandard),
 			},
 		},
@@ -139,7 +131,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -153,13 +145,13 @@ func createSecondNamespace(ctx context.Context) (*armeventhub.EHNamespace, error
 		resourceGroupName,
 		secondNamespacesName,
 		armeventhub.EHNamespace{
-			Location: to.Ptr("eastus"),
+			Location: to.Ptr(pairedLocation),
 			Tags: map[string]*string{
 				"tag1": to.Ptr("value1"),
 				"tag2": to.Ptr("value2"),
 			},
 			SKU: &armeventhub.SKU{
-				Name: to.Ptr(armeventhub.SKUNameStandard),
+				Name: to.Ptr(armeventhub.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armeventhub.SKUTierStandard),
 			},
 		},
@@ -168,7 +160,7 @@ func createSecondNamespace(ctx context.Context) (*armeventhub.EHNamespace, error
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create second namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,32 +221,3 @@ func breakPairingDisasterRecoveryConfig(ctx context.Context) error {
 	}
 	return nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,100 +5,92 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "westus"
-	resourceGroupName  = "sample-resource-group"
-	storageAccountName = "sample1storage"
-	namespacesName     = "sample1namespace"
-	eventHubName       = "sample-eventhub"
+	location              = "westus"
+	resourceGroupName     = "sample-resource-group"
+	storageAccountName    = "sample1storage"
+	namespacesName        = "sample1namespace"
+	eventHubName          = "sample-eventhub"
+	storageAccountSKUName = string(armstorage.SKUNameStandardLRS)
+	namespaceSKUName      = string(armeventhub.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	eventhubClientFactory  *armeventhub.ClientFactory
-	storageClientFactory   *armstorage.ClientFactory
+	eventhubClientFactory *armeventhub.ClientFactory
+	storageClientFactory  *armstorage.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	accountsClient      *armstorage.AccountsClient
-	namespacesClient    *armeventhub.NamespacesClient
-	eventHubsClient     *armeventhub.EventHubsClient
+	accountsClient   *armstorage.AccountsClient
+	namespacesClient *armeventhub.NamespacesClient
+	eventHubsClient  *armeventhub.EventHubsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"storageAccountName":    samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+		"namespacesName":        samplekit.Unique(&namespacesName, samplekit.EventHubNamespace),
+		"eventHubName":          &eventHubName,
+		"storageAccountSKUName": &storageAccountSKUName,
+		"namespaceSKUName":      &namespaceSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountsClient = storageClientFactory.NewAccountsClient()
 
-	eventhubClientFactory, err = armeventhub.NewClientFactory(subscriptionID, cred, nil)
+	eventhubClientFactory, err = armeventhub.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	namespacesClient = eventhubClientFactory.NewNamespacesClient()
 	eventHubsClient = eventhubClientFactory.NewEventHubsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.EventHub", "Microsoft.Storage"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	storageAccount, err := createStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage account:", *storageAccount.ID)
 
 	namespace, err := createNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("eventhub namespace:", *namespace.ID)
 
 	eventhub, err := createEventHub(ctx, *storageAccount.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("eventhub:", *eventhub.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
@@ -110,14 +102,14 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 		armstorage.AccountCreateParameters{
 			Kind: to.Ptr(armstorage.KindStorageV2),
 			SKU: &armstorage.SKU{
-				Name: to.Ptr(armstorage.SKUNameStandardLRS),
+				Name: to.Ptr(armstorage.SKUName(storageAccountSKUName)),
 			},
 			Location: to.Ptr(location),
 		}, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create storage account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +129,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 				"tag2": to.Ptr("value2"),
 			},
 			SKU: &armeventhub.SKU{
-				Name: to.Ptr(armeventhub.SKUNameStandard),
+				Name: to.Ptr(armeventhub.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armeventhub.SKUTierStandard),
 			},
 		},
@@ -146,7 +138,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -188,32 +180,3 @@ func createEventHub(ctx context.Context, storageAccountID string) (*armeventhub.
 	}
 	return &resp.Eventhub, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
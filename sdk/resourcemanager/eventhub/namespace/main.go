@@ -5,81 +5,69 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	namespacesName    = "sample1namespace"
+	namespaceSKUName  = string(armeventhub.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	eventhubClientFactory  *armeventhub.ClientFactory
+	eventhubClientFactory *armeventhub.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	namespacesClient    *armeventhub.NamespacesClient
+	namespacesClient *armeventhub.NamespacesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"namespacesName":    samplekit.Unique(&namespacesName, samplekit.EventHubNamespace),
+		"namespaceSKUName":  &namespaceSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	eventhubClientFactory, err = armeventhub.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	namespacesClient = eventhubClientFactory.NewNamespacesClient()
 
-	eventhubClientFactory, err = armeventhub.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.EventHub"}}); err != nil {
+		return err
 	}
-	namespacesClient = eventhubClientFactory.NewNamespacesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	namespace, err := createNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("eventhub namespace:", *namespace.ID)
 
 	namespace, err = getNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get eventhub namespace:", *namespace.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
@@ -95,7 +83,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 				"tag2": to.Ptr("value2"),
 			},
 			SKU: &armeventhub.SKU{
-				Name: to.Ptr(armeventhub.SKUNameStandard),
+				Name: to.Ptr(armeventhub.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armeventhub.SKUTierStandard),
 			},
 		},
@@ -104,7 +92,7 @@ func createNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -119,32 +107,3 @@ func getNamespace(ctx context.Context) (*armeventhub.EHNamespace, error) {
 	}
 	return &resp.EHNamespace, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
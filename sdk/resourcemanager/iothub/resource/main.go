@@ -9,90 +9,78 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/iothub/armiothub"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID    string
-	location          = "eastus"
-	resourceGroupName = "sample-resource-group"
-	iotHubName        = "sample-iothub"
+	location              = "eastus"
+	resourceGroupName     = "sample-resource-group"
+	iotHubName            = "sample-iothub"
+	iotHubResourceSKUName = string(armiothub.IotHubSKUS1)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	iothubClientFactory    *armiothub.ClientFactory
+	iothubClientFactory *armiothub.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	resourceClient      *armiothub.ResourceClient
+	resourceClient *armiothub.ResourceClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"iotHubName":            samplekit.Unique(&iotHubName, samplekit.IoTHub),
+		"iotHubResourceSKUName": &iotHubResourceSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	iothubClientFactory, err = armiothub.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	resourceClient = iothubClientFactory.NewResourceClient()
 
-	iothubClientFactory, err = armiothub.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Devices"}}); err != nil {
+		return err
 	}
-	resourceClient = iothubClientFactory.NewResourceClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	iothubResource, err := createIotHubResource(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("iothub resource:", *iothubResource.ID)
 
 	iothubResource, err = getIotHubResource(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get iothub resource:", *iothubResource.ID)
 
 	iothubStats, err := getIotHubStats(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("get iothub resource stats:%v\n", *iothubStats)
 
 	endpointHealths := getIotHubEndpointHealth(ctx)
 	log.Println("get iothub resource endpoint health:", endpointHealths)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createIotHubResource(ctx context.Context) (*armiothub.Description, error) {
@@ -104,7 +92,7 @@ func createIotHubResource(ctx context.Context) (*armiothub.Description, error) {
 		armiothub.Description{
 			Location: to.Ptr(location),
 			SKU: &armiothub.SKUInfo{
-				Name:     to.Ptr(armiothub.IotHubSKUS1),
+				Name:     to.Ptr(armiothub.IotHubSKU(iotHubResourceSKUName)),
 				Capacity: to.Ptr[int64](1),
 			},
 			Properties: &armiothub.Properties{
@@ -145,7 +133,7 @@ func createIotHubResource(ctx context.Context) (*armiothub.Description, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create IoT Hub resource", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -187,33 +175,3 @@ func getIotHubStats(ctx context.Context) (*armiothub.RegistryStatistics, error)
 
 	return &resp.RegistryStatistics, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,145 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/iothub/armiothub"
+	iothubfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/iothub/armiothub/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestResource(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	iothubServer := iothubfake.ServerFactory{
+		ResourceServer: iothubfake.ResourceServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, resourceName string, iotHubDescription armiothub.Description, options *armiothub.ResourceClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armiothub.ResourceClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if iotHubDescription.Location == nil || *iotHubDescription.Location != location {
+					t.Errorf("unexpected location: %v", iotHubDescription.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armiothub.ResourceClientCreateOrUpdateResponse{
+					Description: armiothub.Description{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Devices/IotHubs/%s", subscriptionID, resourceGroupName, resourceName)),
+						Name: to.Ptr(resourceName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, resourceName string, options *armiothub.ResourceClientGetOptions) (resp azfake.Responder[armiothub.ResourceClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armiothub.ResourceClientGetResponse{
+					Description: armiothub.Description{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Devices/IotHubs/%s", subscriptionID, resourceGroupName, resourceName)),
+						Name: to.Ptr(resourceName),
+					},
+				}, nil)
+				return
+			},
+			NewGetEndpointHealthPager: func(resourceGroupName string, iotHubName string, options *armiothub.ResourceClientGetEndpointHealthOptions) (resp azfake.PagerResponder[armiothub.ResourceClientGetEndpointHealthResponse]) {
+				resp.AddPage(http.StatusOK, armiothub.ResourceClientGetEndpointHealthResponse{
+					EndpointHealthDataListResult: armiothub.EndpointHealthDataListResult{
+						Value: []*armiothub.EndpointHealthData{
+							{},
+						},
+					},
+				}, nil)
+				return
+			},
+			GetStats: func(ctx context.Context, resourceGroupName string, resourceName string, options *armiothub.ResourceClientGetStatsOptions) (resp azfake.Responder[armiothub.ResourceClientGetStatsResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armiothub.ResourceClientGetStatsResponse{
+					RegistryStatistics: armiothub.RegistryStatistics{},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	iothubClientFactory, err = armiothub.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: iothubfake.NewServerFactoryTransport(&iothubServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resourceClient = iothubClientFactory.NewResourceClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	iothubResource, err := createIotHubResource(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("iothub resource:", *iothubResource.ID)
+
+	iothubResource, err = getIotHubResource(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get iothub resource:", *iothubResource.ID)
+
+	iothubStats, err := getIotHubStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("get iothub resource stats:%v\n", *iothubStats)
+
+	endpointHealths := getIotHubEndpointHealth(ctx)
+	t.Log("get iothub resource endpoint health:", endpointHealths)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,90 +5,76 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	TenantID          string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	vaultName         = "sample2vault"
 	keyName           = "sample2key"
+	vaultSKUName      = string(armkeyvault.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	keyvaultClientFactory  *armkeyvault.ClientFactory
+	keyvaultClientFactory *armkeyvault.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	vaultsClient        *armkeyvault.VaultsClient
-	keysClient          *armkeyvault.KeysClient
+	vaultsClient *armkeyvault.VaultsClient
+	keysClient   *armkeyvault.KeysClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"vaultName":         samplekit.Unique(&vaultName, samplekit.KeyVault),
+		"keyName":           &keyName,
+		"vaultSKUName":      &vaultSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	TenantID = sample.TenantID
 
-	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, nil)
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	vaultsClient = keyvaultClientFactory.NewVaultsClient()
 	keysClient = keyvaultClientFactory.NewKeysClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.KeyVault"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	vault, err := createVault(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("vault:", *vault.ID)
 
 	key, err := createKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("key:", *key.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
@@ -102,7 +88,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 			Properties: &armkeyvault.VaultProperties{
 				SKU: &armkeyvault.SKU{
 					Family: to.Ptr(armkeyvault.SKUFamilyA),
-					Name:   to.Ptr(armkeyvault.SKUNameStandard),
+					Name:   to.Ptr(armkeyvault.SKUName(vaultSKUName)),
 				},
 				TenantID:       to.Ptr(TenantID),
 				AccessPolicies: []*armkeyvault.AccessPolicyEntry{},
@@ -114,7 +100,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create vault", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -149,32 +135,3 @@ func createKey(ctx context.Context) (*armkeyvault.Key, error) {
 
 	return &secretResp.Key, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
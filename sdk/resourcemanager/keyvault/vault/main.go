@@ -5,124 +5,130 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	TenantID          string
 	ObjectID          string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	vaultName         = "sample2vaultalan"
+	managedHsmName    = "sample-hsmsxx"
+	vaultSKUName      = string(armkeyvault.SKUNameStandard)
+	managedHsmSKUName = string(armkeyvault.ManagedHsmSKUNameStandardB1)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	keyvaultClientFactory  *armkeyvault.ClientFactory
+	keyvaultClientFactory *armkeyvault.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	vaultsClient        *armkeyvault.VaultsClient
-	managedHsmsClient   *armkeyvault.ManagedHsmsClient
+	vaultsClient      *armkeyvault.VaultsClient
+	managedHsmsClient *armkeyvault.ManagedHsmsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"vaultName":         samplekit.Unique(&vaultName, samplekit.KeyVault),
+		"vaultSKUName":      &vaultSKUName,
+		"managedHsmSKUName": &managedHsmSKUName,
+	}, run, samplekit.WithReuse())
+}
 
-	ObjectID = os.Getenv("AZURE_OBJECT_ID")
-	if len(ObjectID) == 0 {
-		log.Fatal("AZURE_OBJECT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	// the IDs of the principal the sample signed in as
+	TenantID = sample.TenantID
+	ObjectID = sample.ObjectID
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	vaultsClient = keyvaultClientFactory.NewVaultsClient()
 	managedHsmsClient = keyvaultClientFactory.NewManagedHsmsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.KeyVault"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	vault, err := createVault(ctx)
+	// in reuse mode, the vault of a previous run is used, or recovered if soft-deleted
+	vault, err := samplekit.EnsureSoftDeleted(ctx, sample, "vault "+vaultName, getVault, func(ctx context.Context) (*armkeyvault.Vault, error) {
+		return createVault(ctx, armkeyvault.CreateModeDefault)
+	}, samplekit.SoftDelete[*armkeyvault.Vault]{
+		Find: findDeletedVault,
+		Recover: func(ctx context.Context) (*armkeyvault.Vault, error) {
+			return createVault(ctx, armkeyvault.CreateModeRecover)
+		},
+		Purge: purgeDeleted,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("vault:", *vault.ID)
 
 	vaultForDeployment, err := setVaultPermissionsForDeployment(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("vault for deployment:", *vaultForDeployment.ID)
 
 	deletedVaults, err := deletedVaultList(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for i, v := range deletedVaults {
 		log.Println("deleted vault:", i, *v.ID)
 	}
 
-	err = deleteVault(ctx)
-	if err != nil {
-		log.Fatal(err)
-	}
-	log.Println("deleted vault.")
+	// in reuse mode, the vault is kept for the next run
+	if !sample.Reuse {
+		err = deleteVault(ctx)
+		if err != nil {
+			return err
+		}
+		log.Println("deleted vault.")
 
-	err = purgeDeleted(ctx)
-	if err != nil {
-		log.Fatal(err)
+		err = purgeDeleted(ctx)
+		if err != nil {
+			return err
+		}
+		log.Println("purge deleted vault.")
 	}
-	log.Println("purge deleted vault.")
 
-	hsms, err := createManagedHsms(ctx)
+	// in reuse mode, the managed HSM of a previous run is used, or recovered if soft-deleted
+	hsms, err := samplekit.EnsureSoftDeleted(ctx, sample, "managed HSM "+managedHsmName, getManagedHsms, func(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
+		return createManagedHsms(ctx, armkeyvault.CreateModeDefault)
+	}, samplekit.SoftDelete[*armkeyvault.ManagedHsm]{
+		Find: findDeletedManagedHsms,
+		Recover: func(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
+			return createManagedHsms(ctx, armkeyvault.CreateModeRecover)
+		},
+		Purge: purgeDeletedManagedHsms,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("managed Hsms:", *hsms.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
-func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
+func createVault(ctx context.Context, createMode armkeyvault.CreateMode) (*armkeyvault.Vault, error) {
 
 	pollerResp, err := vaultsClient.BeginCreateOrUpdate(
 		ctx,
@@ -133,9 +139,10 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 			Properties: &armkeyvault.VaultProperties{
 				SKU: &armkeyvault.SKU{
 					Family: to.Ptr(armkeyvault.SKUFamilyA),
-					Name:   to.Ptr(armkeyvault.SKUNameStandard),
+					Name:   to.Ptr(armkeyvault.SKUName(vaultSKUName)),
 				},
-				TenantID: to.Ptr(TenantID),
+				TenantID:   to.Ptr(TenantID),
+				CreateMode: to.Ptr(createMode),
 				AccessPolicies: []*armkeyvault.AccessPolicyEntry{
 					{
 						TenantID: to.Ptr(TenantID),
@@ -166,13 +173,31 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create vault", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Vault, nil
+}
+
+func getVault(ctx context.Context) (*armkeyvault.Vault, error) {
+
+	resp, err := vaultsClient.Get(ctx, resourceGroupName, vaultName, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Vault, nil
 }
 
+func findDeletedVault(ctx context.Context) (bool, error) {
+
+	_, err := vaultsClient.GetDeleted(ctx, vaultName, location, nil)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func setVaultPermissionsForDeployment(ctx context.Context) (*armkeyvault.Vault, error) {
 
 	pollerResp, err := vaultsClient.BeginCreateOrUpdate(ctx, resourceGroupName, vaultName, armkeyvault.VaultCreateOrUpdateParameters{
@@ -180,7 +205,7 @@ func setVaultPermissionsForDeployment(ctx context.Context) (*armkeyvault.Vault,
 		Properties: &armkeyvault.VaultProperties{
 			SKU: &armkeyvault.SKU{
 				Family: to.Ptr(armkeyvault.SKUFamilyA),
-				Name:   to.Ptr(armkeyvault.SKUNameStandard),
+				Name:   to.Ptr(armkeyvault.SKUName(vaultSKUName)),
 			},
 			TenantID:                     to.Ptr(TenantID),
 			EnabledForDeployment:         to.Ptr(true),
@@ -209,7 +234,7 @@ func setVaultPermissionsForDeployment(ctx context.Context) (*armkeyvault.Vault,
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "set vault permissions for deployment", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +273,7 @@ func purgeDeleted(ctx context.Context) error {
 		return err
 	}
 
-	_, err = pollerResp.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "purge deleted", pollerResp, nil)
 	if err != nil {
 		return err
 	}
@@ -256,21 +281,21 @@ func purgeDeleted(ctx context.Context) error {
 	return nil
 }
 
-func createManagedHsms(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
+func createManagedHsms(ctx context.Context, createMode armkeyvault.CreateMode) (*armkeyvault.ManagedHsm, error) {
 
 	pollerResp, err := managedHsmsClient.BeginCreateOrUpdate(
 		ctx,
 		resourceGroupName,
-		"sample-hsmsxx",
+		managedHsmName,
 		armkeyvault.ManagedHsm{
 			Location: to.Ptr(location),
 			SKU: &armkeyvault.ManagedHsmSKU{
 				Family: to.Ptr(armkeyvault.ManagedHsmSKUFamilyB),
-				Name:   to.Ptr(armkeyvault.ManagedHsmSKUNameStandardB1),
+				Name:   to.Ptr(armkeyvault.ManagedHsmSKUName(managedHsmSKUName)),
 			},
 			Properties: &armkeyvault.ManagedHsmProperties{
 				TenantID:   to.Ptr(TenantID),
-				CreateMode: to.Ptr(armkeyvault.CreateModeDefault),
+				CreateMode: to.Ptr(createMode),
 				InitialAdminObjectIDs: []*string{
 					to.Ptr(ObjectID),
 				},
@@ -281,7 +306,7 @@ func createManagedHsms(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create managed HSMs", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -289,31 +314,35 @@ func createManagedHsms(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
 	return &resp.ManagedHsm, nil
 }
 
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
+func getManagedHsms(ctx context.Context) (*armkeyvault.ManagedHsm, error) {
 
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
+	resp, err := managedHsmsClient.Get(ctx, resourceGroupName, managedHsmName, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &resourceGroupResp.ResourceGroup, nil
+	return &resp.ManagedHsm, nil
 }
 
-func cleanup(ctx context.Context) error {
+func findDeletedManagedHsms(ctx context.Context) (bool, error) {
 
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
+	_, err := managedHsmsClient.GetDeleted(ctx, managedHsmName, location, nil)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func purgeDeletedManagedHsms(ctx context.Context) error {
+
+	pollerResp, err := managedHsmsClient.BeginPurgeDeleted(ctx, managedHsmName, location, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = pollerResp.PollUntilDone(ctx, nil)
+	_, err = samplekit.Wait(ctx, "purge deleted managed HSMs", pollerResp, nil)
 	if err != nil {
 		return err
 	}
+
 	return nil
 }
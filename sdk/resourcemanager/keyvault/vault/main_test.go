@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	keyvaultfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestVault(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	keyvaultServer := keyvaultfake.ServerFactory{
+		VaultsServer: keyvaultfake.VaultsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, vaultName string, parameters armkeyvault.VaultCreateOrUpdateParameters, options *armkeyvault.VaultsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armkeyvault.VaultsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armkeyvault.VaultsClientCreateOrUpdateResponse{
+					Vault: armkeyvault.Vault{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/vaults/%s", subscriptionID, resourceGroupName, vaultName)),
+						Name: to.Ptr(vaultName),
+					},
+				}, nil)
+				return
+			},
+			NewListDeletedPager: func(options *armkeyvault.VaultsClientListDeletedOptions) (resp azfake.PagerResponder[armkeyvault.VaultsClientListDeletedResponse]) {
+				resp.AddPage(http.StatusOK, armkeyvault.VaultsClientListDeletedResponse{
+					DeletedVaultListResult: armkeyvault.DeletedVaultListResult{
+						Value: []*armkeyvault.DeletedVault{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.KeyVault/deletedVaults/%s", subscriptionID, vaultName)),
+								Name: to.Ptr(vaultName),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			Delete: func(ctx context.Context, resourceGroupName string, vaultName string, options *armkeyvault.VaultsClientDeleteOptions) (resp azfake.Responder[armkeyvault.VaultsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armkeyvault.VaultsClientDeleteResponse{}, nil)
+				return
+			},
+			BeginPurgeDeleted: func(ctx context.Context, vaultName string, location string, options *armkeyvault.VaultsClientBeginPurgeDeletedOptions) (resp azfake.PollerResponder[armkeyvault.VaultsClientPurgeDeletedResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armkeyvault.VaultsClientPurgeDeletedResponse{}, nil)
+				return
+			},
+		},
+		ManagedHsmsServer: keyvaultfake.ManagedHsmsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, name string, parameters armkeyvault.ManagedHsm, options *armkeyvault.ManagedHsmsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armkeyvault.ManagedHsmsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armkeyvault.ManagedHsmsClientCreateOrUpdateResponse{
+					ManagedHsm: armkeyvault.ManagedHsm{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/managedHSMs/%s", subscriptionID, resourceGroupName, name)),
+						Name: to.Ptr(name),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	TenantID = "00000000-0000-0000-0000-000000000000"
+
+	ObjectID = "00000000-0000-0000-0000-000000000000"
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: keyvaultfake.NewServerFactoryTransport(&keyvaultServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaultsClient = keyvaultClientFactory.NewVaultsClient()
+	managedHsmsClient = keyvaultClientFactory.NewManagedHsmsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	vault, err := createVault(ctx, armkeyvault.CreateModeDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("vault:", *vault.ID)
+
+	vaultForDeployment, err := setVaultPermissionsForDeployment(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("vault for deployment:", *vaultForDeployment.ID)
+
+	deletedVaults, err := deletedVaultList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range deletedVaults {
+		t.Log("deleted vault:", i, *v.ID)
+	}
+
+	err = deleteVault(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("deleted vault.")
+
+	err = purgeDeleted(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("purge deleted vault.")
+
+	hsms, err := createManagedHsms(ctx, armkeyvault.CreateModeDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("managed Hsms:", *hsms.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,12 +5,10 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
@@ -21,65 +19,56 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	monitorClientFactory   *armmonitor.ClientFactory
+	monitorClientFactory *armmonitor.ClientFactory
 )
 
 var (
-	resourceGroupClient     *armresources.ResourceGroupsClient
 	activityLogAlertsClient *armmonitor.ActivityLogAlertsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":             &location,
+		"resourceGroupName":    &resourceGroupName,
+		"activityLogAlertName": &activityLogAlertName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	subscriptionID = sample.SubscriptionID
 
-	monitorClientFactory, err = armmonitor.NewClientFactory(subscriptionID, cred, nil)
+	monitorClientFactory, err = armmonitor.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	activityLogAlertsClient = monitorClientFactory.NewActivityLogAlertsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Insights"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	activityLogAlert, err := createActivityLogAlert(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("activity log alert:", *activityLogAlert.ID)
 
 	activityLogAlert, err = getActivityLogAlert(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get activity log alert:", *activityLogAlert.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createActivityLogAlert(ctx context.Context) (*armmonitor.ActivityLogAlertResource, error) {
@@ -129,32 +118,3 @@ func getActivityLogAlert(ctx context.Context) (*armmonitor.ActivityLogAlertResou
 	}
 	return &resp.ActivityLogAlertResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
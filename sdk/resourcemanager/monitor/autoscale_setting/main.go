@@ -5,18 +5,16 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID       string
 	location             = "eastus"
 	resourceGroupName    = "sample-resource-group"
 	virtualNetworkName   = "sample-virtual-network"
@@ -24,17 +22,16 @@ var (
 	networkInterfaceName = "sample-network-interface"
 	metricAlertName      = "sample-metric-alert"
 	vmScaleSetName       = "sample-vm-scale-set"
+	vmssSKUName          = "Standard_D1_v2"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
-	monitorClientFactory   *armmonitor.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
+	monitorClientFactory *armmonitor.ClientFactory
 )
 
 var (
-	resourceGroupClient           *armresources.ResourceGroupsClient
 	virtualNetworksClient         *armnetwork.VirtualNetworksClient
 	subnetsClient                 *armnetwork.SubnetsClient
 	interfacesClient              *armnetwork.InterfacesClient
@@ -43,87 +40,87 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":             &location,
+		"resourceGroupName":    &resourceGroupName,
+		"virtualNetworkName":   &virtualNetworkName,
+		"subnetName":           &subnetName,
+		"networkInterfaceName": &networkInterfaceName,
+		"metricAlertName":      &metricAlertName,
+		"vmScaleSetName":       &vmScaleSetName,
+		"vmssSKUName":          &vmssSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
 	interfacesClient = networkClientFactory.NewInterfacesClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualMachineScaleSetsClient = computeClientFactory.NewVirtualMachineScaleSetsClient()
 
-	monitorClientFactory, err = armmonitor.NewClientFactory(subscriptionID, cred, nil)
+	monitorClientFactory, err = armmonitor.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	autoscaleSettingsClient = monitorClientFactory.NewAutoscaleSettingsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample;
+	// the scale set starts with two instances
+	if err := sample.Preflight(ctx, samplekit.Needs{
+		Providers: []string{"Microsoft.Insights", "Microsoft.Compute", "Microsoft.Network"},
+		VMSizes:   []string{vmssSKUName, vmssSKUName},
+	}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	nic, err := createNIC(ctx, *subnet.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("network interface:", *nic.ID)
 
 	vmss, err := createVMSS(ctx, *subnet.ID)
 	if err != nil {
-		log.Fatalf("cannot create virtual machine scale sets:%+v", err)
+		return fmt.Errorf("cannot create virtual machine scale sets: %w", err)
 	}
 	log.Printf("virtual machine scale sets: %s", *vmss.ID)
 
 	autoscaleSetting, err := createAutoscaleSetting(ctx, *vmss.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("autoscale Setting: %s", *autoscaleSetting.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, error) {
@@ -148,7 +145,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +170,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +204,7 @@ func createNIC(ctx context.Context, subnetID string) (*armnetwork.Interface, err
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create NIC", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +220,7 @@ func createVMSS(ctx context.Context, subnetID string) (*armcompute.VirtualMachin
 		armcompute.VirtualMachineScaleSet{
 			Location: to.Ptr(location),
 			SKU: &armcompute.SKU{
-				Name:     to.Ptr("Standard_D1_v2"),
+				Name:     to.Ptr(vmssSKUName),
 				Capacity: to.Ptr[int64](2),
 				Tier:     to.Ptr("Standard"),
 			},
@@ -284,7 +281,7 @@ func createVMSS(ctx context.Context, subnetID string) (*armcompute.VirtualMachin
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create VMSS", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -336,32 +333,3 @@ func createAutoscaleSetting(ctx context.Context, resourceURI string) (*armmonito
 	}
 	return &resp.AutoscaleSettingResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,97 +5,86 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "westus"
-	resourceGroupName  = "sample-resource-group"
-	storageAccountName = "sample2storage2account"
-	logProfileName     = "sample-log-profile"
+	location              = "westus"
+	resourceGroupName     = "sample-resource-group"
+	storageAccountName    = "sample2storage2account"
+	logProfileName        = "sample-log-profile"
+	storageAccountSKUName = string(armstorage.SKUNameStandardLRS)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	storageClientFactory   *armstorage.ClientFactory
-	monitorClientFactory   *armmonitor.ClientFactory
+	storageClientFactory *armstorage.ClientFactory
+	monitorClientFactory *armmonitor.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	accountsClient      *armstorage.AccountsClient
-	logProfilesClient   *armmonitor.LogProfilesClient
+	accountsClient    *armstorage.AccountsClient
+	logProfilesClient *armmonitor.LogProfilesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"storageAccountName":    samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+		"logProfileName":        &logProfileName,
+		"storageAccountSKUName": &storageAccountSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountsClient = storageClientFactory.NewAccountsClient()
 
-	monitorClientFactory, err = armmonitor.NewClientFactory(subscriptionID, cred, nil)
+	monitorClientFactory, err = armmonitor.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	logProfilesClient = monitorClientFactory.NewLogProfilesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Insights", "Microsoft.Storage"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	storageAccount, err := createStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage account:", *storageAccount.ID)
 
 	logProfile, err := createLogProfile(ctx, *storageAccount.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("log profile:", *logProfile.ID)
 
 	logProfile, err = getLogProfile(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get log profile:", *logProfile.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
@@ -107,7 +96,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 		armstorage.AccountCreateParameters{
 			Kind: to.Ptr(armstorage.KindStorageV2),
 			SKU: &armstorage.SKU{
-				Name: to.Ptr(armstorage.SKUNameStandardLRS),
+				Name: to.Ptr(armstorage.SKUName(storageAccountSKUName)),
 			},
 			Location: to.Ptr(location),
 			Properties: &armstorage.AccountPropertiesCreateParameters{
@@ -117,7 +106,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create storage account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -163,32 +152,3 @@ func getLogProfile(ctx context.Context) (*armmonitor.LogProfileResource, error)
 	}
 	return &resp.LogProfileResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
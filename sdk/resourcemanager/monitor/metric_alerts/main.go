@@ -5,18 +5,16 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID       string
 	location             = "southcentralus"
 	resourceGroupName    = "sample-resource-group"
 	virtualNetworkName   = "sample-virtual-network"
@@ -25,17 +23,16 @@ var (
 	osDiskName           = "sample-os-disk"
 	virtualMachineName   = "sample-virtual-machine"
 	metricAlertName      = "sample-metric-alert"
+	vmSize               = "Standard_F2s"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	computeClientFactory   *armcompute.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
-	monitorClientFactory   *armmonitor.ClientFactory
+	computeClientFactory *armcompute.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
+	monitorClientFactory *armmonitor.ClientFactory
 )
 
 var (
-	resourceGroupClient   *armresources.ResourceGroupsClient
 	virtualNetworksClient *armnetwork.VirtualNetworksClient
 	subnetsClient         *armnetwork.SubnetsClient
 	interfacesClient      *armnetwork.InterfacesClient
@@ -44,87 +41,87 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":             &location,
+		"resourceGroupName":    &resourceGroupName,
+		"virtualNetworkName":   &virtualNetworkName,
+		"subnetName":           &subnetName,
+		"networkInterfaceName": &networkInterfaceName,
+		"osDiskName":           &osDiskName,
+		"virtualMachineName":   &virtualMachineName,
+		"metricAlertName":      &metricAlertName,
+		"vmSize":               &vmSize,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
 	interfacesClient = networkClientFactory.NewInterfacesClient()
 
-	computeClientFactory, err = armcompute.NewClientFactory(subscriptionID, cred, nil)
+	computeClientFactory, err = armcompute.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualMachinesClient = computeClientFactory.NewVirtualMachinesClient()
 
-	monitorClientFactory, err = armmonitor.NewClientFactory(subscriptionID, cred, nil)
+	monitorClientFactory, err = armmonitor.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	metricAlertsClient = monitorClientFactory.NewMetricAlertsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{
+		Providers: []string{"Microsoft.Insights", "Microsoft.Compute", "Microsoft.Network"},
+		VMSizes:   []string{vmSize},
+	}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	nic, err := createNIC(ctx, *subnet.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("network interface:", *nic.ID)
 
 	virtualMachine, err := createVirtualMachine(ctx, *nic.ID)
 	if err != nil {
-		log.Fatalf("cannot create virual machine:%+v", err)
+		return fmt.Errorf("cannot create virual machine: %w", err)
 	}
 	log.Printf("virual machine: %s", *virtualMachine.ID)
 
 	metricAlert, err := createMetricAlerts(ctx, *virtualMachine.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("metric alert: %s", *metricAlert.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, error) {
@@ -149,7 +146,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +171,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +206,7 @@ func createNIC(ctx context.Context, subnetID string) (*armnetwork.Interface, err
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create NIC", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +240,7 @@ func createVirtualMachine(ctx context.Context, networkInterfaceID string) (*armc
 				},
 			},
 			HardwareProfile: &armcompute.HardwareProfile{
-				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes("Standard_F2s")), // VM size include vCPUs,RAM,Data Disks,Temp storage.
+				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmSize)), // VM size include vCPUs,RAM,Data Disks,Temp storage.
 			},
 			OSProfile: &armcompute.OSProfile{ //
 				ComputerName:  to.Ptr("sample-compute"),
@@ -268,7 +265,7 @@ func createVirtualMachine(ctx context.Context, networkInterfaceID string) (*armc
 		return nil, err
 	}
 
-	resp, err := pollerResponse.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual machine", pollerResponse, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -294,7 +291,7 @@ func createMetricAlerts(ctx context.Context, resourceURI string) (*armmonitor.Me
 				EvaluationFrequency:  to.Ptr("PT1M"),
 				WindowSize:           to.Ptr("PT15M"),
 				TargetResourceType:   to.Ptr("Microsoft.Compute/virtualMachines"),
-				TargetResourceRegion: to.Ptr("southcentralus"),
+				TargetResourceRegion: to.Ptr(location),
 				Criteria: &armmonitor.MetricAlertMultipleResourceMultipleMetricCriteria{
 					ODataType: to.Ptr(armmonitor.OdatatypeMicrosoftAzureMonitorMultipleResourceMultipleMetricCriteria),
 					AllOf: []armmonitor.MultiMetricCriteriaClassification{
@@ -325,32 +322,3 @@ func createMetricAlerts(ctx context.Context, resourceURI string) (*armmonitor.Me
 	}
 	return &resp.MetricAlertResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,97 +5,86 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	workspaceName     = "sample-workspace"
 	ruleName          = "sample-scheduled-query-rules"
+	workspaceSKUName  = string(armoperationalinsights.WorkspaceSKUNameEnumPerNode)
 )
 
 var (
-	resourcesClientFactory           *armresources.ClientFactory
 	operationalinsightsClientFactory *armoperationalinsights.ClientFactory
 	monitorClientFactory             *armmonitor.ClientFactory
 )
 
 var (
-	resourceGroupClient       *armresources.ResourceGroupsClient
 	workspacesClient          *armoperationalinsights.WorkspacesClient
 	scheduledQueryRulesClient *armmonitor.ScheduledQueryRulesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"workspaceName":     &workspaceName,
+		"ruleName":          &ruleName,
+		"workspaceSKUName":  &workspaceSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, nil)
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
 
-	monitorClientFactory, err = armmonitor.NewClientFactory(subscriptionID, cred, nil)
+	monitorClientFactory, err = armmonitor.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	scheduledQueryRulesClient = monitorClientFactory.NewScheduledQueryRulesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Insights", "Microsoft.OperationalInsights"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	workspace, err := createWorkspaces(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("workspace:", *workspace.ID)
 
 	scheduledQueryRule, err := createScheduledQueryRule(ctx, *workspace.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("scheduled query rule:", *scheduledQueryRule.ID)
 
 	scheduledQueryRule, err = getScheduledQueryRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get scheduled query rule:", *scheduledQueryRule.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createWorkspaces(ctx context.Context) (*armoperationalinsights.Workspace, error) {
@@ -111,7 +100,7 @@ func createWorkspaces(ctx context.Context) (*armoperationalinsights.Workspace, e
 			},
 			Properties: &armoperationalinsights.WorkspaceProperties{
 				SKU: &armoperationalinsights.WorkspaceSKU{
-					Name: to.Ptr(armoperationalinsights.WorkspaceSKUNameEnumPerNode),
+					Name: to.Ptr(armoperationalinsights.WorkspaceSKUNameEnum(workspaceSKUName)),
 				},
 				RetentionInDays: to.Ptr[int32](30),
 			},
@@ -121,7 +110,7 @@ func createWorkspaces(ctx context.Context) (*armoperationalinsights.Workspace, e
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create workspaces", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -204,32 +193,3 @@ func getScheduledQueryRule(ctx context.Context) (*armmonitor.ScheduledQueryRuleR
 	}
 	return &resp.ScheduledQueryRuleResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
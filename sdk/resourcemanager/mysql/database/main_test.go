@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql"
+	mysqlfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestDatabase(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	mysqlServer := mysqlfake.ServerFactory{
+		ServersServer: mysqlfake.ServersServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, serverName string, parameters armmysql.ServerForCreate, options *armmysql.ServersClientBeginCreateOptions) (resp azfake.PollerResponder[armmysql.ServersClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armmysql.ServersClientCreateResponse{
+					Server: armmysql.Server{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s", subscriptionID, resourceGroupName, serverName)),
+						Name: to.Ptr(serverName),
+					},
+				}, nil)
+				return
+			},
+		},
+		DatabasesServer: mysqlfake.DatabasesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, parameters armmysql.Database, options *armmysql.DatabasesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armmysql.DatabasesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armmysql.DatabasesClientCreateOrUpdateResponse{
+					Database: armmysql.Database{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s/databases/%s", subscriptionID, resourceGroupName, serverName, databaseName)),
+						Name: to.Ptr(databaseName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, options *armmysql.DatabasesClientGetOptions) (resp azfake.Responder[armmysql.DatabasesClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armmysql.DatabasesClientGetResponse{
+					Database: armmysql.Database{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s/databases/%s", subscriptionID, resourceGroupName, serverName, databaseName)),
+						Name: to.Ptr(databaseName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	mysqlClientFactory, err = armmysql.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: mysqlfake.NewServerFactoryTransport(&mysqlServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversClient = mysqlClientFactory.NewServersClient()
+	databasesClient = mysqlClientFactory.NewDatabasesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	server, err := createServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("mysql server:", *server.ID)
+
+	database, err := createDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("mysql database:", *database.ID)
+
+	database, err = getDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get mysql database:", *database.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,90 +5,79 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
 	firewallRuleName  = "sample-firewall-rule"
+	serverSKUName     = "GP_Gen5_2"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	mysqlClientFactory     *armmysql.ClientFactory
+	mysqlClientFactory *armmysql.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
 	serversClient       *armmysql.ServersClient
 	firewallRulesClient *armmysql.FirewallRulesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.MySQLServer),
+		"firewallRuleName":  &firewallRuleName,
+		"serverSKUName":     &serverSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	mysqlClientFactory, err = armmysql.NewClientFactory(subscriptionID, cred, nil)
+	mysqlClientFactory, err = armmysql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = mysqlClientFactory.NewServersClient()
 	firewallRulesClient = mysqlClientFactory.NewFirewallRulesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DBforMySQL"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	server, err := createServer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("mysql server:", *server.ID)
 
 	firewallRule, err := createFirewallRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("mysql firewall rule:", *firewallRule.ID)
 
 	firewallRule, err = getFirewallRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get mysql firewall rule:", *firewallRule.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armmysql.Server, error) {
@@ -103,7 +92,7 @@ func createServer(ctx context.Context) (*armmysql.Server, error) {
 				CreateMode: to.Ptr(armmysql.CreateModeDefault),
 			},
 			SKU: &armmysql.SKU{
-				Name:     to.Ptr("GP_Gen5_2"),
+				Name:     to.Ptr(serverSKUName),
 				Tier:     to.Ptr(armmysql.SKUTierGeneralPurpose),
 				Capacity: to.Ptr[int32](2),
 				Family:   to.Ptr("Gen5"),
@@ -114,7 +103,7 @@ func createServer(ctx context.Context) (*armmysql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +128,7 @@ func createFirewallRule(ctx context.Context) (*armmysql.FirewallRule, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create firewall rule", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -154,32 +143,3 @@ func getFirewallRule(ctx context.Context) (*armmysql.FirewallRule, error) {
 	}
 	return &resp.FirewallRule, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql"
+	mysqlfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestFirewallrule(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	mysqlServer := mysqlfake.ServerFactory{
+		ServersServer: mysqlfake.ServersServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, serverName string, parameters armmysql.ServerForCreate, options *armmysql.ServersClientBeginCreateOptions) (resp azfake.PollerResponder[armmysql.ServersClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armmysql.ServersClientCreateResponse{
+					Server: armmysql.Server{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s", subscriptionID, resourceGroupName, serverName)),
+						Name: to.Ptr(serverName),
+					},
+				}, nil)
+				return
+			},
+		},
+		FirewallRulesServer: mysqlfake.FirewallRulesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, firewallRuleName string, parameters armmysql.FirewallRule, options *armmysql.FirewallRulesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armmysql.FirewallRulesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armmysql.FirewallRulesClientCreateOrUpdateResponse{
+					FirewallRule: armmysql.FirewallRule{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s/firewallRules/%s", subscriptionID, resourceGroupName, serverName, firewallRuleName)),
+						Name: to.Ptr(firewallRuleName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, serverName string, firewallRuleName string, options *armmysql.FirewallRulesClientGetOptions) (resp azfake.Responder[armmysql.FirewallRulesClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armmysql.FirewallRulesClientGetResponse{
+					FirewallRule: armmysql.FirewallRule{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforMySQL/servers/%s/firewallRules/%s", subscriptionID, resourceGroupName, serverName, firewallRuleName)),
+						Name: to.Ptr(firewallRuleName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	mysqlClientFactory, err = armmysql.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: mysqlfake.NewServerFactoryTransport(&mysqlServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversClient = mysqlClientFactory.NewServersClient()
+	firewallRulesClient = mysqlClientFactory.NewFirewallRulesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	server, err := createServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("mysql server:", *server.ID)
+
+	firewallRule, err := createFirewallRule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("mysql firewall rule:", *firewallRule.ID)
+
+	firewallRule, err = getFirewallRule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get mysql firewall rule:", *firewallRule.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
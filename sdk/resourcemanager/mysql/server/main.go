@@ -5,81 +5,69 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysql"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
+	serverSKUName     = "GP_Gen5_2"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	mysqlClientFactory     *armmysql.ClientFactory
+	mysqlClientFactory *armmysql.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	serversClient       *armmysql.ServersClient
+	serversClient *armmysql.ServersClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.MySQLServer),
+		"serverSKUName":     &serverSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	mysqlClientFactory, err = armmysql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	serversClient = mysqlClientFactory.NewServersClient()
 
-	mysqlClientFactory, err = armmysql.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DBforMySQL"}}); err != nil {
+		return err
 	}
-	serversClient = mysqlClientFactory.NewServersClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	server, err := createServer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server:", *server.ID)
 
 	server, err = getServer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get server:", *server.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armmysql.Server, error) {
@@ -94,7 +82,7 @@ func createServer(ctx context.Context) (*armmysql.Server, error) {
 				CreateMode: to.Ptr(armmysql.CreateModeDefault),
 			},
 			SKU: &armmysql.SKU{
-				Name:     to.Ptr("GP_Gen5_2"),
+				Name:     to.Ptr(serverSKUName),
 				Tier:     to.Ptr(armmysql.SKUTierGeneralPurpose),
 				Capacity: to.Ptr[int32](2),
 				Family:   to.Ptr("Gen5"),
@@ -105,7 +93,7 @@ func createServer(ctx context.Context) (*armmysql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -120,32 +108,3 @@ func getServer(ctx context.Context) (*armmysql.Server, error) {
 	}
 	return &resp.Server, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
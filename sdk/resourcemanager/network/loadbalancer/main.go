@@ -6,12 +6,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
@@ -23,82 +21,59 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
 )
 
 var (
-	resourceGroupClient     *armresources.ResourceGroupsClient
 	publicIPAddressesClient *armnetwork.PublicIPAddressesClient
 	loadBalancersClient     *armnetwork.LoadBalancersClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":            &location,
+		"resourceGroupName":   &resourceGroupName,
+		"publicIPAddressName": &publicIPAddressName,
+		"loadBalancerName":    &loadBalancerName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	subscriptionID = sample.SubscriptionID
 
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	publicIPAddressesClient = networkClientFactory.NewPublicIPAddressesClient()
 	loadBalancersClient = networkClientFactory.NewLoadBalancersClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	publicIP, err := createPublicIP(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("public ip:", *publicIP.ID)
 
 	loadBalancer, err := createLoadBalancer(ctx, publicIP)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("load balancer:", *loadBalancer.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
-}
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
+	return nil
 }
 
 func createPublicIP(ctx context.Context) (*armnetwork.PublicIPAddress, error) {
@@ -121,7 +96,7 @@ func createPublicIP(ctx context.Context) (*armnetwork.PublicIPAddress, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create public IP", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -223,23 +198,9 @@ func createLoadBalancer(ctx context.Context, pip *armnetwork.PublicIPAddress) (*
 		return nil, fmt.Errorf("cannot create load balancer: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create load balancer", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.LoadBalancer, nil
 }
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
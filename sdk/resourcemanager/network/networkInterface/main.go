@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID       string
 	location             = "westus"
 	resourceGroupName    = "sample-resources-group"
 	networkInterfaceName = "sample-network-interface"
@@ -25,12 +22,10 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
 )
 
 var (
-	resourceGroupClient     *armresources.ResourceGroupsClient
 	virtualNetworksClient   *armnetwork.VirtualNetworksClient
 	subnetsClient           *armnetwork.SubnetsClient
 	publicIPAddressesClient *armnetwork.PublicIPAddressesClient
@@ -39,26 +34,23 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":             &location,
+		"resourceGroupName":    &resourceGroupName,
+		"networkInterfaceName": &networkInterfaceName,
+		"virtualNetworkName":   &virtualNetworkName,
+		"subnetName":           &subnetName,
+		"publicIPAddressName":  &publicIPAddressName,
+		"securityGroupName":    &securityGroupName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
@@ -66,65 +58,48 @@ func main() {
 	securityGroupsClient = networkClientFactory.NewSecurityGroupsClient()
 	interfacesClient = networkClientFactory.NewInterfacesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	publicIP, err := createPublicIP(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("public ip:", *publicIP.ID)
 
 	networkSecurityGroup, err := createNetworkSecurityGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("network security group:", *networkSecurityGroup.ID)
 
 	nic, err := createNIC(ctx, *subnet.ID, *publicIP.ID, *networkSecurityGroup.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("network interface:", *nic.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
-}
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
+	return nil
 }
 
 func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, error) {
@@ -149,7 +124,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +149,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +176,7 @@ func createPublicIP(ctx context.Context) (*armnetwork.PublicIPAddress, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create public IP", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +228,7 @@ func createNetworkSecurityGroup(ctx context.Context) (*armnetwork.SecurityGroup,
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create network security group", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -294,23 +269,9 @@ func createNIC(ctx context.Context, subnetID, publicIPID, networkSecurityGroupID
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create NIC", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Interface, nil
 }
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
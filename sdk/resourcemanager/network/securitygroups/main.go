@@ -6,101 +6,87 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resources-group"
 	securityGroupName = "sample-network-security-group"
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	networkClientFactory   *armnetwork.ClientFactory
+	networkClientFactory *armnetwork.ClientFactory
 )
 
 var (
-	resourceGroupClient  *armresources.ResourceGroupsClient
 	securityGroupsClient *armnetwork.SecurityGroupsClient
 	securityRulesClient  *armnetwork.SecurityRulesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"securityGroupName": &securityGroupName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	securityGroupsClient = networkClientFactory.NewSecurityGroupsClient()
 	securityRulesClient = networkClientFactory.NewSecurityRulesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	networkSecurityGroup, err := createNetworkSecurityGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("network security group:", *networkSecurityGroup.ID)
 
 	sshRule, err := createSSHRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("SSH:", *sshRule.ID)
 
 	httpRule, err := createHTTPRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("HTTP:", *httpRule.ID)
 
 	sqlRule, err := createSQLRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("SQL:", *sqlRule.ID)
 
 	denyOutRule, err := createDenyOutRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("Deny Out:", *denyOutRule.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createNetworkSecurityGroup(ctx context.Context) (*armnetwork.SecurityGroup, error) {
@@ -148,7 +134,7 @@ func createNetworkSecurityGroup(ctx context.Context) (*armnetwork.SecurityGroup,
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create network security group", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +166,7 @@ func createSSHRule(ctx context.Context) (*armnetwork.SecurityRule, error) {
 		return nil, fmt.Errorf("cannot create SSH security rule: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create SSH rule", pollerResp, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get security rule create or update future response: %v", err)
 	}
@@ -213,7 +199,7 @@ func createHTTPRule(ctx context.Context) (*armnetwork.SecurityRule, error) {
 		return nil, fmt.Errorf("cannot create HTTP security rule: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create HTTP rule", pollerResp, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get security rule create or update future response: %v", err)
 	}
@@ -246,7 +232,7 @@ func createSQLRule(ctx context.Context) (*armnetwork.SecurityRule, error) {
 		return nil, fmt.Errorf("cannot create SQL security rule: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create SQL rule", pollerResp, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get security rule create or update future response: %v", err)
 	}
@@ -279,38 +265,10 @@ func createDenyOutRule(ctx context.Context) (*armnetwork.SecurityRule, error) {
 		return nil, fmt.Errorf("cannot create deny out security rule: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create deny out rule", pollerResp, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get security rule create or update future response: %v", err)
 	}
 
 	return &resp.SecurityRule, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,83 +5,80 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "westus"
-	resourceGroupName  = "sample-resource-group"
-	workspaceName      = "sample-workspace"
-	storageInsightName = "sample-storage-insight"
-	storageAccountName = "sample2storage2account"
+	location              = "westus"
+	resourceGroupName     = "sample-resource-group"
+	workspaceName         = "sample-workspace"
+	storageInsightName    = "sample-storage-insight"
+	storageAccountName    = "sample2storage2account"
+	storageAccountSKUName = string(armstorage.SKUNameStandardLRS)
 )
 
 var (
-	resourcesClientFactory           *armresources.ClientFactory
 	storageClientFactory             *armstorage.ClientFactory
 	operationalinsightsClientFactory *armoperationalinsights.ClientFactory
 )
 
 var (
-	resourceGroupClient         *armresources.ResourceGroupsClient
 	accountsClient              *armstorage.AccountsClient
 	workspacesClient            *armoperationalinsights.WorkspacesClient
 	storageInsightConfigsClient *armoperationalinsights.StorageInsightConfigsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"workspaceName":         &workspaceName,
+		"storageInsightName":    &storageInsightName,
+		"storageAccountName":    samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+		"storageAccountSKUName": &storageAccountSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountsClient = storageClientFactory.NewAccountsClient()
 
-	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, nil)
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
 	storageInsightConfigsClient = operationalinsightsClientFactory.NewStorageInsightConfigsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.OperationalInsights", "Microsoft.Storage"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	storageAccount, err := createStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage account:", *storageAccount.ID)
 
 	keys, err := regenerateKeyStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for _, v := range keys {
 		if *v.KeyName == "key1" {
@@ -91,24 +88,17 @@ func main() {
 
 	workspace, err := createWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("operational insights workspace:", *workspace.ID)
 
 	storageInsight, err := createStorageInsight(ctx, *storageAccount.ID, *keys[0].Value)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage insight:", *storageInsight.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
@@ -120,7 +110,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 		armstorage.AccountCreateParameters{
 			Kind: to.Ptr(armstorage.KindStorageV2),
 			SKU: &armstorage.SKU{
-				Name: to.Ptr(armstorage.SKUNameStandardLRS),
+				Name: to.Ptr(armstorage.SKUName(storageAccountSKUName)),
 			},
 			Location: to.Ptr(location),
 			Properties: &armstorage.AccountPropertiesCreateParameters{
@@ -151,7 +141,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create storage account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +180,7 @@ func createWorkspace(ctx context.Context) (*armoperationalinsights.Workspace, er
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create workspace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -219,32 +209,3 @@ func createStorageInsight(ctx context.Context, storageAccountID, storageKeyID st
 	}
 	return &resp.StorageInsight, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
+	operationalinsightsfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	storagefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage/fake"
+)
+
+func TestStorageInsightConfig(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	storageServer := storagefake.ServerFactory{
+		AccountsServer: storagefake.AccountsServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, accountName string, parameters armstorage.AccountCreateParameters, options *armstorage.AccountsClientBeginCreateOptions) (resp azfake.PollerResponder[armstorage.AccountsClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armstorage.AccountsClientCreateResponse{
+					Account: armstorage.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+					},
+				}, nil)
+				return
+			},
+			RegenerateKey: func(ctx context.Context, resourceGroupName string, accountName string, regenerateKey armstorage.AccountRegenerateKeyParameters, options *armstorage.AccountsClientRegenerateKeyOptions) (resp azfake.Responder[armstorage.AccountsClientRegenerateKeyResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientRegenerateKeyResponse{
+					AccountListKeysResult: armstorage.AccountListKeysResult{
+						Keys: []*armstorage.AccountKey{
+							{
+								KeyName:      to.Ptr("key1"),
+								Value:        to.Ptr("sample-key1"),
+								CreationTime: to.Ptr(time.Date(2021, 9, 25, 13, 0, 0, 0, time.UTC)),
+								Permissions:  to.Ptr(armstorage.KeyPermissionFull),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+	operationalinsightsServer := operationalinsightsfake.ServerFactory{
+		WorkspacesServer: operationalinsightsfake.WorkspacesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, workspaceName string, parameters armoperationalinsights.Workspace, options *armoperationalinsights.WorkspacesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armoperationalinsights.WorkspacesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armoperationalinsights.WorkspacesClientCreateOrUpdateResponse{
+					Workspace: armoperationalinsights.Workspace{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.OperationalInsights/workspaces/%s", subscriptionID, resourceGroupName, workspaceName)),
+						Name: to.Ptr(workspaceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		StorageInsightConfigsServer: operationalinsightsfake.StorageInsightConfigsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, workspaceName string, storageInsightName string, parameters armoperationalinsights.StorageInsight, options *armoperationalinsights.StorageInsightConfigsClientCreateOrUpdateOptions) (resp azfake.Responder[armoperationalinsights.StorageInsightConfigsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armoperationalinsights.StorageInsightConfigsClientCreateOrUpdateResponse{
+					StorageInsight: armoperationalinsights.StorageInsight{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.OperationalInsights/workspaces/%s/storageInsightConfigs/%s", subscriptionID, resourceGroupName, workspaceName, storageInsightName)),
+						Name: to.Ptr(storageInsightName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: storagefake.NewServerFactoryTransport(&storageServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountsClient = storageClientFactory.NewAccountsClient()
+
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: operationalinsightsfake.NewServerFactoryTransport(&operationalinsightsServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
+	storageInsightConfigsClient = operationalinsightsClientFactory.NewStorageInsightConfigsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	storageAccount, err := createStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("storage account:", *storageAccount.ID)
+
+	keys, err := regenerateKeyStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range keys {
+		if *v.KeyName == "key1" {
+			t.Log("regenerate key:", *v.KeyName, *v.Value, *v.CreationTime, *v.Permissions)
+		}
+	}
+
+	workspace, err := createWorkspace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("operational insights workspace:", *workspace.ID)
+
+	storageInsight, err := createStorageInsight(ctx, *storageAccount.ID, *keys[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("storage insight:", *storageInsight.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,89 +5,75 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	workspaceName     = "sample-workspace"
 )
 
 var (
-	resourcesClientFactory           *armresources.ClientFactory
 	operationalinsightsClientFactory *armoperationalinsights.ClientFactory
 )
 
 var (
-	resourceGroupClient  *armresources.ResourceGroupsClient
 	workspacesClient     *armoperationalinsights.WorkspacesClient
 	workspacePurgeClient *armoperationalinsights.WorkspacePurgeClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"workspaceName":     &workspaceName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, nil)
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
 	workspacePurgeClient = operationalinsightsClientFactory.NewWorkspacePurgeClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.OperationalInsights"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	workspace, err := createWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("operational insights workspace:", *workspace.ID)
 
 	purge, err := purgeWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("purge workspace:", *purge.OperationID, *purge.XMSStatusLocation)
 
 	purgeStatus, err := purgeStatusWorkspace(ctx, *purge.OperationID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("purge status workspace:", *purgeStatus.Status)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createWorkspace(ctx context.Context) (*armoperationalinsights.Workspace, error) {
@@ -105,7 +91,7 @@ func createWorkspace(ctx context.Context) (*armoperationalinsights.Workspace, er
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create workspace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -138,32 +124,3 @@ func purgeStatusWorkspace(ctx context.Context, purgeID string) (*armoperationali
 	}
 	return &resp.WorkspacePurgeStatusResponse, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
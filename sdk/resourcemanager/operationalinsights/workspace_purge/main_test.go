@@ -0,0 +1,137 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
+	operationalinsightsfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestWorkspacePurge(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	operationalinsightsServer := operationalinsightsfake.ServerFactory{
+		WorkspacesServer: operationalinsightsfake.WorkspacesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, workspaceName string, parameters armoperationalinsights.Workspace, options *armoperationalinsights.WorkspacesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armoperationalinsights.WorkspacesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armoperationalinsights.WorkspacesClientCreateOrUpdateResponse{
+					Workspace: armoperationalinsights.Workspace{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.OperationalInsights/workspaces/%s", subscriptionID, resourceGroupName, workspaceName)),
+						Name: to.Ptr(workspaceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		WorkspacePurgeServer: operationalinsightsfake.WorkspacePurgeServer{
+			Purge: func(ctx context.Context, resourceGroupName string, workspaceName string, body armoperationalinsights.WorkspacePurgeBody, options *armoperationalinsights.WorkspacePurgeClientPurgeOptions) (resp azfake.Responder[armoperationalinsights.WorkspacePurgeClientPurgeResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusAccepted, armoperationalinsights.WorkspacePurgeClientPurgeResponse{
+					WorkspacePurgeResponse: armoperationalinsights.WorkspacePurgeResponse{
+						OperationID: to.Ptr("00000000-0000-0000-0000-000000000001"),
+					},
+					XMSStatusLocation: to.Ptr(fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/providers/Microsoft.OperationalInsights/workspaces/%s/operations/00000000-0000-0000-0000-000000000001", subscriptionID, resourceGroupName, workspaceName)),
+				}, nil)
+				return
+			},
+			GetPurgeStatus: func(ctx context.Context, resourceGroupName string, workspaceName string, purgeID string, options *armoperationalinsights.WorkspacePurgeClientGetPurgeStatusOptions) (resp azfake.Responder[armoperationalinsights.WorkspacePurgeClientGetPurgeStatusResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armoperationalinsights.WorkspacePurgeClientGetPurgeStatusResponse{
+					WorkspacePurgeStatusResponse: armoperationalinsights.WorkspacePurgeStatusResponse{
+						Status: to.Ptr(armoperationalinsights.PurgeStateCompleted),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: operationalinsightsfake.NewServerFactoryTransport(&operationalinsightsServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
+	workspacePurgeClient = operationalinsightsClientFactory.NewWorkspacePurgeClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	workspace, err := createWorkspace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("operational insights workspace:", *workspace.ID)
+
+	purge, err := purgeWorkspace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("purge workspace:", *purge.OperationID, *purge.XMSStatusLocation)
+
+	purgeStatus, err := purgeStatusWorkspace(ctx, *purge.OperationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("purge status workspace:", *purgeStatus.Status)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
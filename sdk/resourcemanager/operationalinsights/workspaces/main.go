@@ -5,89 +5,75 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	workspaceName     = "sample-workspace"
 )
 
 var (
-	resourcesClientFactory           *armresources.ClientFactory
 	operationalinsightsClientFactory *armoperationalinsights.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	workspacesClient    *armoperationalinsights.WorkspacesClient
+	workspacesClient *armoperationalinsights.WorkspacesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"workspaceName":     &workspaceName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
 
-	operationalinsightsClientFactory, err = armoperationalinsights.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.OperationalInsights"}}); err != nil {
+		return err
 	}
-	workspacesClient = operationalinsightsClientFactory.NewWorkspacesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	workspace, err := createWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("operational insights workspace:", *workspace.ID)
 
 	workspace, err = getWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("operational insights get workspace:", *workspace.ID)
 
 	workspaces, err := listWorkspace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for _, w := range workspaces {
 		log.Println(*w.Name, *w.ID)
 	}
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createWorkspace(ctx context.Context) (*armoperationalinsights.Workspace, error) {
@@ -105,7 +91,7 @@ func createWorkspace(ctx context.Context) (*armoperationalinsights.Workspace, er
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create workspace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -132,32 +118,3 @@ func listWorkspace(ctx context.Context) ([]*armoperationalinsights.Workspace, er
 	workspaces = append(workspaces, pager.Value...)
 	return workspaces, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,84 +5,73 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sampleyserver"
 	configurationName = "sample-postgresql-configuration"
+	serverSKUName     = "B_Gen5_1"
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	postgresqlClientFactory *armpostgresql.ClientFactory
 )
 
 var (
-	resourceGroupClient  *armresources.ResourceGroupsClient
 	serversClient        *armpostgresql.ServersClient
 	configurationsClient *armpostgresql.ConfigurationsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.PostgreSQLServer),
+		"configurationName": &configurationName,
+		"serverSKUName":     &serverSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	postgresqlClientFactory, err = armpostgresql.NewClientFactory(subscriptionID, cred, nil)
+	postgresqlClientFactory, err = armpostgresql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = postgresqlClientFactory.NewServersClient()
 	configurationsClient = postgresqlClientFactory.NewConfigurationsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DBforPostgreSQL"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	server, err := createServer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("postgresql server:", *server.ID)
 
 	configuration, err := createConfiguration(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("postgresql configuration:", *configuration.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armpostgresql.Server, error) {
@@ -102,7 +91,7 @@ func createServer(ctx context.Context) (*armpostgresql.Server, error) {
 				AdministratorLoginPassword: to.Ptr("QWE123!@#"),
 			},
 			SKU: &armpostgresql.SKU{
-				Name: to.Ptr("B_Gen5_1"),
+				Name: to.Ptr(serverSKUName),
 			},
 		},
 		nil,
@@ -110,7 +99,7 @@ func createServer(ctx context.Context) (*armpostgresql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -135,38 +124,9 @@ func createConfiguration(ctx context.Context) (*armpostgresql.Configuration, err
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create configuration", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Configuration, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
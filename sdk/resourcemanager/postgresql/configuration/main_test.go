@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
+	postgresqlfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestConfiguration(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	postgresqlServer := postgresqlfake.ServerFactory{
+		ServersServer: postgresqlfake.ServersServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, serverName string, parameters armpostgresql.ServerForCreate, options *armpostgresql.ServersClientBeginCreateOptions) (resp azfake.PollerResponder[armpostgresql.ServersClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armpostgresql.ServersClientCreateResponse{
+					Server: armpostgresql.Server{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforPostgreSQL/servers/%s", subscriptionID, resourceGroupName, serverName)),
+						Name: to.Ptr(serverName),
+					},
+				}, nil)
+				return
+			},
+		},
+		ConfigurationsServer: postgresqlfake.ConfigurationsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, configurationName string, parameters armpostgresql.Configuration, options *armpostgresql.ConfigurationsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armpostgresql.ConfigurationsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armpostgresql.ConfigurationsClientCreateOrUpdateResponse{
+					Configuration: armpostgresql.Configuration{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DBforPostgreSQL/servers/%s/configurations/%s", subscriptionID, resourceGroupName, serverName, configurationName)),
+						Name: to.Ptr(configurationName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	postgresqlClientFactory, err = armpostgresql.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: postgresqlfake.NewServerFactoryTransport(&postgresqlServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversClient = postgresqlClientFactory.NewServersClient()
+	configurationsClient = postgresqlClientFactory.NewConfigurationsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	server, err := createServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("postgresql server:", *server.ID)
+
+	configuration, err := createConfiguration(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("postgresql configuration:", *configuration.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
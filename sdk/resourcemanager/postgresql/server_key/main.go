@@ -5,17 +5,14 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	TenantID          string
 	ObjectID          string
 	location          = "eastus"
@@ -24,102 +21,92 @@ var (
 	vaultName         = "sample2vault"
 	keyName           = "sample2key"
 	serverKeyName     = "sample-postgresql-key"
+	serverSKUName     = "B_Gen5_1"
+	vaultSKUName      = string(armkeyvault.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	keyvaultClientFactory   *armkeyvault.ClientFactory
 	postgresqlClientFactory *armpostgresql.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	vaultsClient        *armkeyvault.VaultsClient
-	keysClient          *armkeyvault.KeysClient
-	serversClient       *armpostgresql.ServersClient
-	serverKeysClient    *armpostgresql.ServerKeysClient
+	vaultsClient     *armkeyvault.VaultsClient
+	keysClient       *armkeyvault.KeysClient
+	serversClient    *armpostgresql.ServersClient
+	serverKeysClient *armpostgresql.ServerKeysClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.PostgreSQLServer),
+		"vaultName":         samplekit.Unique(&vaultName, samplekit.KeyVault),
+		"keyName":           &keyName,
+		"serverKeyName":     &serverKeyName,
+		"serverSKUName":     &serverSKUName,
+		"vaultSKUName":      &vaultSKUName,
+	}, run)
+}
 
-	TenantID = os.Getenv("AZURE_TENANT_ID")
-	if len(TenantID) == 0 {
-		log.Fatal("AZURE_TENANT_ID is not set.")
-	}
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	ObjectID = os.Getenv("AZURE_OBJECT_ID")
-	if len(ObjectID) == 0 {
-		log.Fatal("AZURE_OBJECT_ID is not set.")
-	}
+	// the IDs of the principal the sample signed in as
+	TenantID = sample.TenantID
+	ObjectID = sample.ObjectID
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	keyvaultClientFactory, err = armkeyvault.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
-
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	keyvaultClientFactory, err = armkeyvault.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	vaultsClient = keyvaultClientFactory.NewVaultsClient()
 	keysClient = keyvaultClientFactory.NewKeysClient()
 
-	postgresqlClientFactory, err = armpostgresql.NewClientFactory(subscriptionID, cred, nil)
+	postgresqlClientFactory, err = armpostgresql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = postgresqlClientFactory.NewServersClient()
 	serverKeysClient = postgresqlClientFactory.NewServerKeysClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.DBforPostgreSQL", "Microsoft.KeyVault"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	server, err := createServer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("postgresql server:", *server.ID)
 
 	vault, err := createVault(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("vault:", *vault.ID)
 
 	key, err := createKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("key:", *key.ID)
 
 	serverKey, err := createServerKey(ctx, *key.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("postgresql server key:", *serverKey.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armpostgresql.Server, error) {
@@ -139,7 +126,7 @@ func createServer(ctx context.Context) (*armpostgresql.Server, error) {
 				AdministratorLoginPassword: to.Ptr("QWE123!@#"),
 			},
 			SKU: &armpostgresql.SKU{
-				Name: to.Ptr("B_Gen5_1"),
+				Name: to.Ptr(serverSKUName),
 			},
 		},
 		nil,
@@ -147,7 +134,7 @@ func createServer(ctx context.Context) (*armpostgresql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +152,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 			Properties: &armkeyvault.VaultProperties{
 				SKU: &armkeyvault.SKU{
 					Family: to.Ptr(armkeyvault.SKUFamilyA),
-					Name:   to.Ptr(armkeyvault.SKUNameStandard),
+					Name:   to.Ptr(armkeyvault.SKUName(vaultSKUName)),
 				},
 				TenantID: to.Ptr(TenantID),
 				AccessPolicies: []*armkeyvault.AccessPolicyEntry{
@@ -213,7 +200,7 @@ func createVault(ctx context.Context) (*armkeyvault.Vault, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create vault", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -267,38 +254,9 @@ func createServerKey(ctx context.Context, keyID string) (*armpostgresql.ServerKe
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server key", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.ServerKey, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID        string
 	location              = "westus"
 	resourceGroupName     = "sample-resource-group"
 	privateZoneName       = "sample-private-zone"
@@ -22,67 +19,57 @@ var (
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	privatednsClientFactory *armprivatedns.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	privateZonesClient  *armprivatedns.PrivateZonesClient
-	recordSetsClient    *armprivatedns.RecordSetsClient
+	privateZonesClient *armprivatedns.PrivateZonesClient
+	recordSetsClient   *armprivatedns.RecordSetsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"privateZoneName":       &privateZoneName,
+		"relativeRecordSetName": &relativeRecordSetName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	privatednsClientFactory, err = armprivatedns.NewClientFactory(subscriptionID, cred, nil)
+	privatednsClientFactory, err = armprivatedns.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	privateZonesClient = privatednsClientFactory.NewPrivateZonesClient()
 	recordSetsClient = privatednsClientFactory.NewRecordSetsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	privateZone, err := createPrivateZone(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("private zone:", *privateZone.ID)
 
 	recordSets, err := createRecordSets(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("record sets:", *recordSets.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createPrivateZone(ctx context.Context) (*armprivatedns.PrivateZone, error) {
@@ -99,7 +86,7 @@ func createPrivateZone(ctx context.Context) (*armprivatedns.PrivateZone, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollersResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create private zone", pollersResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -126,32 +113,3 @@ func createRecordSets(ctx context.Context) (*armprivatedns.RecordSet, error) {
 	}
 	return &resp.RecordSet, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,17 +5,14 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID         string
 	location               = "westus"
 	resourceGroupName      = "sample-resource-group"
 	virtualNetworkName     = "sample-virtual-network"
@@ -25,13 +22,11 @@ var (
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	networkClientFactory    *armnetwork.ClientFactory
 	privatednsClientFactory *armprivatedns.ClientFactory
 )
 
 var (
-	resourceGroupClient       *armresources.ResourceGroupsClient
 	virtualNetworksClient     *armnetwork.VirtualNetworksClient
 	subnetsClient             *armnetwork.SubnetsClient
 	privateZonesClient        *armprivatedns.PrivateZonesClient
@@ -39,75 +34,69 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":               &location,
+		"resourceGroupName":      &resourceGroupName,
+		"virtualNetworkName":     &virtualNetworkName,
+		"subnetName":             &subnetName,
+		"privateZoneName":        &privateZoneName,
+		"virtualNetworkLinkName": &virtualNetworkLinkName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
 
-	privatednsClientFactory, err = armprivatedns.NewClientFactory(subscriptionID, cred, nil)
+	privatednsClientFactory, err = armprivatedns.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	privateZonesClient = privatednsClientFactory.NewPrivateZonesClient()
 	virtualNetworkLinksClient = privatednsClientFactory.NewVirtualNetworkLinksClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	privateZone, err := createPrivateZone(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("private zone:", *privateZone.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	vnetLink, err := createVirtualNetworkLink(ctx, *subnet.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network link:", *vnetLink.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createPrivateZone(ctx context.Context) (*armprivatedns.PrivateZone, error) {
@@ -124,7 +113,7 @@ func createPrivateZone(ctx context.Context) (*armprivatedns.PrivateZone, error)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollersResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create private zone", pollersResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +142,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +167,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -206,38 +195,9 @@ func createVirtualNetworkLink(ctx context.Context, subnetID string) (*armprivate
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollersResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network link", pollersResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.VirtualNetworkLink, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
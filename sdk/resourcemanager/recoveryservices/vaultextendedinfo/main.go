@@ -6,89 +6,77 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID    string
-	location          = "westus"
-	resourceGroupName = "sample-resource-group"
-	vaultName         = "sample-recoveryservice-vault"
+	location                    = "westus"
+	resourceGroupName           = "sample-resource-group"
+	vaultName                   = "sample-recoveryservice-vault"
+	recoveryServiceVaultSKUName = string(armrecoveryservices.SKUNameStandard)
 )
 
 var (
-	resourcesClientFactory        *armresources.ClientFactory
 	recoveryservicesClientFactory *armrecoveryservices.ClientFactory
 )
 
 var (
-	resourceGroupClient     *armresources.ResourceGroupsClient
 	vaultsClient            *armrecoveryservices.VaultsClient
 	vaultExtendedInfoClient *armrecoveryservices.VaultExtendedInfoClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":                    &location,
+		"resourceGroupName":           &resourceGroupName,
+		"vaultName":                   &vaultName,
+		"recoveryServiceVaultSKUName": &recoveryServiceVaultSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	recoveryservicesClientFactory, err = armrecoveryservices.NewClientFactory(subscriptionID, cred, nil)
+	recoveryservicesClientFactory, err = armrecoveryservices.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	vaultsClient = recoveryservicesClientFactory.NewVaultsClient()
 	vaultExtendedInfoClient = recoveryservicesClientFactory.NewVaultExtendedInfoClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.RecoveryServices"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	vault, err := createRecoveryServiceVault(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("recovery service vault:", *vault.ID)
 
 	vaultExtendedInfo, err := createVaultExtendedInfo(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("recovery service vault extended info:", *vaultExtendedInfo.ID)
 
 	vaultExtendedInfo, err = getVaultExtendedInfo(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get recovery service vault extended info:", *vaultExtendedInfo.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createRecoveryServiceVault(ctx context.Context) (*armrecoveryservices.Vault, error) {
@@ -100,7 +88,7 @@ func createRecoveryServiceVault(ctx context.Context) (*armrecoveryservices.Vault
 		armrecoveryservices.Vault{
 			Location: to.Ptr(location),
 			SKU: &armrecoveryservices.SKU{
-				Name: to.Ptr(armrecoveryservices.SKUNameStandard),
+				Name: to.Ptr(armrecoveryservices.SKUName(recoveryServiceVaultSKUName)),
 			},
 			Properties: &armrecoveryservices.VaultProperties{},
 		},
@@ -110,7 +98,7 @@ func createRecoveryServiceVault(ctx context.Context) (*armrecoveryservices.Vault
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create recovery service vault", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -148,33 +136,3 @@ func getVaultExtendedInfo(ctx context.Context) (*armrecoveryservices.VaultExtend
 
 	return &resp.VaultExtendedInfoResource, err
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
+	recoveryservicesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestVaultextendedinfo(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	recoveryservicesServer := recoveryservicesfake.ServerFactory{
+		VaultsServer: recoveryservicesfake.VaultsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, vaultName string, vault armrecoveryservices.Vault, options *armrecoveryservices.VaultsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armrecoveryservices.VaultsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if vault.Location == nil || *vault.Location != location {
+					t.Errorf("unexpected location: %v", vault.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armrecoveryservices.VaultsClientCreateOrUpdateResponse{
+					Vault: armrecoveryservices.Vault{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.RecoveryServices/vaults/%s", subscriptionID, resourceGroupName, vaultName)),
+						Name: to.Ptr(vaultName),
+					},
+				}, nil)
+				return
+			},
+		},
+		VaultExtendedInfoServer: recoveryservicesfake.VaultExtendedInfoServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, vaultName string, resourceExtendedInfoDetails armrecoveryservices.VaultExtendedInfoResource, options *armrecoveryservices.VaultExtendedInfoClientCreateOrUpdateOptions) (resp azfake.Responder[armrecoveryservices.VaultExtendedInfoClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armrecoveryservices.VaultExtendedInfoClientCreateOrUpdateResponse{
+					VaultExtendedInfoResource: armrecoveryservices.VaultExtendedInfoResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.RecoveryServices/vaults/%s/extendedInformation/vaultExtendedInfo", subscriptionID, resourceGroupName, vaultName)),
+						Name: to.Ptr(vaultName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, vaultName string, options *armrecoveryservices.VaultExtendedInfoClientGetOptions) (resp azfake.Responder[armrecoveryservices.VaultExtendedInfoClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armrecoveryservices.VaultExtendedInfoClientGetResponse{
+					VaultExtendedInfoResource: armrecoveryservices.VaultExtendedInfoResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.RecoveryServices/vaults/%s/extendedInformation/vaultExtendedInfo", subscriptionID, resourceGroupName, vaultName)),
+						Name: to.Ptr(vaultName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	recoveryservicesClientFactory, err = armrecoveryservices.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: recoveryservicesfake.NewServerFactoryTransport(&recoveryservicesServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vaultsClient = recoveryservicesClientFactory.NewVaultsClient()
+	vaultExtendedInfoClient = recoveryservicesClientFactory.NewVaultExtendedInfoClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	vault, err := createRecoveryServiceVault(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("recovery service vault:", *vault.ID)
+
+	vaultExtendedInfo, err := createVaultExtendedInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("recovery service vault extended info:", *vaultExtendedInfo.ID)
+
+	vaultExtendedInfo, err = getVaultExtendedInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get recovery service vault extended info:", *vaultExtendedInfo.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
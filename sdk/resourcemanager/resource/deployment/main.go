@@ -7,16 +7,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"io/ioutil"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 	deploymentName    = "sample-deployment"
@@ -27,70 +25,65 @@ var (
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	deploymentsClient   *armresources.DeploymentsClient
+	deploymentsClient *armresources.DeploymentsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"deploymentName":    &deploymentName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	resourcesClientFactory, err = armresources.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
 	deploymentsClient = resourcesClientFactory.NewDeploymentsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Compute"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	exist, err := checkExistDeployment(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("deployment is exist:", exist)
 
 	template, err := readJson("testdata/template.json")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	params, err := readJson("testdata/parameters.json")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	deploymentExtended, err := createDeployment(ctx, template, params)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("created deployment:", *deploymentExtended.ID)
 
 	validateResult, err := validateDeployment(ctx, template, params)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	data, _ := json.Marshal(validateResult)
 	log.Println("validate deployment:", string(data))
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func readJson(path string) (map[string]interface{}, error) {
@@ -136,7 +129,7 @@ func createDeployment(ctx context.Context, template, params map[string]interface
 		return nil, fmt.Errorf("cannot create deployment: %v", err)
 	}
 
-	resp, err := deploymentPollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create deployment", deploymentPollerResp, nil)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get the create deployment future respone: %v", err)
 	}
@@ -163,39 +156,10 @@ func validateDeployment(ctx context.Context, template, params map[string]interfa
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "validate deployment", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	return &resp.DeploymentValidateResult, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
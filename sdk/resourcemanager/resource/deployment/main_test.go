@@ -0,0 +1,130 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestDeployment(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		DeploymentsServer: resourcesfake.DeploymentsServer{
+			CheckExistence: func(ctx context.Context, resourceGroupName string, deploymentName string, options *armresources.DeploymentsClientCheckExistenceOptions) (resp azfake.Responder[armresources.DeploymentsClientCheckExistenceResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusNotFound, armresources.DeploymentsClientCheckExistenceResponse{}, nil)
+				return
+			},
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, deploymentName string, parameters armresources.Deployment, options *armresources.DeploymentsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armresources.DeploymentsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.DeploymentsClientCreateOrUpdateResponse{
+					DeploymentExtended: armresources.DeploymentExtended{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.Resources/deployments/%s", subscriptionID, resourceGroupName, deploymentName)),
+						Name: to.Ptr(deploymentName),
+					},
+				}, nil)
+				return
+			},
+			BeginValidate: func(ctx context.Context, resourceGroupName string, deploymentName string, parameters armresources.Deployment, options *armresources.DeploymentsClientBeginValidateOptions) (resp azfake.PollerResponder[armresources.DeploymentsClientValidateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.DeploymentsClientValidateResponse{
+					DeploymentValidateResult: armresources.DeploymentValidateResult{},
+				}, nil)
+				return
+			},
+		},
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploymentsClient = resourcesClientFactory.NewDeploymentsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	exist, err := checkExistDeployment(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("deployment is exist:", exist)
+
+	template, err := readJson("testdata/template.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := readJson("testdata/parameters.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploymentExtended, err := createDeployment(ctx, template, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("created deployment:", *deploymentExtended.ID)
+
+	validateResult, err := validateDeployment(ctx, template, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(validateResult)
+	t.Log("validate deployment:", string(data))
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,14 +8,12 @@ import (
 	"encoding/json"
 	"log"
 	"math"
-	"os"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 var (
-	subscriptionID            string
 	resourceProviderNamespace = "Microsoft.Compute"
 )
 
@@ -28,38 +26,36 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	// providers live at subscription and tenant scope, so this sample needs no resource group.
+	samplekit.Main(samplekit.Settings{
+		"resourceProviderNamespace": &resourceProviderNamespace,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	resourcesClientFactory, err = armresources.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	providersClient = resourcesClientFactory.NewProvidersClient()
 
 	provider, err := registerProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("registered provider:", *provider.ID)
 
 	provider, err = getProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get provider:", *provider.ID)
 
 	providers, err := listProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("providers:", len(providers))
 	l := math.Min(10, float64(len(providers)))
@@ -69,18 +65,18 @@ func main() {
 
 	providerPermissionsResult, err := providerPermissions(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	data, err := json.Marshal(providerPermissionsResult)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println(string(data))
 
 	// Tenant
 	providers, err = listAtTenantScopeProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("list providers:", len(providers))
 	l = math.Min(10, float64(len(providers)))
@@ -90,9 +86,11 @@ func main() {
 
 	atTenant, err := getAtTenantScopeProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get atTenant:", *atTenant.Namespace)
+
+	return nil
 }
 
 func registerProvider(ctx context.Context) (*armresources.Provider, error) {
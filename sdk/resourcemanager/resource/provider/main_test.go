@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+)
+
+func TestProvider(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ProvidersServer: resourcesfake.ProvidersServer{
+			Register: func(ctx context.Context, resourceProviderNamespace string, options *armresources.ProvidersClientRegisterOptions) (resp azfake.Responder[armresources.ProvidersClientRegisterResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armresources.ProvidersClientRegisterResponse{
+					Provider: armresources.Provider{
+						ID:                to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/%s", subscriptionID, resourceProviderNamespace)),
+						Namespace:         to.Ptr(resourceProviderNamespace),
+						RegistrationState: to.Ptr("Registered"),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceProviderNamespace string, options *armresources.ProvidersClientGetOptions) (resp azfake.Responder[armresources.ProvidersClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armresources.ProvidersClientGetResponse{
+					Provider: armresources.Provider{
+						ID:                to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/%s", subscriptionID, resourceProviderNamespace)),
+						Namespace:         to.Ptr(resourceProviderNamespace),
+						RegistrationState: to.Ptr("Registered"),
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armresources.ProvidersClientListOptions) (resp azfake.PagerResponder[armresources.ProvidersClientListResponse]) {
+				resp.AddPage(http.StatusOK, armresources.ProvidersClientListResponse{
+					ProviderListResult: armresources.ProviderListResult{
+						Value: []*armresources.Provider{
+							{
+								ID:                to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/%s", subscriptionID, resourceProviderNamespace)),
+								Namespace:         to.Ptr(resourceProviderNamespace),
+								RegistrationState: to.Ptr("Registered"),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			GetAtTenantScope: func(ctx context.Context, resourceProviderNamespace string, options *armresources.ProvidersClientGetAtTenantScopeOptions) (resp azfake.Responder[armresources.ProvidersClientGetAtTenantScopeResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armresources.ProvidersClientGetAtTenantScopeResponse{
+					Provider: armresources.Provider{
+						ID:                to.Ptr(fmt.Sprintf("/providers/%s", resourceProviderNamespace)),
+						Namespace:         to.Ptr(resourceProviderNamespace),
+						RegistrationState: to.Ptr("Registered"),
+					},
+				}, nil)
+				return
+			},
+			NewListAtTenantScopePager: func(options *armresources.ProvidersClientListAtTenantScopeOptions) (resp azfake.PagerResponder[armresources.ProvidersClientListAtTenantScopeResponse]) {
+				resp.AddPage(http.StatusOK, armresources.ProvidersClientListAtTenantScopeResponse{
+					ProviderListResult: armresources.ProviderListResult{
+						Value: []*armresources.Provider{
+							{
+								ID:                to.Ptr(fmt.Sprintf("/providers/%s", resourceProviderNamespace)),
+								Namespace:         to.Ptr(resourceProviderNamespace),
+								RegistrationState: to.Ptr("Registered"),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			ProviderPermissions: func(ctx context.Context, resourceProviderNamespace string, options *armresources.ProvidersClientProviderPermissionsOptions) (resp azfake.Responder[armresources.ProvidersClientProviderPermissionsResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armresources.ProvidersClientProviderPermissionsResponse{
+					ProviderPermissionListResult: armresources.ProviderPermissionListResult{},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	var err error
+
+	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	providersClient = resourcesClientFactory.NewProvidersClient()
+
+	provider, err := registerProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("registered provider:", *provider.ID)
+
+	provider, err = getProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get provider:", *provider.ID)
+
+	providers, err := listProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("providers:", len(providers))
+	l := math.Min(10, float64(len(providers)))
+	for i := 0; i < int(l); i++ {
+		t.Logf("Namespace: %s,RegistratonState: %s\n", *providers[i].Namespace, *providers[i].RegistrationState)
+	}
+
+	providerPermissionsResult, err := providerPermissions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(providerPermissionsResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(string(data))
+
+	// Tenant
+	providers, err = listAtTenantScopeProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list providers:", len(providers))
+	l = math.Min(10, float64(len(providers)))
+	for i := 0; i < int(l); i++ {
+		t.Log("Namespace:", *providers[i].Namespace)
+	}
+
+	atTenant, err := getAtTenantScopeProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get atTenant:", *atTenant.Namespace)
+}
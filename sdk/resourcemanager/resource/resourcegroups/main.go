@@ -5,15 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group"
 )
@@ -27,46 +25,44 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	resourcesClientFactory, err = armresources.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
 
 	exits, err := checkExistenceResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group already exist:", exits)
 
 	if !exits {
-		resourceGroup, err := createResourceGroup(ctx)
+		resourceGroup, err := sample.CreateResourceGroup(ctx)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		log.Println("resources group:", *resourceGroup.ID)
 	}
 
 	resourceGroup, err := getResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get resources group:", *resourceGroup.ID)
 
 	resourceGroups, err := listResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for _, resource := range resourceGroups {
 		log.Printf("Resource Group Name: %s,ID: %s", *resource.Name, *resource.ID)
@@ -74,33 +70,11 @@ func main() {
 
 	template, err := exportTemplateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("export template: %#v", template.Template)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
-}
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
+	return nil
 }
 
 func getResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
@@ -151,23 +125,9 @@ func exportTemplateResourceGroup(ctx context.Context) (*armresources.ResourceGro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "export template resource group", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.ResourceGroupExportResult, nil
 }
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -5,18 +5,16 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID             string
 	location                   = "westus"
+	pairedLocation             = "eastus"
 	resourceGroupName          = "sample-resource-group"
 	virtualNetworkName         = "sample-virtual-network"
 	subnetName                 = "sample-subnet"
@@ -24,16 +22,15 @@ var (
 	namespacePrimaryName       = "sample-sb-namespace-primary"
 	authorizationRuleName      = "sample-sb-authorization-rule"
 	disasterRecoveryConfigName = "sample-disaster-recovery"
+	namespaceSKUName           = string(armservicebus.SKUNamePremium)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	networkClientFactory    *armnetwork.ClientFactory
 	servicebusClientFactory *armservicebus.ClientFactory
 )
 
 var (
-	resourceGroupClient           *armresources.ResourceGroupsClient
 	virtualNetworksClient         *armnetwork.VirtualNetworksClient
 	subnetsClient                 *armnetwork.SubnetsClient
 	namespacesClient              *armservicebus.NamespacesClient
@@ -41,76 +38,81 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":                   &location,
+		"pairedLocation":             &pairedLocation,
+		"resourceGroupName":          &resourceGroupName,
+		"virtualNetworkName":         &virtualNetworkName,
+		"subnetName":                 &subnetName,
+		"namespaceName":              samplekit.Unique(&namespaceName, samplekit.ServiceBusNamespace),
+		"namespacePrimaryName":       samplekit.Unique(&namespacePrimaryName, samplekit.ServiceBusNamespace),
+		"authorizationRuleName":      &authorizationRuleName,
+		"disasterRecoveryConfigName": &disasterRecoveryConfigName,
+		"namespaceSKUName":           &namespaceSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, nil)
+	networkClientFactory, err = armnetwork.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
 	subnetsClient = networkClientFactory.NewSubnetsClient()
 
-	servicebusClientFactory, err = armservicebus.NewClientFactory(subscriptionID, cred, nil)
+	servicebusClientFactory, err = armservicebus.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	namespacesClient = servicebusClientFactory.NewNamespacesClient()
 	disasterRecoveryConfigsClient = servicebusClientFactory.NewDisasterRecoveryConfigsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ServiceBus", "Microsoft.Network"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	virtualNetwork, err := createVirtualNetwork(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("virtual network:", *virtualNetwork.ID)
 
 	subnet, err := createSubnet(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("subnet:", *subnet.ID)
 
 	namespace, err := createNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus namespace:", *namespace.ID)
 
 	namespacePrimary, err := createNamespacePrimary(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus namespace primary:", *namespacePrimary.ID)
 
 	namespaceAuthorizationRule, err := createNamespaceAuthorizationRule(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus namespace authorization rule:", *namespaceAuthorizationRule.ID)
 
 	exist, err := checkNameAvailability(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if exist {
 		log.Println("disaster recovery name existed.")
@@ -118,30 +120,23 @@ func main() {
 
 	disasterRecoveryConfig, err := createDisasterRecoveryConfig(ctx, *namespacePrimary.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus disaster recovery config:", *disasterRecoveryConfig.ID)
 
 	disasterRecoveryConfig, err = getDisasterRecoveryConfig(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get service bus disaster recovery config:", *disasterRecoveryConfig.ID)
 
 	err = failOverDisasterRecoveryConfig(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus fail over")
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, error) {
@@ -166,7 +161,7 @@ func createVirtualNetwork(ctx context.Context) (*armnetwork.VirtualNetwork, erro
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create virtual network", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +187,7 @@ func createSubnet(ctx context.Context) (*armnetwork.Subnet, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create subnet", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +203,7 @@ func createNamespace(ctx context.Context) (*armservicebus.SBNamespace, error) {
 		armservicebus.SBNamespace{
 			Location: to.Ptr(location),
 			SKU: &armservicebus.SBSKU{
-				Name: to.Ptr(armservicebus.SKUNamePremium),
+				Name: to.Ptr(armservicebus.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armservicebus.SKUTierPremium),
 			},
 		},
@@ -218,7 +213,7 @@ func createNamespace(ctx context.Context) (*armservicebus.SBNamespace, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -232,9 +227,9 @@ func createNamespacePrimary(ctx context.Context) (*armservicebus.SBNamespace, er
 		resourceGroupName,
 		namespacePrimaryName,
 		armservicebus.SBNamespace{
-			Location: to.Ptr("eastus"),
+			Location: to.Ptr(pairedLocation),
 			SKU: &armservicebus.SBSKU{
-				Name: to.Ptr(armservicebus.SKUNamePremium),
+				Name: to.Ptr(armservicebus.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armservicebus.SKUTierPremium),
 			},
 		},
@@ -244,7 +239,7 @@ func createNamespacePrimary(ctx context.Context) (*armservicebus.SBNamespace, er
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace primary", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -332,32 +327,3 @@ func failOverDisasterRecoveryConfig(ctx context.Context) error {
 
 	return nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
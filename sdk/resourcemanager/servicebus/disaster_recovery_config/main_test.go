@@ -0,0 +1,242 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	networkfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
+	servicebusfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus/fake"
+)
+
+func TestDisasterRecoveryConfig(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	networkServer := networkfake.ServerFactory{
+		VirtualNetworksServer: networkfake.VirtualNetworksServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, virtualNetworkName string, parameters armnetwork.VirtualNetwork, options *armnetwork.VirtualNetworksClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armnetwork.VirtualNetworksClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.VirtualNetworksClientCreateOrUpdateResponse{
+					VirtualNetwork: armnetwork.VirtualNetwork{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s", subscriptionID, resourceGroupName, virtualNetworkName)),
+						Name: to.Ptr(virtualNetworkName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SubnetsServer: networkfake.SubnetsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, virtualNetworkName string, subnetName string, subnetParameters armnetwork.Subnet, options *armnetwork.SubnetsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armnetwork.SubnetsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.SubnetsClientCreateOrUpdateResponse{
+					Subnet: armnetwork.Subnet{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/%s/subnets/%s", subscriptionID, resourceGroupName, virtualNetworkName, subnetName)),
+						Name: to.Ptr(subnetName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+	servicebusServer := servicebusfake.ServerFactory{
+		NamespacesServer: servicebusfake.NamespacesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, parameters armservicebus.SBNamespace, options *armservicebus.NamespacesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armservicebus.NamespacesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				// the primary namespace of the pairing lives in another region
+				expected := location
+				if namespaceName == namespacePrimaryName {
+					expected = pairedLocation
+				}
+				if parameters.Location == nil || *parameters.Location != expected {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armservicebus.NamespacesClientCreateOrUpdateResponse{
+					SBNamespace: armservicebus.SBNamespace{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s", subscriptionID, resourceGroupName, namespaceName)),
+						Name: to.Ptr(namespaceName),
+					},
+				}, nil)
+				return
+			},
+			CreateOrUpdateAuthorizationRule: func(ctx context.Context, resourceGroupName string, namespaceName string, authorizationRuleName string, parameters armservicebus.SBAuthorizationRule, options *armservicebus.NamespacesClientCreateOrUpdateAuthorizationRuleOptions) (resp azfake.Responder[armservicebus.NamespacesClientCreateOrUpdateAuthorizationRuleResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.NamespacesClientCreateOrUpdateAuthorizationRuleResponse{
+					SBAuthorizationRule: armservicebus.SBAuthorizationRule{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/AuthorizationRules/%s", subscriptionID, resourceGroupName, namespaceName, authorizationRuleName)),
+						Name: to.Ptr(authorizationRuleName),
+					},
+				}, nil)
+				return
+			},
+		},
+		DisasterRecoveryConfigsServer: servicebusfake.DisasterRecoveryConfigsServer{
+			CheckNameAvailability: func(ctx context.Context, resourceGroupName string, namespaceName string, parameters armservicebus.CheckNameAvailability, options *armservicebus.DisasterRecoveryConfigsClientCheckNameAvailabilityOptions) (resp azfake.Responder[armservicebus.DisasterRecoveryConfigsClientCheckNameAvailabilityResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.DisasterRecoveryConfigsClientCheckNameAvailabilityResponse{
+					CheckNameAvailabilityResult: armservicebus.CheckNameAvailabilityResult{
+						NameAvailable: to.Ptr(true),
+					},
+				}, nil)
+				return
+			},
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, alias string, parameters armservicebus.ArmDisasterRecovery, options *armservicebus.DisasterRecoveryConfigsClientCreateOrUpdateOptions) (resp azfake.Responder[armservicebus.DisasterRecoveryConfigsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.DisasterRecoveryConfigsClientCreateOrUpdateResponse{
+					ArmDisasterRecovery: armservicebus.ArmDisasterRecovery{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/disasterRecoveryConfigs/%s", subscriptionID, resourceGroupName, namespaceName, alias)),
+						Name: to.Ptr(alias),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, namespaceName string, alias string, options *armservicebus.DisasterRecoveryConfigsClientGetOptions) (resp azfake.Responder[armservicebus.DisasterRecoveryConfigsClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.DisasterRecoveryConfigsClientGetResponse{
+					ArmDisasterRecovery: armservicebus.ArmDisasterRecovery{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/disasterRecoveryConfigs/%s", subscriptionID, resourceGroupName, namespaceName, alias)),
+						Name: to.Ptr(alias),
+					},
+				}, nil)
+				return
+			},
+			FailOver: func(ctx context.Context, resourceGroupName string, namespaceName string, alias string, options *armservicebus.DisasterRecoveryConfigsClientFailOverOptions) (resp azfake.Responder[armservicebus.DisasterRecoveryConfigsClientFailOverResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.DisasterRecoveryConfigsClientFailOverResponse{}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	networkClientFactory, err = armnetwork.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: networkfake.NewServerFactoryTransport(&networkServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	virtualNetworksClient = networkClientFactory.NewVirtualNetworksClient()
+	subnetsClient = networkClientFactory.NewSubnetsClient()
+
+	servicebusClientFactory, err = armservicebus.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: servicebusfake.NewServerFactoryTransport(&servicebusServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespacesClient = servicebusClientFactory.NewNamespacesClient()
+	disasterRecoveryConfigsClient = servicebusClientFactory.NewDisasterRecoveryConfigsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	virtualNetwork, err := createVirtualNetwork(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("virtual network:", *virtualNetwork.ID)
+
+	subnet, err := createSubnet(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("subnet:", *subnet.ID)
+
+	namespace, err := createNamespace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus namespace:", *namespace.ID)
+
+	namespacePrimary, err := createNamespacePrimary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus namespace primary:", *namespacePrimary.ID)
+
+	namespaceAuthorizationRule, err := createNamespaceAuthorizationRule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus namespace authorization rule:", *namespaceAuthorizationRule.ID)
+
+	exist, err := checkNameAvailability(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exist {
+		t.Log("disaster recovery name existed.")
+	}
+
+	disasterRecoveryConfig, err := createDisasterRecoveryConfig(ctx, *namespacePrimary.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus disaster recovery config:", *disasterRecoveryConfig.ID)
+
+	disasterRecoveryConfig, err = getDisasterRecoveryConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get service bus disaster recovery config:", *disasterRecoveryConfig.ID)
+
+	err = failOverDisasterRecoveryConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus fail over")
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
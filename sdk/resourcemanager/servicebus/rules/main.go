@@ -5,31 +5,27 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "westus"
 	resourceGroupName = "sample-resource-group2"
 	namespaceName     = "sample-sb-namespace"
 	topicName         = "sample-sb-topic"
 	subscriptionName  = "sample-subscription"
 	ruleName          = "sample-rule"
+	namespaceSKUName  = string(armservicebus.SKUNamePremium)
 )
 
 var (
-	resourcesClientFactory  *armresources.ClientFactory
 	servicebusClientFactory *armservicebus.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
 	namespacesClient    *armservicebus.NamespacesClient
 	topicsClient        *armservicebus.TopicsClient
 	subscriptionsClient *armservicebus.SubscriptionsClient
@@ -37,69 +33,65 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"namespaceName":     samplekit.Unique(&namespaceName, samplekit.ServiceBusNamespace),
+		"topicName":         &topicName,
+		"subscriptionName":  &subscriptionName,
+		"ruleName":          &ruleName,
+		"namespaceSKUName":  &namespaceSKUName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-	servicebusClientFactory, err = armservicebus.NewClientFactory(subscriptionID, cred, nil)
+	servicebusClientFactory, err = armservicebus.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	namespacesClient = servicebusClientFactory.NewNamespacesClient()
 	topicsClient = servicebusClientFactory.NewTopicsClient()
 	subscriptionsClient = servicebusClientFactory.NewSubscriptionsClient()
 	rulesClient = servicebusClientFactory.NewRulesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ServiceBus"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	namespace, err := createNamespace(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus namespace:", *namespace.ID)
 
 	topic, err := createTopic(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus topic:", *topic.ID)
 
 	subscription, err := createSubscription(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service bus subscription:", *subscription.ID)
 
 	rules, err := createRules(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("rules:", *rules.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createNamespace(ctx context.Context) (*armservicebus.SBNamespace, error) {
@@ -111,7 +103,7 @@ func createNamespace(ctx context.Context) (*armservicebus.SBNamespace, error) {
 		armservicebus.SBNamespace{
 			Location: to.Ptr(location),
 			SKU: &armservicebus.SBSKU{
-				Name: to.Ptr(armservicebus.SKUNamePremium),
+				Name: to.Ptr(armservicebus.SKUName(namespaceSKUName)),
 				Tier: to.Ptr(armservicebus.SKUTierPremium),
 			},
 		},
@@ -121,7 +113,7 @@ func createNamespace(ctx context.Context) (*armservicebus.SBNamespace, error) {
 		return nil, err
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create namespace", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -180,32 +172,3 @@ func createRules(ctx context.Context) (*armservicebus.Rule, error) {
 	}
 	return &resp.Rule, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
+	servicebusfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus/fake"
+)
+
+func TestRules(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	servicebusServer := servicebusfake.ServerFactory{
+		NamespacesServer: servicebusfake.NamespacesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, parameters armservicebus.SBNamespace, options *armservicebus.NamespacesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armservicebus.NamespacesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armservicebus.NamespacesClientCreateOrUpdateResponse{
+					SBNamespace: armservicebus.SBNamespace{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s", subscriptionID, resourceGroupName, namespaceName)),
+						Name: to.Ptr(namespaceName),
+					},
+				}, nil)
+				return
+			},
+		},
+		TopicsServer: servicebusfake.TopicsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, topicName string, parameters armservicebus.SBTopic, options *armservicebus.TopicsClientCreateOrUpdateOptions) (resp azfake.Responder[armservicebus.TopicsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.TopicsClientCreateOrUpdateResponse{
+					SBTopic: armservicebus.SBTopic{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/topics/%s", subscriptionID, resourceGroupName, namespaceName, topicName)),
+						Name: to.Ptr(topicName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SubscriptionsServer: servicebusfake.SubscriptionsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, topicName string, subscriptionName string, parameters armservicebus.SBSubscription, options *armservicebus.SubscriptionsClientCreateOrUpdateOptions) (resp azfake.Responder[armservicebus.SubscriptionsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.SubscriptionsClientCreateOrUpdateResponse{
+					SBSubscription: armservicebus.SBSubscription{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/topics/%s/subscriptions/%s", subscriptionID, resourceGroupName, namespaceName, topicName, subscriptionName)),
+						Name: to.Ptr(subscriptionName),
+					},
+				}, nil)
+				return
+			},
+		},
+		RulesServer: servicebusfake.RulesServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, namespaceName string, topicName string, subscriptionName string, ruleName string, parameters armservicebus.Rule, options *armservicebus.RulesClientCreateOrUpdateOptions) (resp azfake.Responder[armservicebus.RulesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicebus.RulesClientCreateOrUpdateResponse{
+					Rule: armservicebus.Rule{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceBus/namespaces/%s/topics/%s/subscriptions/%s/rules/%s", subscriptionID, resourceGroupName, namespaceName, topicName, subscriptionName, ruleName)),
+						Name: to.Ptr(ruleName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	servicebusClientFactory, err = armservicebus.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: servicebusfake.NewServerFactoryTransport(&servicebusServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespacesClient = servicebusClientFactory.NewNamespacesClient()
+	topicsClient = servicebusClientFactory.NewTopicsClient()
+	subscriptionsClient = servicebusClientFactory.NewSubscriptionsClient()
+	rulesClient = servicebusClientFactory.NewRulesClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	namespace, err := createNamespace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus namespace:", *namespace.ID)
+
+	topic, err := createTopic(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus topic:", *topic.ID)
+
+	subscription, err := createSubscription(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service bus subscription:", *subscription.ID)
+
+	rules, err := createRules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("rules:", *rules.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,16 +5,14 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicefabric/armservicefabric"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	clusterName       = "sample-servicefabric-cluster"
@@ -22,131 +20,137 @@ var (
 )
 
 var (
-	resourcesClientFactory     *armresources.ClientFactory
 	servicefabricClientFactory *armservicefabric.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	clustersClient      *armservicefabric.ClustersClient
-	applicationsClient  *armservicefabric.ApplicationsClient
+	clustersClient     *armservicefabric.ClustersClient
+	applicationsClient *armservicefabric.ApplicationsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"clusterName":       &clusterName,
+		"applicationName":   &applicationName,
+	}, run)
+}
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
+	servicefabricClientFactory, err = armservicefabric.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
-
-	servicefabricClientFactory, err = armservicefabric.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	clustersClient = servicefabricClientFactory.NewClustersClient()
 	applicationsClient = servicefabricClientFactory.NewApplicationsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.ServiceFabric"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	cluster, err := createCluster(ctx)
+	cluster, err := createCluster(ctx, sample)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service fabric cluster:", *cluster.ID)
 
 	application, err := createApplication(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("service fabric application:", *application.ID)
 
 	application, err = getApplication(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get service fabric application:", *application.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
-func createCluster(ctx context.Context) (*armservicefabric.Cluster, error) {
+func createCluster(ctx context.Context, sample *samplekit.Sample) (*armservicefabric.Cluster, error) {
 
-	pollerResp, err := clustersClient.BeginCreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		clusterName,
-		armservicefabric.Cluster{
-			Location: to.Ptr(location),
-			Properties: &armservicefabric.ClusterProperties{
-				ManagementEndpoint: to.Ptr("https://myCluster.eastus.cloudapp.azure.com:19080"),
-				NodeTypes: []*armservicefabric.NodeTypeDescription{
-					{
-						Name:                         to.Ptr("nt1vm"),
-						ClientConnectionEndpointPort: to.Ptr[int32](19000),
-						HTTPGatewayEndpointPort:      to.Ptr[int32](19007),
-						ApplicationPorts: &armservicefabric.EndpointRangeDescription{
-							StartPort: to.Ptr[int32](20000),
-							EndPort:   to.Ptr[int32](30000),
-						},
-						EphemeralPorts: &armservicefabric.EndpointRangeDescription{
-							StartPort: to.Ptr[int32](49000),
-							EndPort:   to.Ptr[int32](64000),
+	// the endpoints are in the sample's cloud
+	domainName, err := sample.DomainName("myCluster")
+	if err != nil {
+		return nil, err
+	}
+	blobEndpoint, err := sample.BlobEndpoint("diag")
+	if err != nil {
+		return nil, err
+	}
+	queueEndpoint, err := sample.QueueEndpoint("diag")
+	if err != nil {
+		return nil, err
+	}
+	tableEndpoint, err := sample.TableEndpoint("diag")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := samplekit.PollUntilDone(ctx, sample, "Service Fabric cluster "+clusterName, func(resumeToken string) (*runtime.Poller[armservicefabric.ClustersClientCreateOrUpdateResponse], error) {
+		return clustersClient.BeginCreateOrUpdate(
+			ctx,
+			resourceGroupName,
+			clusterName,
+			armservicefabric.Cluster{
+				Location: to.Ptr(location),
+				Properties: &armservicefabric.ClusterProperties{
+					ManagementEndpoint: to.Ptr("https://" + domainName + ":19080"),
+					NodeTypes: []*armservicefabric.NodeTypeDescription{
+						{
+							Name:                         to.Ptr("nt1vm"),
+							ClientConnectionEndpointPort: to.Ptr[int32](19000),
+							HTTPGatewayEndpointPort:      to.Ptr[int32](19007),
+							ApplicationPorts: &armservicefabric.EndpointRangeDescription{
+								StartPort: to.Ptr[int32](20000),
+								EndPort:   to.Ptr[int32](30000),
+							},
+							EphemeralPorts: &armservicefabric.EndpointRangeDescription{
+								StartPort: to.Ptr[int32](49000),
+								EndPort:   to.Ptr[int32](64000),
+							},
+							IsPrimary:       to.Ptr(true),
+							VMInstanceCount: to.Ptr[int32](5),
+							DurabilityLevel: to.Ptr(armservicefabric.DurabilityLevelBronze),
 						},
-						IsPrimary:       to.Ptr(true),
-						VMInstanceCount: to.Ptr[int32](5),
-						DurabilityLevel: to.Ptr(armservicefabric.DurabilityLevelBronze),
 					},
-				},
-				FabricSettings: []*armservicefabric.SettingsSectionDescription{
-					{
-						Name: to.Ptr("UpgradeService"),
-						Parameters: []*armservicefabric.SettingsParameterDescription{
-							{
-								Name:  to.Ptr("AppPollIntervalInSeconds"),
-								Value: to.Ptr("60"),
+					FabricSettings: []*armservicefabric.SettingsSectionDescription{
+						{
+							Name: to.Ptr("UpgradeService"),
+							Parameters: []*armservicefabric.SettingsParameterDescription{
+								{
+									Name:  to.Ptr("AppPollIntervalInSeconds"),
+									Value: to.Ptr("60"),
+								},
 							},
 						},
 					},
+					DiagnosticsStorageAccountConfig: &armservicefabric.DiagnosticsStorageAccountConfig{
+						StorageAccountName:      to.Ptr("diag"),
+						ProtectedAccountKeyName: to.Ptr("StorageAccountKey1"),
+						BlobEndpoint:            to.Ptr(blobEndpoint),
+						QueueEndpoint:           to.Ptr(queueEndpoint),
+						TableEndpoint:           to.Ptr(tableEndpoint),
+					},
+					ReliabilityLevel: to.Ptr(armservicefabric.ReliabilityLevelSilver),
+					UpgradeMode:      to.Ptr(armservicefabric.UpgradeModeAutomatic),
 				},
-				DiagnosticsStorageAccountConfig: &armservicefabric.DiagnosticsStorageAccountConfig{
-					StorageAccountName:      to.Ptr("diag"),
-					ProtectedAccountKeyName: to.Ptr("StorageAccountKey1"),
-					BlobEndpoint:            to.Ptr("https://diag.blob.core.windows.net/"),
-					QueueEndpoint:           to.Ptr("https://diag.queue.core.windows.net/"),
-					TableEndpoint:           to.Ptr("https://diag.table.core.windows.net/"),
-				},
-				ReliabilityLevel: to.Ptr(armservicefabric.ReliabilityLevelSilver),
-				UpgradeMode:      to.Ptr(armservicefabric.UpgradeModeAutomatic),
 			},
-		},
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+			&armservicefabric.ClustersClientBeginCreateOrUpdateOptions{ResumeToken: resumeToken},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +213,7 @@ func createApplication(ctx context.Context) (*armservicefabric.ApplicationResour
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create application", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -224,32 +228,3 @@ func getApplication(ctx context.Context) (*armservicefabric.ApplicationResource,
 	}
 	return &resp.ApplicationResource, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
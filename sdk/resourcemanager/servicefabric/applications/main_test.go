@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicefabric/armservicefabric"
+	servicefabricfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicefabric/armservicefabric/fake"
+)
+
+func TestApplications(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	servicefabricServer := servicefabricfake.ServerFactory{
+		ClustersServer: servicefabricfake.ClustersServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, clusterName string, parameters armservicefabric.Cluster, options *armservicefabric.ClustersClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armservicefabric.ClustersClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armservicefabric.ClustersClientCreateOrUpdateResponse{
+					Cluster: armservicefabric.Cluster{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceFabric/clusters/%s", subscriptionID, resourceGroupName, clusterName)),
+						Name: to.Ptr(clusterName),
+					},
+				}, nil)
+				return
+			},
+		},
+		ApplicationsServer: servicefabricfake.ApplicationsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, clusterName string, applicationName string, parameters armservicefabric.ApplicationResource, options *armservicefabric.ApplicationsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armservicefabric.ApplicationsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusAccepted, armservicefabric.ApplicationsClientCreateOrUpdateResponse{
+					ApplicationResource: armservicefabric.ApplicationResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceFabric/clusters/%s/applications/%s", subscriptionID, resourceGroupName, clusterName, applicationName)),
+						Name: to.Ptr(applicationName),
+					},
+				}, nil)
+				return
+			},
+			Get: func(ctx context.Context, resourceGroupName string, clusterName string, applicationName string, options *armservicefabric.ApplicationsClientGetOptions) (resp azfake.Responder[armservicefabric.ApplicationsClientGetResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armservicefabric.ApplicationsClientGetResponse{
+					ApplicationResource: armservicefabric.ApplicationResource{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ServiceFabric/clusters/%s/applications/%s", subscriptionID, resourceGroupName, clusterName, applicationName)),
+						Name: to.Ptr(applicationName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	servicefabricClientFactory, err = armservicefabric.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: servicefabricfake.NewServerFactoryTransport(&servicefabricServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clustersClient = servicefabricClientFactory.NewClustersClient()
+	applicationsClient = servicefabricClientFactory.NewApplicationsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	cluster, err := createCluster(ctx, sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service fabric cluster:", *cluster.ID)
+
+	application, err := createApplication(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("service fabric application:", *application.ID)
+
+	application, err = getApplication(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("get service fabric application:", *application.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
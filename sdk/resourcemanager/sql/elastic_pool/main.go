@@ -6,16 +6,13 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
@@ -23,73 +20,64 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	sqlClientFactory       *armsql.ClientFactory
+	sqlClientFactory *armsql.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	serversClient       *armsql.ServersClient
-	elasticPoolsClient  *armsql.ElasticPoolsClient
+	serversClient      *armsql.ServersClient
+	elasticPoolsClient *armsql.ElasticPoolsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.SQLServer),
+		"elasticPoolName":   &elasticPoolName,
+	}, run, samplekit.WithReuse())
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, nil)
+	sqlClientFactory, err = armsql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = sqlClientFactory.NewServersClient()
 	elasticPoolsClient = sqlClientFactory.NewElasticPoolsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Sql"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	server, err := createServer(ctx)
+	// in reuse mode, the server of a previous run is used
+	server, err := samplekit.Ensure(ctx, sample, "server "+serverName, getServer, createServer)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server:", *server.ID)
 
 	elasticPool, err := createElasticPool(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("elastic pool:", *elasticPool.ID)
 
 	elasticPool, err = getElasticPool(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get elastic pool:", *elasticPool.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armsql.Server, error) {
@@ -110,7 +98,16 @@ func createServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, serverName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +129,7 @@ func createElasticPool(ctx context.Context) (*armsql.ElasticPool, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create elastic pool", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -147,32 +144,3 @@ func getElasticPool(ctx context.Context) (*armsql.ElasticPool, error) {
 	}
 	return &resp.ElasticPool, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
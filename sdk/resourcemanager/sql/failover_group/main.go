@@ -6,17 +6,15 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
+	pairedLocation    = "eastus2"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
 	partnerServerName = "sample2partner2server"
@@ -24,79 +22,97 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	sqlClientFactory       *armsql.ClientFactory
+	sqlClientFactory *armsql.ClientFactory
 )
 
 var (
-	resourceGroupClient  *armresources.ResourceGroupsClient
 	serversClient        *armsql.ServersClient
 	failoverGroupsClient *armsql.FailoverGroupsClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"pairedLocation":    &pairedLocation,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.SQLServer),
+		"partnerServerName": samplekit.Unique(&partnerServerName, samplekit.SQLServer),
+		"failoverGroupName": samplekit.Unique(&failoverGroupName, samplekit.SQLServer),
+	}, run, samplekit.WithReuse())
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, nil)
+	sqlClientFactory, err = armsql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = sqlClientFactory.NewServersClient()
 	failoverGroupsClient = sqlClientFactory.NewFailoverGroupsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
-	if err != nil {
-		log.Fatal(err)
-	}
-	log.Println("resources group:", *resourceGroup.ID)
-
-	server, err := createServer(ctx)
-	if err != nil {
-		log.Fatal(err)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Sql"}}); err != nil {
+		return err
 	}
-	log.Println("server:", *server.ID)
 
-	partnerServer, err := createPartnerServer(ctx)
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	log.Println("partner server:", *partnerServer.ID)
+	log.Println("resources group:", *resourceGroup.ID)
 
-	failoverGroup, err := createFailoverGroup(ctx, *partnerServer.ID)
-	if err != nil {
-		log.Fatal(err)
+	// the two servers do not depend on each other, so they are created at the same time
+	var partnerServerID string
+	var graph samplekit.Graph
+	graph.Add(samplekit.GraphStep{
+		Name: "createServer",
+		Create: func(ctx context.Context) error {
+			// in reuse mode, the server of a previous run is used
+			server, err := samplekit.Ensure(ctx, sample, "server "+serverName, getServer, createServer)
+			if err != nil {
+				return err
+			}
+			log.Println("server:", *server.ID)
+			return nil
+		},
+	})
+	graph.Add(samplekit.GraphStep{
+		Name: "createPartnerServer",
+		Create: func(ctx context.Context) error {
+			// in reuse mode, the partner server of a previous run is used
+			partnerServer, err := samplekit.Ensure(ctx, sample, "partner server "+partnerServerName, getPartnerServer, createPartnerServer)
+			if err != nil {
+				return err
+			}
+			partnerServerID = *partnerServer.ID
+			log.Println("partner server:", partnerServerID)
+			return nil
+		},
+	})
+	graph.Add(samplekit.GraphStep{
+		Name:      "createFailoverGroup",
+		DependsOn: []string{"createServer", "createPartnerServer"},
+		Create: func(ctx context.Context) error {
+			failoverGroup, err := createFailoverGroup(ctx, partnerServerID)
+			if err != nil {
+				return err
+			}
+			log.Println("failover group:", *failoverGroup.ID)
+			return nil
+		},
+	})
+	if err := graph.Run(ctx, sample); err != nil {
+		return err
 	}
-	log.Println("failover group:", *failoverGroup.ID)
 
-	failoverGroup, err = getFailoverGroup(ctx, *partnerServer.ID)
+	failoverGroup, err := getFailoverGroup(ctx, partnerServerID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("get failover group:", *failoverGroup.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armsql.Server, error) {
@@ -117,7 +133,16 @@ func createServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, serverName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +156,7 @@ func createPartnerServer(ctx context.Context) (*armsql.Server, error) {
 		resourceGroupName,
 		partnerServerName,
 		armsql.Server{
-			Location: to.Ptr("eastus2"),
+			Location: to.Ptr(pairedLocation),
 			Properties: &armsql.ServerProperties{
 				AdministratorLogin:         to.Ptr("dummylogin"),
 				AdministratorLoginPassword: to.Ptr("QWE123!@#"),
@@ -142,7 +167,16 @@ func createPartnerServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create partner server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getPartnerServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, partnerServerName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +209,7 @@ func createFailoverGroup(ctx context.Context, partnerServerID string) (*armsql.F
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create failover group", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,32 +224,3 @@ func getFailoverGroup(ctx context.Context, partnerServerID string) (*armsql.Fail
 	}
 	return &resp.FailoverGroup, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
@@ -6,17 +6,14 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
@@ -29,12 +26,10 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	sqlClientFactory       *armsql.ClientFactory
+	sqlClientFactory *armsql.ClientFactory
 )
 
 var (
-	resourceGroupClient   *armresources.ResourceGroupsClient
 	serversClient         *armsql.ServersClient
 	databasesClient       *armsql.DatabasesClient
 	jobAgentsClient       *armsql.JobAgentsClient
@@ -46,26 +41,25 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.SQLServer),
+		"databaseName":      &databaseName,
+		"jobAgentName":      &jobAgentName,
+		"credentialName":    &credentialName,
+		"targetGroupName":   &targetGroupName,
+		"jobName":           &jobName,
+		"jobStepName":       &jobStepName,
+	}, run, samplekit.WithReuse())
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, nil)
+	sqlClientFactory, err = armsql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = sqlClientFactory.NewServersClient()
 	databasesClient = sqlClientFactory.NewDatabasesClient()
@@ -76,68 +70,67 @@ func main() {
 	jobStepsClient = sqlClientFactory.NewJobStepsClient()
 	jobExecutionsClient = sqlClientFactory.NewJobExecutionsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Sql"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	server, err := createServer(ctx)
+	// in reuse mode, the server of a previous run is used
+	server, err := samplekit.Ensure(ctx, sample, "server "+serverName, getServer, createServer)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server:", *server.ID)
 
 	database, err := createDatabase(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("database:", *database.ID)
 
 	jobAgent, err := createJobAgent(ctx, *database.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job agent:", *jobAgent.ID)
 
 	jobCredential, err := createJobCredential(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job credential:", *jobCredential.ID)
 
 	jobTargetGroup, err := createJobTargetGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job target group:", *jobTargetGroup.ID)
 
 	job, err := createJob(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job:", *job.ID)
 
 	jobStep, err := createJobStep(ctx, *jobCredential.ID, *jobTargetGroup.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job step:", *jobStep.ID)
 
 	jobExecution, err := createJobExecution(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("job execution:", *jobExecution.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armsql.Server, error) {
@@ -158,7 +151,16 @@ func createServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, serverName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +185,7 @@ func createDatabase(ctx context.Context) (*armsql.Database, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create database", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +210,7 @@ func createJobAgent(ctx context.Context, databaseID string) (*armsql.JobAgent, e
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create job agent", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -328,38 +330,9 @@ func createJobExecution(ctx context.Context) (*armsql.JobExecution, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create job execution", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.JobExecution, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
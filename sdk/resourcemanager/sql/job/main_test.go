@@ -0,0 +1,231 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	sqlfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql/fake"
+)
+
+func TestJob(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	sqlServer := sqlfake.ServerFactory{
+		ServersServer: sqlfake.ServersServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, parameters armsql.Server, options *armsql.ServersClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.ServersClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armsql.ServersClientCreateOrUpdateResponse{
+					Server: armsql.Server{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s", subscriptionID, resourceGroupName, serverName)),
+						Name: to.Ptr(serverName),
+					},
+				}, nil)
+				return
+			},
+		},
+		DatabasesServer: sqlfake.DatabasesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, parameters armsql.Database, options *armsql.DatabasesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.DatabasesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.DatabasesClientCreateOrUpdateResponse{
+					Database: armsql.Database{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/databases/%s", subscriptionID, resourceGroupName, serverName, databaseName)),
+						Name: to.Ptr(databaseName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobAgentsServer: sqlfake.JobAgentsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, parameters armsql.JobAgent, options *armsql.JobAgentsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.JobAgentsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.JobAgentsClientCreateOrUpdateResponse{
+					JobAgent: armsql.JobAgent{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s", subscriptionID, resourceGroupName, serverName, jobAgentName)),
+						Name: to.Ptr(jobAgentName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobCredentialsServer: sqlfake.JobCredentialsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, credentialName string, parameters armsql.JobCredential, options *armsql.JobCredentialsClientCreateOrUpdateOptions) (resp azfake.Responder[armsql.JobCredentialsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armsql.JobCredentialsClientCreateOrUpdateResponse{
+					JobCredential: armsql.JobCredential{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s/credentials/%s", subscriptionID, resourceGroupName, serverName, jobAgentName, credentialName)),
+						Name: to.Ptr(credentialName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobTargetGroupsServer: sqlfake.JobTargetGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, targetGroupName string, parameters armsql.JobTargetGroup, options *armsql.JobTargetGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armsql.JobTargetGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armsql.JobTargetGroupsClientCreateOrUpdateResponse{
+					JobTargetGroup: armsql.JobTargetGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s/targetGroups/%s", subscriptionID, resourceGroupName, serverName, jobAgentName, targetGroupName)),
+						Name: to.Ptr(targetGroupName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobsServer: sqlfake.JobsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, jobName string, parameters armsql.Job, options *armsql.JobsClientCreateOrUpdateOptions) (resp azfake.Responder[armsql.JobsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armsql.JobsClientCreateOrUpdateResponse{
+					Job: armsql.Job{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s/jobs/%s", subscriptionID, resourceGroupName, serverName, jobAgentName, jobName)),
+						Name: to.Ptr(jobName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobStepsServer: sqlfake.JobStepsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, jobName string, stepName string, parameters armsql.JobStep, options *armsql.JobStepsClientCreateOrUpdateOptions) (resp azfake.Responder[armsql.JobStepsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armsql.JobStepsClientCreateOrUpdateResponse{
+					JobStep: armsql.JobStep{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s/jobs/%s/steps/%s", subscriptionID, resourceGroupName, serverName, jobAgentName, jobName, stepName)),
+						Name: to.Ptr(stepName),
+					},
+				}, nil)
+				return
+			},
+		},
+		JobExecutionsServer: sqlfake.JobExecutionsServer{
+			BeginCreate: func(ctx context.Context, resourceGroupName string, serverName string, jobAgentName string, jobName string, options *armsql.JobExecutionsClientBeginCreateOptions) (resp azfake.PollerResponder[armsql.JobExecutionsClientCreateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.JobExecutionsClientCreateResponse{
+					JobExecution: armsql.JobExecution{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/jobAgents/%s/jobs/%s/start", subscriptionID, resourceGroupName, serverName, jobAgentName, jobName)),
+						Name: to.Ptr(jobName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: sqlfake.NewServerFactoryTransport(&sqlServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversClient = sqlClientFactory.NewServersClient()
+	databasesClient = sqlClientFactory.NewDatabasesClient()
+	jobAgentsClient = sqlClientFactory.NewJobAgentsClient()
+	jobCredentialsClient = sqlClientFactory.NewJobCredentialsClient()
+	jobTargetGroupsClient = sqlClientFactory.NewJobTargetGroupsClient()
+	jobsClient = sqlClientFactory.NewJobsClient()
+	jobStepsClient = sqlClientFactory.NewJobStepsClient()
+	jobExecutionsClient = sqlClientFactory.NewJobExecutionsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	server, err := createServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("server:", *server.ID)
+
+	database, err := createDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("database:", *database.ID)
+
+	jobAgent, err := createJobAgent(ctx, *database.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job agent:", *jobAgent.ID)
+
+	jobCredential, err := createJobCredential(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job credential:", *jobCredential.ID)
+
+	jobTargetGroup, err := createJobTargetGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job target group:", *jobTargetGroup.ID)
+
+	job, err := createJob(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job:", *job.ID)
+
+	jobStep, err := createJobStep(ctx, *jobCredential.ID, *jobTargetGroup.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job step:", *jobStep.ID)
+
+	jobExecution, err := createJobExecution(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("job execution:", *jobExecution.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,16 +5,13 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID    string
 	location          = "eastus"
 	resourceGroupName = "sample-resource-group"
 	serverName        = "sample2server"
@@ -26,40 +23,36 @@ var (
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	sqlClientFactory       *armsql.ClientFactory
+	sqlClientFactory *armsql.ClientFactory
 )
 
 var (
-	resourceGroupClient *armresources.ResourceGroupsClient
-	serversClient       *armsql.ServersClient
-	databasesClient     *armsql.DatabasesClient
-	syncAgentsClient    *armsql.SyncAgentsClient
-	syncGroupsClient    *armsql.SyncGroupsClient
-	syncMembersClient   *armsql.SyncMembersClient
+	serversClient     *armsql.ServersClient
+	databasesClient   *armsql.DatabasesClient
+	syncAgentsClient  *armsql.SyncAgentsClient
+	syncGroupsClient  *armsql.SyncGroupsClient
+	syncMembersClient *armsql.SyncMembersClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":          &location,
+		"resourceGroupName": &resourceGroupName,
+		"serverName":        samplekit.Unique(&serverName, samplekit.SQLServer),
+		"databaseName":      &databaseName,
+		"syncDatabaseName":  &syncDatabaseName,
+		"syncAgentName":     &syncAgentName,
+		"syncGroupName":     &syncGroupName,
+		"syncMemberName":    &syncMemberName,
+	}, run, samplekit.WithReuse())
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, nil)
+	sqlClientFactory, err = armsql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = sqlClientFactory.NewServersClient()
 	databasesClient = sqlClientFactory.NewDatabasesClient()
@@ -67,56 +60,55 @@ func main() {
 	syncGroupsClient = sqlClientFactory.NewSyncGroupsClient()
 	syncMembersClient = sqlClientFactory.NewSyncMembersClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Sql"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
-	server, err := createServer(ctx)
+	// in reuse mode, the server of a previous run is used
+	server, err := samplekit.Ensure(ctx, sample, "server "+serverName, getServer, createServer)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server:", *server.ID)
 
 	database, err := createDatabase(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("database:", *database.ID)
 
 	syncDatabase, err := createSyncDatabase(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("sync database:", *syncDatabase.ID)
 
 	syncAgent, err := createSyncAgent(ctx, *syncDatabase.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("sync agent:", *syncAgent.ID)
 
 	syncGroup, err := createSyncGroup(ctx, *syncDatabase.ID)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("sync group:", *syncGroup.ID)
 
 	syncMember, err := createSyncMember(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("sync member:", *syncMember.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createServer(ctx context.Context) (*armsql.Server, error) {
@@ -137,7 +129,16 @@ func createServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, serverName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +160,7 @@ func createDatabase(ctx context.Context) (*armsql.Database, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create database", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +182,7 @@ func createSyncDatabase(ctx context.Context) (*armsql.Database, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create sync database", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +206,7 @@ func createSyncAgent(ctx context.Context, syncDatabaseID string) (*armsql.SyncAg
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create sync agent", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +235,7 @@ func createSyncGroup(ctx context.Context, syncDatabaseID string) (*armsql.SyncGr
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create sync group", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -267,38 +268,9 @@ func createSyncMember(ctx context.Context) (*armsql.SyncMember, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create sync member", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.SyncMember, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
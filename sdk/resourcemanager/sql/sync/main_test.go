@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	sqlfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql/fake"
+)
+
+func TestSync(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	sqlServer := sqlfake.ServerFactory{
+		ServersServer: sqlfake.ServersServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, parameters armsql.Server, options *armsql.ServersClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.ServersClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armsql.ServersClientCreateOrUpdateResponse{
+					Server: armsql.Server{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s", subscriptionID, resourceGroupName, serverName)),
+						Name: to.Ptr(serverName),
+					},
+				}, nil)
+				return
+			},
+		},
+		DatabasesServer: sqlfake.DatabasesServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, parameters armsql.Database, options *armsql.DatabasesClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.DatabasesClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.DatabasesClientCreateOrUpdateResponse{
+					Database: armsql.Database{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/databases/%s", subscriptionID, resourceGroupName, serverName, databaseName)),
+						Name: to.Ptr(databaseName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SyncAgentsServer: sqlfake.SyncAgentsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, syncAgentName string, parameters armsql.SyncAgent, options *armsql.SyncAgentsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.SyncAgentsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.SyncAgentsClientCreateOrUpdateResponse{
+					SyncAgent: armsql.SyncAgent{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/syncAgents/%s", subscriptionID, resourceGroupName, serverName, syncAgentName)),
+						Name: to.Ptr(syncAgentName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SyncGroupsServer: sqlfake.SyncGroupsServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string, parameters armsql.SyncGroup, options *armsql.SyncGroupsClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.SyncGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.SyncGroupsClientCreateOrUpdateResponse{
+					SyncGroup: armsql.SyncGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/databases/%s/syncGroups/%s", subscriptionID, resourceGroupName, serverName, databaseName, syncGroupName)),
+						Name: to.Ptr(syncGroupName),
+					},
+				}, nil)
+				return
+			},
+		},
+		SyncMembersServer: sqlfake.SyncMembersServer{
+			BeginCreateOrUpdate: func(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string, syncMemberName string, parameters armsql.SyncMember, options *armsql.SyncMembersClientBeginCreateOrUpdateOptions) (resp azfake.PollerResponder[armsql.SyncMembersClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armsql.SyncMembersClientCreateOrUpdateResponse{
+					SyncMember: armsql.SyncMember{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/%s/databases/%s/syncGroups/%s/syncMembers/%s", subscriptionID, resourceGroupName, serverName, databaseName, syncGroupName, syncMemberName)),
+						Name: to.Ptr(syncMemberName),
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: sqlfake.NewServerFactoryTransport(&sqlServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serversClient = sqlClientFactory.NewServersClient()
+	databasesClient = sqlClientFactory.NewDatabasesClient()
+	syncAgentsClient = sqlClientFactory.NewSyncAgentsClient()
+	syncGroupsClient = sqlClientFactory.NewSyncGroupsClient()
+	syncMembersClient = sqlClientFactory.NewSyncMembersClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	server, err := createServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("server:", *server.ID)
+
+	database, err := createDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("database:", *database.ID)
+
+	syncDatabase, err := createSyncDatabase(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("sync database:", *syncDatabase.ID)
+
+	syncAgent, err := createSyncAgent(ctx, *syncDatabase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("sync agent:", *syncAgent.ID)
+
+	syncGroup, err := createSyncGroup(ctx, *syncDatabase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("sync group:", *syncGroup.ID)
+
+	syncMember, err := createSyncMember(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("sync member:", *syncMember.ID)
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
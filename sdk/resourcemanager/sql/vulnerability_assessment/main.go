@@ -5,32 +5,28 @@ package main
 
 import (
 	"context"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "eastus"
-	resourceGroupName  = "sample-resource-group"
-	serverName         = "sample2server"
-	storageAccountName = "sample2storage2account"
-	containerName      = "blob2container"
+	location              = "eastus"
+	resourceGroupName     = "sample-resource-group"
+	serverName            = "sample2server"
+	storageAccountName    = "sample2storage2account"
+	containerName         = "blob2container"
+	storageAccountSKUName = string(armstorage.SKUNameStandardLRS)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	storageClientFactory   *armstorage.ClientFactory
-	sqlClientFactory       *armsql.ClientFactory
+	storageClientFactory *armstorage.ClientFactory
+	sqlClientFactory     *armsql.ClientFactory
 )
 
 var (
-	resourceGroupClient                  *armresources.ResourceGroupsClient
 	accountsClient                       *armstorage.AccountsClient
 	blobContainersClient                 *armstorage.BlobContainersClient
 	serversClient                        *armsql.ServersClient
@@ -39,90 +35,89 @@ var (
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"serverName":            samplekit.Unique(&serverName, samplekit.SQLServer),
+		"storageAccountName":    samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+		"containerName":         &containerName,
+		"storageAccountSKUName": &storageAccountSKUName,
+	}, run, samplekit.WithReuse())
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountsClient = storageClientFactory.NewAccountsClient()
 	blobContainersClient = storageClientFactory.NewBlobContainersClient()
 
-	sqlClientFactory, err = armsql.NewClientFactory(subscriptionID, cred, nil)
+	sqlClientFactory, err = armsql.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	serversClient = sqlClientFactory.NewServersClient()
 	serverSecurityAlertPoliciesClient = sqlClientFactory.NewServerSecurityAlertPoliciesClient()
 	serverVulnerabilityAssessmentsClient = sqlClientFactory.NewServerVulnerabilityAssessmentsClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Sql", "Microsoft.Storage"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	storageAccount, err := createStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage account:", *storageAccount.ID)
 
 	blobContainer, err := createBlobContainers(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("blob container:", *blobContainer.ID)
 
 	accountKey, err := regenerateStorageAccountKey(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accessKey := accountKey[0].Value
 	log.Println("account Key:", *accessKey)
 
-	server, err := createServer(ctx)
+	// in reuse mode, the server of a previous run is used
+	server, err := samplekit.Ensure(ctx, sample, "server "+serverName, getServer, createServer)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server:", *server.ID)
 
 	serverSecurityAlertPolicy, err := createServerSecurityAlertPolicy(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server security alert policy:", *serverSecurityAlertPolicy.ID)
 
-	containerPath := "https://" + storageAccountName + ".blob.core.windows.net/" + containerName + "/"
+	blobEndpoint, err := sample.BlobEndpoint(storageAccountName)
+	if err != nil {
+		return err
+	}
+	containerPath := blobEndpoint + containerName + "/"
 	serverVulnerabilityAssessment, err := createServerVulnerabilityAssessment(ctx, containerPath, *accessKey)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("server vulnerability assessment:", *serverVulnerabilityAssessment.ID)
 
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
-	}
+	return nil
 }
 
 func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
@@ -134,7 +129,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 		armstorage.AccountCreateParameters{
 			Kind: to.Ptr(armstorage.KindStorageV2),
 			SKU: &armstorage.SKU{
-				Name: to.Ptr(armstorage.SKUNameStandardLRS),
+				Name: to.Ptr(armstorage.SKUName(storageAccountSKUName)),
 			},
 			Location: to.Ptr(location),
 			Properties: &armstorage.AccountPropertiesCreateParameters{
@@ -157,7 +152,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create storage account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +213,16 @@ func createServer(ctx context.Context) (*armsql.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server", pollerResp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Server, nil
+}
+
+func getServer(ctx context.Context) (*armsql.Server, error) {
+
+	resp, err := serversClient.Get(ctx, resourceGroupName, serverName, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +249,7 @@ func createServerSecurityAlertPolicy(ctx context.Context) (*armsql.ServerSecurit
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create server security alert policy", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -272,32 +276,3 @@ func createServerVulnerabilityAssessment(ctx context.Context, containerPath, acc
 	}
 	return &resp.ServerVulnerabilityAssessment, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
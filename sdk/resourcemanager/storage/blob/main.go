@@ -5,103 +5,96 @@ package main
 
 import (
 	"context"
+	"errors"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"log"
-	"os"
 )
 
 var (
-	subscriptionID     string
-	location           = "westus"
-	resourceGroupName  = "sample-resource-group"
-	storageAccountName = "sample2storage2account"
-	containerName      = "blob2container"
+	location              = "westus"
+	resourceGroupName     = "sample-resource-group"
+	storageAccountName    = "sample2storage2account"
+	containerName         = "blob2container"
+	storageAccountSKUName = string(armstorage.SKUNameStandardLRS)
 )
 
 var (
-	resourcesClientFactory *armresources.ClientFactory
-	storageClientFactory   *armstorage.ClientFactory
+	storageClientFactory *armstorage.ClientFactory
 )
 
 var (
-	resourceGroupClient  *armresources.ResourceGroupsClient
 	accountsClient       *armstorage.AccountsClient
 	blobContainersClient *armstorage.BlobContainersClient
 	blobServicesClient   *armstorage.BlobServicesClient
 )
 
 func main() {
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
-	if len(subscriptionID) == 0 {
-		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
-	}
-
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	ctx := context.Background()
+	samplekit.Main(samplekit.Settings{
+		"location":              &location,
+		"resourceGroupName":     &resourceGroupName,
+		"storageAccountName":    samplekit.Unique(&storageAccountName, samplekit.StorageAccount),
+		"containerName":         &containerName,
+		"storageAccountSKUName": &storageAccountSKUName,
+	}, run)
+}
 
-	resourcesClientFactory, err = armresources.NewClientFactory(subscriptionID, cred, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	resourceGroupClient = resourcesClientFactory.NewResourceGroupsClient()
+func run(ctx context.Context, sample *samplekit.Sample) error {
+	var err error
 
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(sample.SubscriptionID, sample.Credential, sample.ClientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	accountsClient = storageClientFactory.NewAccountsClient()
 	blobContainersClient = storageClientFactory.NewBlobContainersClient()
 	blobServicesClient = storageClientFactory.NewBlobServicesClient()
 
-	resourceGroup, err := createResourceGroup(ctx)
+	// fail before creating anything if the subscription cannot run the sample
+	if err := sample.Preflight(ctx, samplekit.Needs{Providers: []string{"Microsoft.Storage"}}); err != nil {
+		return err
+	}
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("resources group:", *resourceGroup.ID)
 
 	storageAccount, err := createStorageAccount(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("storage account:", *storageAccount.ID)
 
 	blobContainer, err := createBlobContainers(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("blob container:", *blobContainer.ID)
 
 	blobContainer, err = getBlobContainer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("blob container ID:", *blobContainer.ID)
 
 	containerItems, err := listBlobContainer(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println("blob container list:")
 	for _, item := range containerItems {
 		log.Println("\t", *item.ID)
 	}
 
-	blobServices(ctx)
-
-	keepResource := os.Getenv("KEEP_RESOURCE")
-	if len(keepResource) == 0 {
-		err = cleanup(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Println("cleaned up successfully.")
+	err = blobServices(ctx)
+	if err != nil {
+		return err
 	}
+
+	return nil
 }
 
 func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
@@ -113,7 +106,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 		armstorage.AccountCreateParameters{
 			Kind: to.Ptr(armstorage.KindStorageV2),
 			SKU: &armstorage.SKU{
-				Name: to.Ptr(armstorage.SKUNameStandardLRS),
+				Name: to.Ptr(armstorage.SKUName(storageAccountSKUName)),
 			},
 			Location: to.Ptr(location),
 			Properties: &armstorage.AccountPropertiesCreateParameters{
@@ -136,7 +129,7 @@ func createStorageAccount(ctx context.Context) (*armstorage.Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := samplekit.Wait(ctx, "create storage account", pollerResp, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,29 +183,30 @@ func listBlobContainer(ctx context.Context) (listItems []*armstorage.ListContain
 	return
 }
 
-func blobServices(ctx context.Context) {
+func blobServices(ctx context.Context) error {
 	blobServicesProperties, err := setBlobServices(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	if blobServicesProperties == nil {
-		log.Fatal("what")
+		return errors.New("what")
 	}
 	log.Println(*blobServicesProperties.ID)
 
 	blobServicesProperties, err = getBlobServices(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Println(*blobServicesProperties.ID)
 
 	listBlob, err := listBlobServices(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	for _, properties := range listBlob {
 		log.Println(*properties.ID)
 	}
+	return nil
 }
 
 func setBlobServices(ctx context.Context) (*armstorage.BlobServiceProperties, error) {
@@ -252,32 +246,3 @@ func listBlobServices(ctx context.Context) ([]*armstorage.BlobServiceProperties,
 	}
 	return resp.Value, nil
 }
-
-func createResourceGroup(ctx context.Context) (*armresources.ResourceGroup, error) {
-
-	resourceGroupResp, err := resourceGroupClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		armresources.ResourceGroup{
-			Location: to.Ptr(location),
-		},
-		nil)
-	if err != nil {
-		return nil, err
-	}
-	return &resourceGroupResp.ResourceGroup, nil
-}
-
-func cleanup(ctx context.Context) error {
-
-	pollerResp, err := resourceGroupClient.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		return err
-	}
-	return nil
-}
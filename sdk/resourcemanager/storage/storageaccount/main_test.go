@@ -0,0 +1,245 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/sdk/internal/samplekit"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	resourcesfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	storagefake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage/fake"
+)
+
+func TestStorageaccount(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	resourcesServer := resourcesfake.ServerFactory{
+		ResourceGroupsServer: resourcesfake.ResourceGroupsServer{
+			CreateOrUpdate: func(ctx context.Context, resourceGroupName string, parameters armresources.ResourceGroup, options *armresources.ResourceGroupsClientCreateOrUpdateOptions) (resp azfake.Responder[armresources.ResourceGroupsClientCreateOrUpdateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetResponse(http.StatusOK, armresources.ResourceGroupsClientCreateOrUpdateResponse{
+					ResourceGroup: armresources.ResourceGroup{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s", subscriptionID, resourceGroupName)),
+						Name: to.Ptr(resourceGroupName),
+					},
+				}, nil)
+				return
+			},
+			BeginDelete: func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (resp azfake.PollerResponder[armresources.ResourceGroupsClientDeleteResponse], errResp azfake.ErrorResponder) {
+				resp.SetTerminalResponse(http.StatusOK, armresources.ResourceGroupsClientDeleteResponse{}, nil)
+				return
+			},
+		},
+	}
+	storageServer := storagefake.ServerFactory{
+		AccountsServer: storagefake.AccountsServer{
+			GetProperties: func(ctx context.Context, resourceGroupName string, accountName string, options *armstorage.AccountsClientGetPropertiesOptions) (resp azfake.Responder[armstorage.AccountsClientGetPropertiesResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientGetPropertiesResponse{
+					Account: armstorage.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+					},
+				}, nil)
+				return
+			},
+			CheckNameAvailability: func(ctx context.Context, accountName armstorage.AccountCheckNameAvailabilityParameters, options *armstorage.AccountsClientCheckNameAvailabilityOptions) (resp azfake.Responder[armstorage.AccountsClientCheckNameAvailabilityResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientCheckNameAvailabilityResponse{
+					CheckNameAvailabilityResult: armstorage.CheckNameAvailabilityResult{
+						NameAvailable: to.Ptr(true),
+					},
+				}, nil)
+				return
+			},
+			BeginCreate: func(ctx context.Context, resourceGroupName string, accountName string, parameters armstorage.AccountCreateParameters, options *armstorage.AccountsClientBeginCreateOptions) (resp azfake.PollerResponder[armstorage.AccountsClientCreateResponse], errResp azfake.ErrorResponder) {
+				if parameters.Location == nil || *parameters.Location != location {
+					t.Errorf("unexpected location: %v", parameters.Location)
+				}
+				resp.SetTerminalResponse(http.StatusOK, armstorage.AccountsClientCreateResponse{
+					Account: armstorage.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+					},
+				}, nil)
+				return
+			},
+			NewListByResourceGroupPager: func(resourceGroupName string, options *armstorage.AccountsClientListByResourceGroupOptions) (resp azfake.PagerResponder[armstorage.AccountsClientListByResourceGroupResponse]) {
+				resp.AddPage(http.StatusOK, armstorage.AccountsClientListByResourceGroupResponse{
+					AccountListResult: armstorage.AccountListResult{
+						Value: []*armstorage.Account{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, subscriptionID)),
+								Name: to.Ptr(subscriptionID),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			NewListPager: func(options *armstorage.AccountsClientListOptions) (resp azfake.PagerResponder[armstorage.AccountsClientListResponse]) {
+				resp.AddPage(http.StatusOK, armstorage.AccountsClientListResponse{
+					AccountListResult: armstorage.AccountListResult{
+						Value: []*armstorage.Account{
+							{
+								ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, subscriptionID)),
+								Name: to.Ptr(subscriptionID),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			ListKeys: func(ctx context.Context, resourceGroupName string, accountName string, options *armstorage.AccountsClientListKeysOptions) (resp azfake.Responder[armstorage.AccountsClientListKeysResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientListKeysResponse{
+					AccountListKeysResult: armstorage.AccountListKeysResult{
+						Keys: []*armstorage.AccountKey{
+							{
+								KeyName:      to.Ptr("key1"),
+								Value:        to.Ptr("sample-key1"),
+								CreationTime: to.Ptr(time.Date(2021, 9, 25, 13, 0, 0, 0, time.UTC)),
+								Permissions:  to.Ptr(armstorage.KeyPermissionFull),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			RegenerateKey: func(ctx context.Context, resourceGroupName string, accountName string, regenerateKey armstorage.AccountRegenerateKeyParameters, options *armstorage.AccountsClientRegenerateKeyOptions) (resp azfake.Responder[armstorage.AccountsClientRegenerateKeyResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientRegenerateKeyResponse{
+					AccountListKeysResult: armstorage.AccountListKeysResult{
+						Keys: []*armstorage.AccountKey{
+							{
+								KeyName:      to.Ptr("key1"),
+								Value:        to.Ptr("sample-key1"),
+								CreationTime: to.Ptr(time.Date(2021, 9, 25, 13, 0, 0, 0, time.UTC)),
+								Permissions:  to.Ptr(armstorage.KeyPermissionFull),
+							},
+						},
+					},
+				}, nil)
+				return
+			},
+			Update: func(ctx context.Context, resourceGroupName string, accountName string, parameters armstorage.AccountUpdateParameters, options *armstorage.AccountsClientUpdateOptions) (resp azfake.Responder[armstorage.AccountsClientUpdateResponse], errResp azfake.ErrorResponder) {
+				resp.SetResponse(http.StatusOK, armstorage.AccountsClientUpdateResponse{
+					Account: armstorage.Account{
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, accountName)),
+						Name: to.Ptr(accountName),
+						Tags: parameters.Tags,
+					},
+				}, nil)
+				return
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	cred := &azfake.TokenCredential{}
+	sample := &samplekit.Sample{
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: resourceGroupName,
+		Location:          location,
+		Credential:        cred,
+		ClientOptions: &arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: resourcesfake.NewServerFactoryTransport(&resourcesServer),
+			},
+		},
+	}
+	var err error
+
+	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: storagefake.NewServerFactoryTransport(&storageServer),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountsClient = storageClientFactory.NewAccountsClient()
+
+	resourceGroup, err := sample.CreateResourceGroup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("resources group:", *resourceGroup.ID)
+
+	availability, err := checkNameAvailability(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !*availability.NameAvailable {
+		t.Fatal(*availability.Message)
+	}
+
+	storageAccount, err := createStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("storage account:", *storageAccount.ID)
+
+	properties, err := storageAccountProperties(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(*properties.ID)
+
+	listByResourceGroup, err := listByResourceGroupStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sa := range listByResourceGroup {
+		t.Log(*sa.ID)
+	}
+
+	list, err := listStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Storage Accounts:")
+	for _, sa := range list {
+		t.Log("\t" + *sa.ID)
+	}
+
+	keys, err := regenerateKeyStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range keys {
+		if *v.KeyName == "key1" {
+			t.Log("regenerate key:", *v.KeyName, *v.Value, *v.CreationTime, *v.Permissions)
+		}
+	}
+
+	keys2, err := listKeysStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("list keys:")
+	for i, v := range keys2 {
+		t.Log("\t", i, *v.KeyName, *v.Value, *v.CreationTime, *v.Permissions)
+	}
+
+	update, err := updateStorageAccount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("updated storage account:%s,sample tag:%s\n", *update.ID, *update.Tags["sample"])
+
+	err = sample.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}